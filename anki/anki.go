@@ -0,0 +1,196 @@
+// Package anki exports [en.WordData] senses as Anki flashcards, written
+// as a tab-separated file with Anki's import headers.
+package anki
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Note is the data of one flashcard note, available to field
+// templates.
+type Note struct {
+	Word string
+	// part-of-speech label, e.g. "adjective"
+	Pos string
+	// the gloss of the sense itself
+	Gloss string
+	// glosses from the top-level sense down to this one
+	Glosses []string
+	// sense tags and qualifier, e.g. "(transitive, informal)"
+	Label string
+	IPA   string
+	// an example sentence with the headword in <b>
+	Example            template.HTML
+	ExampleTranslation template.HTML
+	// translations of the sense into [Options.TranslationLang]
+	Translation string
+
+	Data  *en.WordData
+	Sense *en.SenseData
+	// position of Sense in Data.Senses
+	SenseIndex int
+}
+
+// Notes returns one note per sense of `w`, in order. Senses that
+// only exist as the parent gloss of other senses get no note.
+func Notes(w *en.WordData, translationLang string) []*Note {
+	e := article.New(w)
+	var ipa string
+	for _, p := range e.Pronunciations {
+		if p.System == "IPA" {
+			ipa = p.Text
+			break
+		}
+	}
+	var notes []*Note
+	var walk func(senses []*article.Sense, glosses []string)
+	walk = func(senses []*article.Sense, glosses []string) {
+		for _, s := range senses {
+			path := append(glosses[:len(glosses):len(glosses)], s.Gloss)
+			if s.Data != nil {
+				n := &Note{
+					Word:    w.Word,
+					Pos:     e.Pos,
+					Gloss:   s.Gloss,
+					Glosses: path,
+					Label:   article.Label(s.Tags, s.Qualifier),
+					IPA:     ipa,
+					Data:    w,
+					Sense:   s.Data,
+				}
+				for i := range w.Senses {
+					if &w.Senses[i] == s.Data {
+						n.SenseIndex = i
+					}
+				}
+				if ex, ok := pickExample(w.Word, s.Examples); ok {
+					n.Example = template.HTML(article.HTMLSpans(ex.Text))
+					n.ExampleTranslation = template.HTML(article.HTMLSpans(ex.Translation))
+				}
+				if translationLang != "" {
+					n.Translation = translations(w, s.Data, translationLang)
+				}
+				notes = append(notes, n)
+			}
+			walk(s.Subsenses, path)
+		}
+	}
+	walk(e.Senses, nil)
+	return notes
+}
+
+// pickExample prefers an example whose headword is marked bold by
+// wiktextract. Otherwise the first example is used and occurrences
+// of the headword are made bold.
+func pickExample(word string, examples []article.Example) (article.Example, bool) {
+	for _, ex := range examples {
+		for _, s := range ex.Text {
+			if s.Bold {
+				return ex, true
+			}
+		}
+	}
+	if len(examples) == 0 {
+		return article.Example{}, false
+	}
+	ex := examples[0]
+	ex.Text = article.Spans(article.PlainText(ex.Text), findWord(article.PlainText(ex.Text), word))
+	return ex, true
+}
+
+// findWord returns the rune offsets of case-insensitive occurrences of
+// `word` in `text` that are not part of a longer word.
+func findWord(text, word string) [][2]int {
+	if word == "" {
+		return nil
+	}
+	var offsets [][2]int
+	lower, lw := strings.ToLower(text), strings.ToLower(word)
+	if len(lower) != len(text) {
+		// case mapping changed byte offsets
+		lower, lw = text, word
+	}
+	for i := 0; ; {
+		j := strings.Index(lower[i:], lw)
+		if j < 0 {
+			return offsets
+		}
+		start, end := i+j, i+j+len(lw)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			s := utf8.RuneCountInString(text[:start])
+			offsets = append(offsets, [2]int{s, s + utf8.RuneCountInString(text[start:end])})
+		}
+		i = end
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// translations joins the translations of `sense` into `lang`. A
+// translation without a sense gloss belongs to the only sense of a
+// word.
+func translations(w *en.WordData, sense *en.SenseData, lang string) string {
+	gloss := ""
+	if len(sense.Glosses) > 0 {
+		gloss = sense.Glosses[len(sense.Glosses)-1]
+	}
+	var words []string
+	for _, tr := range w.Translations {
+		if tr.LangCode != lang || tr.Word == nil || *tr.Word == "" {
+			continue
+		}
+		if tr.Sense != nil && *tr.Sense != "" {
+			if *tr.Sense != gloss {
+				continue
+			}
+		} else if len(w.Senses) != 1 {
+			continue
+		}
+		words = append(words, *tr.Word)
+	}
+	return strings.Join(words, ", ")
+}
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// GUID derives the note id of the sense `sense`, a position in
+// w.Senses, from the language, word, part of speech and etymology
+// number, and the Wiktionary sense ids of the sense or else its
+// position, so that a regenerated deck updates existing notes even if
+// their glosses were edited. It is encoded in base 62 rather than
+// Anki's base 91, which may start with the '#' comment character.
+func GUID(w *en.WordData, sense int) string {
+	h := sha256.New()
+	etym := 0
+	if w.EtymologyNumber != nil {
+		etym = *w.EtymologyNumber
+	}
+	id := "#" + strconv.Itoa(sense)
+	if sense < len(w.Senses) && len(w.Senses[sense].Senseid) > 0 {
+		id = "id:" + strings.Join(w.Senses[sense].Senseid, " ")
+	}
+	for _, s := range []string{w.LangCode, w.Word, w.Pos, strconv.Itoa(etym), id} {
+		h.Write([]byte(s))
+		h.Write([]byte{0x1f})
+	}
+	n := binary.BigEndian.Uint64(h.Sum(nil))
+	var buf [11]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = base62[n%62]
+		n /= 62
+	}
+	return string(buf[:])
+}
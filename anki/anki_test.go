@@ -0,0 +1,145 @@
+package anki_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/anki"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var bank = &en.WordData{
+	Word: "bank", Pos: "noun", LangCode: "en", EtymologyNumber: ptr(1),
+	Sounds: []en.SoundData{{Enpr: ptr("băngk")}, {Ipa: ptr("/bæŋk/")}},
+	Senses: []en.SenseData{
+		{
+			Glosses: []string{"An institution where one can place money."},
+			Examples: []en.ExampleData{
+				{Text: "I put it in the Bank & left."},
+				{Text: "The banks were closed.", BoldTextOffsets: [][2]int{{4, 9}}, Translation: ptr("Les banques étaient fermées.")},
+			},
+		},
+		{
+			Glosses:   []string{"An institution where one can place money.", "A branch office."},
+			Tags:      []string{"informal"},
+			Qualifier: ptr("chiefly US"),
+			Examples:  []en.ExampleData{{Text: "Banking at the bank, \"embankment\" aside."}},
+		},
+		{Glosses: []string{"The edge of a river."}},
+	},
+	Translations: []en.TranslationData{
+		{LangCode: "fr", Word: ptr("banque"), Sense: ptr("An institution where one can place money.")},
+		{LangCode: "fr", Word: ptr("rive"), Sense: ptr("The edge of a river.")},
+		{LangCode: "fr", Word: ptr("berge"), Sense: ptr("The edge of a river.")},
+		{LangCode: "de", Word: ptr("Ufer"), Sense: ptr("The edge of a river.")},
+	},
+}
+
+func TestNotes(t *testing.T) {
+	notes := anki.Notes(bank, "fr")
+	if len(notes) != 3 {
+		t.Fatalf("got %d notes, want 3", len(notes))
+	}
+	for i, want := range []anki.Note{
+		{Gloss: "An institution where one can place money.", Example: "The <b>banks</b> were closed.", ExampleTranslation: "Les banques étaient fermées.", Translation: "banque"},
+		{Gloss: "A branch office.", Label: "(informal, chiefly US)", Example: `Banking at the <b>bank</b>, &#34;embankment&#34; aside.`},
+		{Gloss: "The edge of a river.", Translation: "rive, berge"},
+	} {
+		n := notes[i]
+		if n.Word != "bank" || n.Pos != "noun" || n.IPA != "/bæŋk/" || n.Gloss != want.Gloss || n.Label != want.Label ||
+			n.Example != want.Example || n.ExampleTranslation != want.ExampleTranslation || n.Translation != want.Translation {
+			t.Errorf("note %d = %+v", i, n)
+		}
+	}
+	if got := notes[1].Glosses; len(got) != 2 || got[1] != "A branch office." {
+		t.Errorf("glosses = %q", got)
+	}
+}
+
+func TestGUID(t *testing.T) {
+	a := anki.GUID(bank, 0)
+	if a != anki.GUID(bank, 0) {
+		t.Error("GUID is not deterministic")
+	}
+	if len(a) != 11 || strings.HasPrefix(a, "#") {
+		t.Errorf("GUID = %q", a)
+	}
+	other := *bank
+	other.EtymologyNumber = ptr(2)
+	// senses 0 and 1 share their first gloss
+	for _, b := range []string{anki.GUID(bank, 1), anki.GUID(bank, 2), anki.GUID(&other, 0)} {
+		if a == b {
+			t.Errorf("GUID collision %s", a)
+		}
+	}
+
+	// an edited gloss keeps its note
+	edited := *bank
+	edited.Senses = slices.Clone(bank.Senses)
+	edited.Senses[0].Glosses = []string{"An institution where one can deposit money."}
+	if b := anki.GUID(&edited, 0); b != a {
+		t.Errorf("GUID changed with the gloss: %s, %s", a, b)
+	}
+	// and so does a moved sense with a sense id
+	edited.Senses[0].Senseid = []string{"en:financial institution"}
+	a = anki.GUID(&edited, 0)
+	edited.Senses = append([]en.SenseData{{Glosses: []string{"A new sense."}}}, edited.Senses...)
+	if b := anki.GUID(&edited, 1); b != a {
+		t.Errorf("GUID changed with the position of a sense with an id: %s, %s", a, b)
+	}
+	if notes := anki.Notes(&edited, ""); notes[1].SenseIndex != 1 || anki.GUID(&edited, notes[1].SenseIndex) != a {
+		t.Errorf("note of sense 1 has index %d", notes[1].SenseIndex)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var sb strings.Builder
+	w, err := anki.NewWriter(&sb, anki.Options{
+		Deck: "English::Nouns",
+		Fields: []anki.Field{
+			{Name: "Word", Template: "{{.Word}}"},
+			{Name: "Meaning", Template: "{{.Gloss}}\n{{.Example}}"},
+		},
+		Tags: []string{"wiktionary export"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(bank); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	notes := anki.Notes(bank, "")
+	want := "#separator:tab\n#html:true\n#notetype:Basic\n#deck:English::Nouns\n" +
+		"#columns:GUID\tWord\tMeaning\tTags\n#guid column:1\n#tags column:4\n" +
+		anki.GUID(bank, notes[0].SenseIndex) + "\tbank\t\"An institution where one can place money.\nThe <b>banks</b> were closed.\"\twiktionary_export noun\n" +
+		anki.GUID(bank, notes[1].SenseIndex) + "\tbank\t\"A branch office.\nBanking at the <b>bank</b>, &#34;embankment&#34; aside.\"\twiktionary_export noun informal\n" +
+		anki.GUID(bank, notes[2].SenseIndex) + "\tbank\t\"The edge of a river.\n\"\twiktionary_export noun\n"
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestDefaultFields(t *testing.T) {
+	var sb strings.Builder
+	w, err := anki.NewWriter(&sb, anki.Options{TranslationLang: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(&en.WordData{Word: "<tag>", Pos: "noun", Senses: bank.Senses[2:], Translations: bank.Translations})
+	w.Flush()
+	if !strings.Contains(sb.String(), "\t<b>&lt;tag&gt;</b> <i>noun</i>\tThe edge of a river.<br><br>Ufer\tnoun\n") {
+		t.Errorf("got\n%s", sb.String())
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	if _, err := anki.NewWriter(nil, anki.Options{Fields: []anki.Field{{Name: "Front", Template: "{{.Word"}}}); err == nil {
+		t.Error("expected a template parse error")
+	}
+}
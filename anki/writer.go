@@ -0,0 +1,147 @@
+package anki
+
+import (
+	"bufio"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// Field is a note field whose content is rendered from a [Note] by an
+// [html/template] template.
+type Field struct {
+	Name     string
+	Template string
+}
+
+// DefaultFields fill the Front and Back of Anki's "Basic" note type.
+var DefaultFields = []Field{
+	{Name: "Front", Template: `<b>{{.Word}}</b> <i>{{.Pos}}</i>{{with .IPA}}<br>{{.}}{{end}}`},
+	{Name: "Back", Template: `{{with .Label}}<i>{{.}}</i> {{end}}{{.Gloss}}` +
+		`{{with .Example}}<br><br>{{.}}{{end}}{{with .ExampleTranslation}}<br><i>{{.}}</i>{{end}}` +
+		`{{with .Translation}}<br><br>{{.}}{{end}}`},
+}
+
+// Options configures a [Writer].
+type Options struct {
+	// defaults to "Basic"
+	NoteType string
+	// deck to import into, the one selected in Anki if empty
+	Deck string
+	// defaults to DefaultFields
+	Fields []Field
+	// language code of the translations shown on the cards, none if
+	// empty
+	TranslationLang string
+	// Anki tags added to every note, besides the part of speech and
+	// sense tags
+	Tags []string
+}
+
+// Writer writes notes in Anki's tab-separated text import format. The
+// GUID is the first column and tags the last one.
+type Writer struct {
+	w      *bufio.Writer
+	opts   Options
+	fields []*template.Template
+	header bool
+}
+
+// NewWriter returns a writer to `w`. The field templates are parsed
+// here, so a template error is reported before anything is written.
+func NewWriter(w io.Writer, opts Options) (*Writer, error) {
+	if opts.NoteType == "" {
+		opts.NoteType = "Basic"
+	}
+	if opts.Fields == nil {
+		opts.Fields = DefaultFields
+	}
+	fields := make([]*template.Template, len(opts.Fields))
+	for i, f := range opts.Fields {
+		t, err := template.New(f.Name).Parse(f.Template)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = t
+	}
+	return &Writer{w: bufio.NewWriter(w), opts: opts, fields: fields}, nil
+}
+
+func (w *Writer) writeHeader() {
+	w.header = true
+	w.w.WriteString("#separator:tab\n#html:true\n")
+	w.w.WriteString("#notetype:" + w.opts.NoteType + "\n")
+	if w.opts.Deck != "" {
+		w.w.WriteString("#deck:" + w.opts.Deck + "\n")
+	}
+	columns := []string{"GUID"}
+	for _, f := range w.opts.Fields {
+		columns = append(columns, f.Name)
+	}
+	columns = append(columns, "Tags")
+	w.w.WriteString("#columns:" + strings.Join(columns, "\t") + "\n")
+	w.w.WriteString("#guid column:1\n")
+	w.w.WriteString("#tags column:" + strconv.Itoa(len(columns)) + "\n")
+}
+
+// Add writes one note per sense of `word`.
+func (w *Writer) Add(word *en.WordData) error {
+	if !w.header {
+		w.writeHeader()
+	}
+	var sb strings.Builder
+	for _, n := range Notes(word, w.opts.TranslationLang) {
+		row := []string{GUID(word, n.SenseIndex)}
+		for _, t := range w.fields {
+			sb.Reset()
+			if err := t.Execute(&sb, n); err != nil {
+				return err
+			}
+			row = append(row, sb.String())
+		}
+		row = append(row, tags(w.opts.Tags, word.Pos, n.Sense.Tags))
+		for i, f := range row {
+			if i > 0 {
+				w.w.WriteByte('\t')
+			}
+			w.w.WriteString(quote(f))
+		}
+		if _, err := w.w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes the headers if no note was added and any buffered data.
+func (w *Writer) Flush() error {
+	if !w.header {
+		w.writeHeader()
+	}
+	return w.w.Flush()
+}
+
+// tags joins Anki tags, which may not contain spaces.
+func tags(extra []string, pos string, senseTags []string) string {
+	var all []string
+	for _, group := range [][]string{extra, {pos}, senseTags} {
+		for _, t := range group {
+			if t = strings.Join(strings.Fields(t), "_"); t != "" {
+				all = append(all, t)
+			}
+		}
+	}
+	return strings.Join(all, " ")
+}
+
+// quote quotes a field containing a separator, line break or quote
+// the way Anki's CSV reader expects.
+func quote(s string) string {
+	if !strings.ContainsAny(s, "\t\n\r\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,146 @@
+// Package appledict exports [en.WordData] as the source of a macOS
+// Dictionary.app dictionary, in the XML format of Apple's Dictionary
+// Development Kit. The generated directory is built into a .dictionary
+// bundle with the kit's build_dict.sh.
+package appledict
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+const (
+	// namespace of the d: elements and attributes
+	NAMESPACE string = "http://www.apple.com/DTDs/DictionaryService-1.0.rng"
+	XHTML     string = "http://www.w3.org/1999/xhtml"
+)
+
+// Options configures a dictionary created by [Create].
+type Options struct {
+	// CFBundleName, defaults to "Wiktionary"
+	Name string
+	// CFBundleDisplayName, defaults to Name
+	DisplayName string
+	// CFBundleIdentifier, defaults to "org.wiktionary." + Name
+	Identifier string
+	Copyright  string
+	// do not index the entries by their inflected forms
+	NoForms bool
+}
+
+// Writer writes the entries of a Dictionary.xml file.
+type Writer struct {
+	w       *bufio.Writer
+	closer  io.Closer
+	noForms bool
+	n       int
+}
+
+// NewWriter starts a Dictionary.xml document written to `w`.
+func NewWriter(w io.Writer, noForms bool) *Writer {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<d:dictionary xmlns="` + XHTML + `" xmlns:d="` + NAMESPACE + `">` + "\n")
+	return &Writer{w: bw, noForms: noForms}
+}
+
+// Create writes the source files of a dictionary to `dir`:
+// Dictionary.xml, Dictionary.css and Info.plist. Entries are added to
+// Dictionary.xml with the returned [Writer].
+func Create(dir string, opts Options) (*Writer, error) {
+	if opts.Name == "" {
+		opts.Name = "Wiktionary"
+	}
+	if opts.DisplayName == "" {
+		opts.DisplayName = opts.Name
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = "org.wiktionary." + opts.Name
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Info.plist"), infoPlist(opts), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Dictionary.css"), []byte(CSS), 0o644); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, "Dictionary.xml"))
+	if err != nil {
+		return nil, err
+	}
+	w := NewWriter(f, opts.NoForms)
+	w.closer = f
+	return w, nil
+}
+
+// Add writes `word` as a d:entry, indexed by its headword and, unless
+// disabled, by its inflected forms.
+func (w *Writer) Add(word *en.WordData) error {
+	e := article.New(word)
+	w.n++
+	title := html.EscapeString(e.Word)
+	w.w.WriteString(`<d:entry id="entry_` + strconv.Itoa(w.n) + `" d:title="` + title + `">` + "\n")
+	w.w.WriteString(`<d:index d:value="` + title + `"/>` + "\n")
+	if !w.noForms {
+		for _, f := range e.Inflections() {
+			w.w.WriteString(`<d:index d:value="` + html.EscapeString(f) + `" d:title="` + title + `"/>` + "\n")
+		}
+	}
+	w.w.WriteString(`<h1>` + title + `</h1>` + "\n")
+	w.w.WriteString(article.HTML(e))
+	_, err := w.w.WriteString("\n</d:entry>\n")
+	return err
+}
+
+// Close ends the document and closes the file opened by [Create].
+func (w *Writer) Close() error {
+	w.w.WriteString("</d:dictionary>\n")
+	err := w.w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// CSS styles the classes of the rendered articles.
+const CSS string = `@namespace d url(http://www.apple.com/DTDs/DictionaryService-1.0.rng);
+
+d|entry h1 { font-size: 150%; margin-bottom: 0.2em; }
+.pos { color: #666; margin: 0; }
+.pron, .forms { margin: 0.2em 0; }
+.tags { font-style: italic; color: #555; }
+.examples { list-style: none; color: #444; }
+.etym { margin-top: 0.8em; font-size: 90%; }
+`
+
+func infoPlist(opts Options) []byte {
+	keys := [][2]string{
+		{"CFBundleDevelopmentRegion", "English"},
+		{"CFBundleIdentifier", opts.Identifier},
+		{"CFBundleName", opts.Name},
+		{"CFBundleDisplayName", opts.DisplayName},
+		{"CFBundleShortVersionString", "1.0"},
+		{"DCSDictionaryCopyright", opts.Copyright},
+		{"DCSDictionaryManufacturerName", "Wiktionary"},
+	}
+	b := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	for _, kv := range keys {
+		if kv[1] == "" {
+			continue
+		}
+		b = append(b, "\t<key>"+kv[0]+"</key>\n\t<string>"+html.EscapeString(kv[1])+"</string>\n"...)
+	}
+	return append(b, "</dict>\n</plist>\n"...)
+}
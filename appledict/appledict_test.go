@@ -0,0 +1,152 @@
+package appledict_test
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/appledict"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var words = []*en.WordData{
+	{
+		Word: "go", Pos: "verb",
+		Sounds: []en.SoundData{{Ipa: ptr("/ɡəʊ/"), Tags: []string{"UK"}}, {Ipa: ptr("/ɡoʊ/"), Tags: []string{"US"}}},
+		Forms: []en.FormData{
+			{Form: "goes", Tags: []string{"present", "singular", "third-person"}},
+			{Form: "went", Tags: []string{"past"}},
+			{Form: "gone", Tags: []string{"past", "participle"}},
+			{Form: "went", Tags: []string{"past", "archaic"}},
+			{Form: "go", Tags: []string{"canonical"}},
+		},
+		Senses: []en.SenseData{{
+			Glosses:  []string{"To move <somewhere> & back."},
+			Examples: []en.ExampleData{{Text: `"Go away," he said.`, BoldTextOffsets: [][2]int{{1, 3}}}},
+		}},
+	},
+	{Word: "R&D", Pos: "noun", Senses: []en.SenseData{{Glosses: []string{"Research and development."}}}},
+}
+
+type entry struct {
+	title   string
+	indexes [][2]string // value, title
+	text    string
+}
+
+// parse collects the d:entry elements of a Dictionary.xml document.
+func parse(t *testing.T, r io.Reader) []entry {
+	t.Helper()
+	var entries []entry
+	var text strings.Builder
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attr := func(name string) string {
+				for _, a := range tok.Attr {
+					if a.Name.Space == appledict.NAMESPACE && a.Name.Local == name {
+						return a.Value
+					}
+				}
+				return ""
+			}
+			switch {
+			case tok.Name.Space == appledict.NAMESPACE && tok.Name.Local == "entry":
+				entries = append(entries, entry{title: attr("title")})
+				text.Reset()
+			case tok.Name.Space == appledict.NAMESPACE && tok.Name.Local == "index":
+				e := &entries[len(entries)-1]
+				e.indexes = append(e.indexes, [2]string{attr("value"), attr("title")})
+			case tok.Name.Space != appledict.XHTML && tok.Name.Space != appledict.NAMESPACE:
+				t.Errorf("element %s outside the XHTML namespace", tok.Name.Local)
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			if tok.Name.Local == "entry" {
+				entries[len(entries)-1].text = text.String()
+			}
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	w, err := appledict.Create(dir, appledict.Options{Name: "Test", Copyright: "CC BY-SA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range words {
+		if err := w.Add(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "Dictionary.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries := parse(t, f)
+	if len(entries) != 2 || entries[0].title != "go" || entries[1].title != "R&D" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	want := [][2]string{{"go", ""}, {"goes", "go"}, {"went", "go"}, {"gone", "go"}}
+	if !slices.Equal(entries[0].indexes, want) {
+		t.Errorf("indexes = %q, want %q", entries[0].indexes, want)
+	}
+	for _, want := range []string{"IPA: /ɡoʊ/ (US)", "To move <somewhere> & back.", `"Go away," he said.`} {
+		if !strings.Contains(entries[0].text, want) {
+			t.Errorf("missing %q in %q", want, entries[0].text)
+		}
+	}
+
+	var plist struct {
+		Dict struct {
+			Keys    []string `xml:"key"`
+			Strings []string `xml:"string"`
+		} `xml:"dict"`
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "Info.plist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(b, &plist); err != nil {
+		t.Fatal(err)
+	}
+	i := slices.Index(plist.Dict.Keys, "CFBundleIdentifier")
+	if i < 0 || plist.Dict.Strings[i] != "org.wiktionary.Test" {
+		t.Errorf("plist = %+v", plist)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Dictionary.css")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoForms(t *testing.T) {
+	var sb strings.Builder
+	w := appledict.NewWriter(&sb, true)
+	w.Add(words[0])
+	w.Close()
+	entries := parse(t, strings.NewReader(sb.String()))
+	if len(entries) != 1 || len(entries[0].indexes) != 1 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/anki"
+	"github.com/FreeDictionary/wiktionary-schema-go/appledict"
+	"github.com/FreeDictionary/wiktionary-schema-go/dictd"
+	"github.com/FreeDictionary/wiktionary-schema-go/dsl"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/hunspell"
+	"github.com/FreeDictionary/wiktionary-schema-go/kindle"
+	"github.com/FreeDictionary/wiktionary-schema-go/ontolex"
+	"github.com/FreeDictionary/wiktionary-schema-go/pron"
+	"github.com/FreeDictionary/wiktionary-schema-go/render"
+	"github.com/FreeDictionary/wiktionary-schema-go/stardict"
+	"github.com/FreeDictionary/wiktionary-schema-go/tei"
+	"github.com/FreeDictionary/wiktionary-schema-go/wordbin"
+	"github.com/FreeDictionary/wiktionary-schema-go/yomitan"
+)
+
+// exporter writes words in some format.
+type exporter struct {
+	add func(w *en.WordData) error
+	// finishes the export; the output is closed afterwards
+	close func() error
+}
+
+// convertOptions are the flags of convert.
+type convertOptions struct {
+	// output file, or directory for directory formats
+	out string
+	// base name of the files of directory formats
+	name     string
+	title    string
+	lang     string
+	noForms  bool
+	compress bool
+	full     bool
+	base     string
+}
+
+// format is an export format. Stream formats write to `o`; directory
+// formats write files to the -o directory and get a nil `o`.
+type format struct {
+	name string
+	desc string
+	dir  bool
+	open func(c *convertOptions, o io.Writer) (*exporter, error)
+}
+
+var formats = []format{
+	{"jsonl", "JSON lines, re-encoded", false, openJSONL},
+	{"wordbin", "binary file indexed by headword", false, openWordbin},
+	{"tei", "TEI Lex-0 XML", false, openTEI},
+	{"ttl", "OntoLex-Lemon RDF as Turtle", false, openTurtle},
+	{"nt", "OntoLex-Lemon RDF as N-Triples", false, openNTriples},
+	{"yomitan", "Yomitan dictionary archive (.zip)", false, openYomitan},
+	{"anki", "Anki notes as tab-separated text", false, openAnki},
+	{"epub", "Kindle dictionary EPUB", false, openKindle},
+	{"dsl", "ABBYY Lingvo / GoldenDict DSL", false, openDSL},
+	{"stardict", "StarDict dictionary files", true, openStarDict},
+	{"dictd", "dictd database files", true, openDictd},
+	{"appledict", "Apple Dictionary Development Kit sources", true, openAppleDict},
+	{"hunspell", "Hunspell .aff and .dic files", true, openHunspell},
+	{"cmudict", "CMUdict pronunciation dictionary", false, openCMUDict},
+	{"pron-tsv", "word, accent and IPA as tab-separated values", false, openPronTSV},
+	{"html", "HTML fragments", false, openRenderer(func(l render.Layout) render.Renderer { return render.NewHTML(l) }, "")},
+	{"md", "Markdown", false, openRenderer(func(l render.Layout) render.Renderer { return render.NewMarkdown(l) }, "\n")},
+	{"txt", "plain text wrapped to 80 columns", false, openRenderer(func(l render.Layout) render.Renderer { return render.NewTerminal(l, 80, false) }, "\n")},
+}
+
+func findFormat(name string) (format, bool) {
+	i := slices.IndexFunc(formats, func(f format) bool { return f.name == name })
+	if i < 0 {
+		return format{}, false
+	}
+	return formats[i], true
+}
+
+func runConvert(e *env, args []string) error {
+	fs := newFlagSet(e, "convert")
+	to := fs.String("to", "", "output `format`, one of "+formatNames())
+	var c convertOptions
+	fs.StringVar(&c.out, "o", "", "output `path`: a file, compressed if it ends in .gz, or the directory of directory formats")
+	fs.StringVar(&c.name, "name", "wiktionary", "base `name` of the files of directory formats")
+	fs.StringVar(&c.title, "title", "Wiktionary", "`title` of the dictionary")
+	fs.StringVar(&c.lang, "lang", "", "only convert words of this language `code`")
+	fs.BoolVar(&c.noForms, "no-forms", false, "do not index inflected forms")
+	fs.BoolVar(&c.compress, "compress", false, "compress dictd and StarDict definitions with dictzip")
+	fs.BoolVar(&c.full, "full", false, "render examples, linkages and translations (html, md, txt)")
+	fs.StringVar(&c.base, "base", "http://example.org/wiktionary/", "namespace `IRI` of the RDF resources, a placeholder to replace with one you own")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: wiktionary convert -to format [flags] [file ...]")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nformats:")
+		for _, f := range formats {
+			fmt.Fprintf(fs.Output(), "  %-10s %s\n", f.name, f.desc)
+		}
+	}
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	f, ok := findFormat(*to)
+	if !ok {
+		fmt.Fprintf(e.stderr, "wiktionary convert: unknown format %q, expected one of %s\n", *to, formatNames())
+		return errUsage
+	}
+
+	var o *output
+	var x *exporter
+	var err error
+	if f.dir {
+		if c.out == "" {
+			c.out = "."
+		}
+		if err := os.MkdirAll(c.out, 0o755); err != nil {
+			return err
+		}
+		x, err = f.open(&c, nil)
+	} else {
+		if o, err = create(e, c.out); err != nil {
+			return err
+		}
+		x, err = f.open(&c, o)
+	}
+	if err != nil {
+		if o != nil {
+			o.Close()
+		}
+		return err
+	}
+
+	err = eachLine(e, fs.Args(), func(l line) error {
+		w, err := decodeWord(l)
+		if err != nil {
+			return err
+		}
+		if c.lang != "" && w.LangCode != c.lang {
+			return nil
+		}
+		if err := x.add(w); err != nil {
+			return fmt.Errorf("%s: %w", l, err)
+		}
+		return nil
+	})
+	if cerr := x.close(); err == nil {
+		err = cerr
+	}
+	if o != nil {
+		if cerr := o.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func formatNames() string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+func openJSONL(c *convertOptions, o io.Writer) (*exporter, error) {
+	enc := json.NewEncoder(o)
+	enc.SetEscapeHTML(false)
+	return &exporter{
+		add:   func(w *en.WordData) error { return enc.Encode(w) },
+		close: func() error { return nil },
+	}, nil
+}
+
+func openWordbin(c *convertOptions, o io.Writer) (*exporter, error) {
+	w := wordbin.NewWriter(o)
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+func openTEI(c *convertOptions, o io.Writer) (*exporter, error) {
+	enc := tei.NewEncoder(o, tei.Header{Title: c.title, Source: "Wiktionary", Lang: c.lang})
+	return &exporter{add: enc.Encode, close: enc.Close}, nil
+}
+
+func openTurtle(c *convertOptions, o io.Writer) (*exporter, error) {
+	tw := ontolex.NewTurtleWriter(o, ontolex.Prefixes())
+	return &exporter{add: ontolex.NewEncoder(tw, c.base).Encode, close: tw.Close}, nil
+}
+
+func openNTriples(c *convertOptions, o io.Writer) (*exporter, error) {
+	tw := ontolex.NewNTriplesWriter(o)
+	return &exporter{add: ontolex.NewEncoder(tw, c.base).Encode, close: tw.Close}, nil
+}
+
+func openYomitan(c *convertOptions, o io.Writer) (*exporter, error) {
+	w := yomitan.NewWriter(o, yomitan.Options{Title: c.title, SourceLanguage: c.lang, NoForms: c.noForms})
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+func openAnki(c *convertOptions, o io.Writer) (*exporter, error) {
+	w, err := anki.NewWriter(o, anki.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Flush}, nil
+}
+
+func openKindle(c *convertOptions, o io.Writer) (*exporter, error) {
+	w, err := kindle.NewWriter(o, kindle.Options{Title: c.title, Language: c.lang, NoForms: c.noForms})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+func openDSL(c *convertOptions, o io.Writer) (*exporter, error) {
+	w, err := dsl.NewWriter(o, dsl.Options{Name: c.title, NoForms: c.noForms})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Flush}, nil
+}
+
+func openStarDict(c *convertOptions, _ io.Writer) (*exporter, error) {
+	w, err := stardict.Create(c.out, c.name, stardict.Options{BookName: c.title, Compress: c.compress, NoSynonyms: c.noForms})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+func openDictd(c *convertOptions, _ io.Writer) (*exporter, error) {
+	w, err := dictd.Create(c.out, c.name, dictd.Options{Short: c.title, Compress: c.compress, NoForms: c.noForms})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+func openAppleDict(c *convertOptions, _ io.Writer) (*exporter, error) {
+	w, err := appledict.Create(c.out, appledict.Options{Name: c.title, NoForms: c.noForms})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{add: w.Add, close: w.Close}, nil
+}
+
+// openHunspell collects the words and writes the .aff and .dic files
+// when the input is exhausted.
+func openHunspell(c *convertOptions, _ io.Writer) (*exporter, error) {
+	b := hunspell.NewBuilder(c.lang)
+	return &exporter{
+		add: func(w *en.WordData) error {
+			b.Add(w)
+			return nil
+		},
+		close: func() error {
+			d := b.Build()
+			if err := writeFile(filepath.Join(c.out, c.name+".aff"), d.WriteAff); err != nil {
+				return err
+			}
+			return writeFile(filepath.Join(c.out, c.name+".dic"), d.WriteDic)
+		},
+	}, nil
+}
+
+func writeFile(name string, write func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func openCMUDict(c *convertOptions, o io.Writer) (*exporter, error) {
+	w := pron.NewCMUDictWriter(o, pron.CMUDictOptions{})
+	return &exporter{add: w.Write, close: w.Flush}, nil
+}
+
+func openPronTSV(c *convertOptions, o io.Writer) (*exporter, error) {
+	w := pron.NewTSVWriter(o, pron.TSVOptions{Header: true})
+	return &exporter{add: w.Write, close: w.Flush}, nil
+}
+
+// openRenderer renders every word, writing `sep` between entries.
+func openRenderer(newRenderer func(render.Layout) render.Renderer, sep string) func(c *convertOptions, o io.Writer) (*exporter, error) {
+	return func(c *convertOptions, o io.Writer) (*exporter, error) {
+		layout := render.Compact
+		if c.full {
+			layout = render.Full
+		}
+		r := newRenderer(layout)
+		first := true
+		return &exporter{
+			add: func(w *en.WordData) error {
+				if !first {
+					if _, err := io.WriteString(o, sep); err != nil {
+						return err
+					}
+				}
+				first = false
+				return r.Render(o, w)
+			},
+			close: func() error { return nil },
+		}, nil
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// listFlag is a flag accepting comma-separated values, and repeatable.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	for v := range strings.SplitSeq(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the list is empty or contains one of `values`.
+func (l listFlag) matches(values ...string) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if slices.Contains(l, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFields are the fields the filter looks at; decoding only them
+// is much faster than decoding whole entries.
+type filterFields struct {
+	Word       string        `json:"word"`
+	LangCode   string        `json:"lang_code"`
+	Pos        string        `json:"pos"`
+	Categories []string      `json:"categories"`
+	Senses     []filterSense `json:"senses"`
+}
+
+type filterSense struct {
+	Tags       []string `json:"tags"`
+	Categories []string `json:"categories"`
+}
+
+// filter selects words; values of one flag are alternatives, and a word
+// must match all flags given.
+type filter struct {
+	langs      listFlag
+	pos        listFlag
+	tags       listFlag
+	categories listFlag
+	words      listFlag
+}
+
+func (f *filter) match(w *filterFields) bool {
+	if !f.langs.matches(w.LangCode) || !f.pos.matches(w.Pos) || !f.words.matches(w.Word) {
+		return false
+	}
+	if len(f.tags) > 0 && !slices.ContainsFunc(w.Senses, func(s filterSense) bool {
+		return f.tags.matches(s.Tags...)
+	}) {
+		return false
+	}
+	if len(f.categories) > 0 {
+		found := f.categories.matches(w.Categories...)
+		for _, s := range w.Senses {
+			found = found || f.categories.matches(s.Categories...)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func runFilter(e *env, args []string) error {
+	fs := newFlagSet(e, "filter")
+	var f filter
+	fs.Var(&f.langs, "lang", "language `codes`, e.g. en,fr")
+	fs.Var(&f.pos, "pos", "parts of speech, e.g. noun,verb")
+	fs.Var(&f.tags, "tag", "tags of one of the senses, e.g. archaic")
+	fs.Var(&f.categories, "category", "categories of the word or one of its senses")
+	fs.Var(&f.words, "word", "headwords")
+	out := fs.String("o", "", "output `file`, compressed if it ends in .gz")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	o, err := create(e, *out)
+	if err != nil {
+		return err
+	}
+	err = eachLine(e, fs.Args(), func(l line) error {
+		var w filterFields
+		if err := json.Unmarshal(l.data, &w); err != nil {
+			return fmt.Errorf("%s: %w", l, err)
+		}
+		if !f.match(&w) {
+			return nil
+		}
+		o.Write(l.data)
+		return o.WriteByte('\n')
+	})
+	if cerr := o.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
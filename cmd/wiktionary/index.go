@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/jsonlindex"
+)
+
+func runIndex(e *env, args []string) error {
+	fs := newFlagSet(e, "index")
+	seekable := fs.String("gzip", "", "recompress the dump into this seekable gzip `file` and index it instead")
+	size := fs.Int("member-size", jsonlindex.MEMBER_SIZE, "uncompressed `bytes` per gzip member written by -gzip")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	name := fs.Arg(0)
+
+	if *seekable != "" {
+		if err := recompress(*seekable, name, *size); err != nil {
+			return err
+		}
+		name = *seekable
+	}
+	if err := jsonlindex.BuildFile(name); err != nil {
+		return err
+	}
+	x, err := jsonlindex.OpenIndex(jsonlindex.IndexName(name))
+	if err != nil {
+		return err
+	}
+	defer x.Close()
+	fmt.Fprintf(e.stderr, "indexed %d entries in %s\n", x.Len(), jsonlindex.IndexName(name))
+	return nil
+}
+
+// recompress writes the dump `src`, decompressed if needed, to `dst`
+// as gzip members.
+func recompress(dst, src string, size int) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r, err := decompress(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := jsonlindex.Recompress(out, r, size); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// maxLine is the longest line accepted; the longest wiktextract entries
+// are a few megabytes.
+const maxLine = 256 << 20
+
+// line is a line of an input file.
+type line struct {
+	// file name, "-" for standard input
+	name string
+	// 1-based line number, 0 if unknown
+	n int
+	// only valid until the next line is read
+	data []byte
+}
+
+func (l line) String() string {
+	if l.n == 0 {
+		return l.name
+	}
+	return fmt.Sprintf("%s:%d", l.name, l.n)
+}
+
+// eachLine calls `fn` with the non-empty lines of the files `names`, or
+// of standard input if there are none, and stops at the first error.
+func eachLine(e *env, names []string, fn func(l line) error) error {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	for _, name := range names {
+		if err := readLines(e, name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLines(e *env, name string, fn func(l line) error) error {
+	var r io.Reader = e.stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	r, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64<<10), maxLine)
+	n := 0
+	for s.Scan() {
+		n++
+		data := bytes.TrimSpace(s.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := fn(line{name, n, data}); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("%s:%d: %w", name, n+1, err)
+	}
+	return nil
+}
+
+// decompress detects gzip and bzip2 streams by their magic numbers and
+// returns a reader of the decompressed data. Other streams are returned
+// as they are.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	magic, err := br.Peek(3)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		// concatenated members, as written by pigz, are read as one stream
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return bzip2.NewReader(br), nil
+	}
+	return br, nil
+}
+
+// decodeWord decodes a line as word data.
+func decodeWord(l line) (*en.WordData, error) {
+	w := new(en.WordData)
+	if err := json.Unmarshal(l.data, w); err != nil {
+		return nil, fmt.Errorf("%s: %w", l, err)
+	}
+	return w, nil
+}
+
+// output is where a command writes its results.
+type output struct {
+	*bufio.Writer
+	closers []io.Closer
+}
+
+// create opens the output `name`: standard output for "" or "-", else
+// a file, gzip-compressed when the name ends in ".gz".
+func create(e *env, name string) (*output, error) {
+	var w io.Writer = e.stdout
+	o := &output{}
+	if name != "" && name != "-" {
+		f, err := os.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		o.closers = append(o.closers, f)
+		w = f
+		if strings.HasSuffix(name, ".gz") {
+			zw := gzip.NewWriter(f)
+			o.closers = append(o.closers, zw)
+			w = zw
+		}
+	}
+	o.Writer = bufio.NewWriterSize(w, 1<<16)
+	return o, nil
+}
+
+// Close flushes the output and closes the compressor and file, if any.
+func (o *output) Close() error {
+	err := o.Flush()
+	for i := len(o.closers) - 1; i >= 0; i-- {
+		if cerr := o.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
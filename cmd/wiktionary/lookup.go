@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/jsonlindex"
+	"github.com/FreeDictionary/wiktionary-schema-go/render"
+)
+
+// lookupFields are decoded to decide whether a line is a match before
+// decoding it completely.
+type lookupFields struct {
+	Word     string `json:"word"`
+	LangCode string `json:"lang_code"`
+}
+
+func runLookup(e *env, args []string) error {
+	fs := newFlagSet(e, "lookup")
+	var langs listFlag
+	fs.Var(&langs, "lang", "only show these language `codes`")
+	fold := fs.Bool("i", false, "match the word case-insensitively")
+	format := fs.String("format", "text", "output `format`: text, html, md or json")
+	full := fs.Bool("full", false, "also show examples, linkages, translations and etymology")
+	color := fs.Bool("color", isTerminal(e.stdout), "style text output with ANSI escape sequences")
+	width := fs.Int("width", terminalWidth(), "wrap text output to this many `columns`")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	word, files := fs.Arg(0), fs.Args()[1:]
+
+	layout := render.Compact
+	if *full {
+		layout = render.Full
+	}
+	var r render.Renderer
+	switch *format {
+	case "text":
+		r = render.NewTerminal(layout, *width, *color)
+	case "html":
+		r = render.NewHTML(layout)
+	case "md":
+		r = render.NewMarkdown(layout)
+	case "json":
+	default:
+		fmt.Fprintf(e.stderr, "wiktionary lookup: unknown format %q\n", *format)
+		return errUsage
+	}
+
+	ix, err := openIndexed(e, files, *fold)
+	if err != nil {
+		return err
+	}
+	o, err := create(e, "")
+	if err != nil {
+		if ix != nil {
+			ix.Close()
+		}
+		return err
+	}
+	found := 0
+	lang := ""
+	// emit writes a matching line
+	emit := func(l line) error {
+		found++
+		if r == nil {
+			o.Write(l.data)
+			return o.WriteByte('\n')
+		}
+		w, err := decodeWord(l)
+		if err != nil {
+			return err
+		}
+		if found > 1 {
+			o.WriteByte('\n')
+		}
+		if *format == "text" && w.Lang != lang {
+			lang = w.Lang
+			fmt.Fprintf(o, "%s\n\n", lang)
+		}
+		return r.Render(o, w)
+	}
+
+	if ix != nil {
+		err = lookupIndexed(ix, files[0], word, langs, emit)
+		ix.Close()
+	} else {
+		// the word appears as it is in matching lines, wiktextract does
+		// not escape non-ASCII characters
+		needle := []byte(word)
+		err = eachLine(e, files, func(l line) error {
+			if !*fold && !bytes.Contains(l.data, needle) {
+				return nil
+			}
+			var f lookupFields
+			if err := json.Unmarshal(l.data, &f); err != nil {
+				return fmt.Errorf("%s: %w", l, err)
+			}
+			if f.Word != word && !(*fold && strings.EqualFold(f.Word, word)) || !langs.matches(f.LangCode) {
+				return nil
+			}
+			return emit(l)
+		})
+	}
+	if cerr := o.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && found == 0 {
+		err = fmt.Errorf("%q not found", word)
+	}
+	return err
+}
+
+// openIndexed opens the only file of `files` with its index, if it has
+// one; the index only serves exact matches. An index that cannot be read
+// is reported and the file scanned instead, but a stale index is an
+// error: it is rebuilt with "wiktionary index".
+func openIndexed(e *env, files []string, fold bool) (*jsonlindex.Reader, error) {
+	if len(files) != 1 || fold {
+		return nil, nil
+	}
+	if _, err := os.Stat(jsonlindex.IndexName(files[0])); err != nil {
+		return nil, nil
+	}
+	ix, err := jsonlindex.Open(files[0])
+	if errors.Is(err, jsonlindex.ErrStale) {
+		return nil, fmt.Errorf("%w; rebuild it with wiktionary index", err)
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "wiktionary lookup: %v; scanning %s\n", err, files[0])
+		return nil, nil
+	}
+	return ix, nil
+}
+
+// lookupIndexed emits the entries of `word` read through the index.
+func lookupIndexed(ix *jsonlindex.Reader, name, word string, langs listFlag, emit func(line) error) error {
+	entries, err := ix.Find(jsonlindex.Key{Word: word})
+	if err != nil {
+		return err
+	}
+	for _, ent := range entries {
+		if !langs.matches(ent.LangCode) {
+			continue
+		}
+		data, err := ix.Raw(ent)
+		if err != nil {
+			return err
+		}
+		if err := emit(line{name: fmt.Sprintf("%s@%d", name, ent.Offset), data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTerminal reports whether `w` is a character device, such as a
+// terminal.
+func isTerminal(w any) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns $COLUMNS, or 80 if it is not set.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
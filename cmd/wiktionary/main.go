@@ -0,0 +1,130 @@
+// Command wiktionary filters, converts and inspects wiktextract JSONL
+// dumps of the English Wiktionary.
+//
+// Usage:
+//
+//	wiktionary <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	filter    print the words matching language, part of speech, tag or category
+//	convert   export words to a dictionary format
+//	stats     count words and report how often each field is set
+//	lookup    show a word in every language it exists in
+//	validate  report lines that do not decode as word data
+//	index     write the byte offset index used by lookup
+//	serve     serve a file over the JSON HTTP API of package server
+//
+// Every command reads the files given as arguments, or standard input
+// when there are none or the file is "-". Files compressed with gzip or
+// bzip2 are decompressed transparently. Output goes to standard output
+// unless -o is given; an output file ending in ".gz" is compressed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is a subcommand; run returns an error for failures, which are
+// reported with the command name.
+type command struct {
+	name  string
+	usage string
+	run   func(e *env, args []string) error
+}
+
+// env holds the standard streams so that commands can be tested.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = []command{
+	{"filter", "filter [-lang code] [-pos pos] [-tag tag] [-category category] [-word word] [-o file] [file ...]", runFilter},
+	{"convert", "convert -to format [-o path] [-name name] [file ...]", runConvert},
+	{"stats", "stats [-json] [file ...]", runStats},
+	{"lookup", "lookup [-lang code] [-i] [-format text|html|md|json] [-full] word [file ...]", runLookup},
+	{"validate", "validate [-strict] [-max n] [file ...]", runValidate},
+	{"index", "index [-gzip seekable.jsonl.gz] [-member-size bytes] file", runIndex},
+	{"serve", "serve [-addr address] file", runServe},
+}
+
+// errInvalid is returned by validate when some lines are invalid; the
+// lines have already been reported.
+var errInvalid = errors.New("invalid lines found")
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{os.Stdin, os.Stdout, os.Stderr}))
+}
+
+// run executes the command line and returns the exit status: 0 on
+// success, 1 when the command failed and 2 on usage errors.
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(e.stdout)
+		return 0
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		err := c.run(e, args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(e.stderr, "usage: wiktionary %s\n", c.usage)
+			return 2
+		case errors.Is(err, errInvalid):
+			return 1
+		}
+		fmt.Fprintf(e.stderr, "wiktionary %s: %v\n", c.name, err)
+		return 1
+	}
+	fmt.Fprintf(e.stderr, "wiktionary: unknown command %q\n", args[0])
+	usage(e.stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: wiktionary <command> [flags] [file ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\n", c.usage)
+	}
+}
+
+// errUsage reports invalid flags or arguments; the flag package has
+// already printed the details.
+var errUsage = errors.New("usage")
+
+// newFlagSet returns a flag set printing its errors to the standard
+// error of `e`.
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parse parses `args`, turning parse errors into errUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/jsonlindex"
+)
+
+const words = `{"word":"water","lang":"English","lang_code":"en","pos":"noun","categories":["en:Liquids"],"forms":[{"form":"waters","tags":["plural"]}],"senses":[{"glosses":["A clear liquid."],"tags":["uncountable"]}],"sounds":[{"ipa":"/ˈwɔːtə/"}]}
+{"word":"water","lang":"English","lang_code":"en","pos":"verb","senses":[{"glosses":["To pour water on."]}]}
+
+{"word":"Water","lang":"German","lang_code":"de","pos":"noun","senses":[{"glosses":["father"],"tags":["dialectal"],"categories":["Low German"]}]}
+{"word":"eau","lang":"French","lang_code":"fr","pos":"noun","senses":[{"glosses":["water"],"tags":["feminine"]}]}
+`
+
+// runWith runs the command line with `stdin` and returns the exit
+// status, standard output and standard error.
+func runWith(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{strings.NewReader(stdin), &stdout, &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+// wordsOf returns the "word/lang_code/pos" of JSON lines.
+func wordsOf(t *testing.T, out string) []string {
+	t.Helper()
+	var ws []string
+	for l := range strings.Lines(out) {
+		var f filterFields
+		if err := json.Unmarshal([]byte(l), &f); err != nil {
+			t.Fatalf("%q: %v", l, err)
+		}
+		ws = append(ws, f.Word+"/"+f.LangCode+"/"+f.Pos)
+	}
+	return ws
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"water/en/noun", "water/en/verb", "Water/de/noun", "eau/fr/noun"}},
+		{[]string{"-lang", "en"}, []string{"water/en/noun", "water/en/verb"}},
+		{[]string{"-lang", "de,fr"}, []string{"Water/de/noun", "eau/fr/noun"}},
+		{[]string{"-lang", "de", "-lang", "fr", "-pos", "noun"}, []string{"Water/de/noun", "eau/fr/noun"}},
+		{[]string{"-pos", "verb"}, []string{"water/en/verb"}},
+		{[]string{"-tag", "feminine"}, []string{"eau/fr/noun"}},
+		{[]string{"-tag", "uncountable", "-lang", "de"}, nil},
+		{[]string{"-category", "en:Liquids"}, []string{"water/en/noun"}},
+		{[]string{"-category", "Low German"}, []string{"Water/de/noun"}},
+		{[]string{"-word", "water"}, []string{"water/en/noun", "water/en/verb"}},
+	}
+	for _, tt := range tests {
+		code, out, stderr := runWith(t, words, append([]string{"filter"}, tt.args...)...)
+		if code != 0 {
+			t.Fatalf("filter %v: exit %d: %s", tt.args, code, stderr)
+		}
+		if got := wordsOf(t, out); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("filter %v = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "words.jsonl.gz")
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(words))
+	zw.Close()
+	if err := os.WriteFile(in, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "en.jsonl.gz")
+	if code, _, stderr := runWith(t, "", "filter", "-lang", "en", "-o", out, in); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	got.ReadFrom(zr)
+	if ws := wordsOf(t, got.String()); strings.Join(ws, " ") != "water/en/noun water/en/verb" {
+		t.Errorf("got %v", ws)
+	}
+
+	// standard input is decompressed too, and "-" names it
+	code, stdout, stderr := runWith(t, buf.String(), "filter", "-lang", "fr", "-")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if ws := wordsOf(t, stdout); strings.Join(ws, " ") != "eau/fr/noun" {
+		t.Errorf("got %v", ws)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		format   string
+		args     []string
+		contains []string
+	}{
+		{"jsonl", []string{"-lang", "fr"}, []string{`"word":"eau"`}},
+		{"wordbin", nil, []string{"WIKTBIN"}},
+		{"tei", nil, []string{"<TEI", "<orth>water</orth>", "</TEI>"}},
+		{"nt", nil, []string{"<http://example.org/wiktionary/en/water/noun> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/ns/lemon/ontolex#LexicalEntry>"}},
+		{"ttl", []string{"-base", "https://dict.test/"}, []string{"@prefix ontolex:", "<https://dict.test/en/water/noun>"}},
+		{"anki", nil, []string{"#separator:tab", "A clear liquid."}},
+		{"cmudict", []string{"-lang", "en"}, []string{"WATER"}},
+		{"pron-tsv", nil, []string{"water\t\tˈwɔːtə"}},
+		{"md", []string{"-lang", "en"}, []string{"## water", "To pour water on."}},
+		{"html", []string{"-lang", "de"}, []string{"father"}},
+		{"txt", []string{"-lang", "fr", "-full"}, []string{"eau", "1. (feminine) water"}},
+	}
+	for _, tt := range tests {
+		code, out, stderr := runWith(t, words, append([]string{"convert", "-to", tt.format}, tt.args...)...)
+		if code != 0 {
+			t.Fatalf("convert -to %s: exit %d: %s", tt.format, code, stderr)
+		}
+		for _, s := range tt.contains {
+			if !strings.Contains(out, s) {
+				t.Errorf("convert -to %s: %q not found in:\n%s", tt.format, s, out)
+			}
+		}
+	}
+}
+
+func TestConvertArchive(t *testing.T) {
+	code, out, stderr := runWith(t, words, "convert", "-to", "yomitan")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	zr, err := zip.NewReader(strings.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if !strings.Contains(strings.Join(names, " "), "index.json") {
+		t.Errorf("files = %v", names)
+	}
+}
+
+func TestConvertDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	tests := []struct {
+		format string
+		files  []string
+	}{
+		{"stardict", []string{"wiktionary.ifo", "wiktionary.idx", "wiktionary.dict"}},
+		{"dictd", []string{"wiktionary.index", "wiktionary.dict"}},
+		{"appledict", []string{"Dictionary.xml", "Info.plist"}},
+		{"hunspell", []string{"wiktionary.aff", "wiktionary.dic"}},
+	}
+	for _, tt := range tests {
+		code, _, stderr := runWith(t, words, "convert", "-to", tt.format, "-lang", "en", "-o", dir)
+		if code != 0 {
+			t.Fatalf("convert -to %s: exit %d: %s", tt.format, code, stderr)
+		}
+		for _, name := range tt.files {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("convert -to %s: %v", tt.format, err)
+			}
+		}
+	}
+	dic, err := os.ReadFile(filepath.Join(dir, "wiktionary.dic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(dic); got != "1\nwater/1\n" {
+		t.Errorf("wiktionary.dic = %q", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	code, out, stderr := runWith(t, words, "stats", "-json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	var s Stats
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Records != 4 || s.Senses != 4 {
+		t.Errorf("records, senses = %d, %d", s.Records, s.Senses)
+	}
+	if s.Languages["en"] != 2 || s.Languages["de"] != 1 || s.Pos["noun"] != 3 {
+		t.Errorf("languages = %v, pos = %v", s.Languages, s.Pos)
+	}
+	if s.Fields["word"] != 4 || s.Fields["forms"] != 1 || s.Fields["categories"] != 1 {
+		t.Errorf("fields = %v", s.Fields)
+	}
+	if s.SenseFields["glosses"] != 4 || s.SenseFields["tags"] != 3 {
+		t.Errorf("sense fields = %v", s.SenseFields)
+	}
+
+	code, out, _ = runWith(t, words, "stats", "-top", "1")
+	if code != 0 || !strings.Contains(out, "(2 more)") || !strings.Contains(out, "50.0%") {
+		t.Errorf("exit %d:\n%s", code, out)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	code, out, stderr := runWith(t, words, "lookup", "-color=false", "water")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	want := "English\n\nwater  noun\n  1. (uncountable) A clear liquid.\n\nwater  verb\n  1. To pour water on.\n"
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+
+	code, out, _ = runWith(t, words, "lookup", "-i", "-format", "json", "water")
+	if code != 0 || len(wordsOf(t, out)) != 3 {
+		t.Errorf("exit %d:\n%s", code, out)
+	}
+	code, out, _ = runWith(t, words, "lookup", "-i", "-lang", "de", "-format", "json", "WATER")
+	if ws := wordsOf(t, out); code != 0 || strings.Join(ws, " ") != "Water/de/noun" {
+		t.Errorf("exit %d: %v", code, ws)
+	}
+	if code, _, stderr = runWith(t, words, "lookup", "fire"); code != 1 || !strings.Contains(stderr, "not found") {
+		t.Errorf("exit %d: %s", code, stderr)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "words.jsonl")
+	if err := os.WriteFile(in, []byte(words), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	seekable := filepath.Join(dir, "seekable.jsonl.gz")
+	for _, args := range [][]string{{in}, {"-gzip", seekable, "-member-size", "100", in}} {
+		code, _, stderr := runWith(t, "", append([]string{"index"}, args...)...)
+		if code != 0 || !strings.Contains(stderr, "indexed 4 entries") {
+			t.Fatalf("index %v: exit %d: %s", args, code, stderr)
+		}
+	}
+	for _, name := range []string{in, seekable} {
+		if _, err := os.Stat(name + ".idx"); err != nil {
+			t.Fatal(err)
+		}
+		code, out, stderr := runWith(t, "", "lookup", "-format", "json", "-lang", "en", "water", name)
+		if ws := wordsOf(t, out); code != 0 || strings.Join(ws, " ") != "water/en/noun water/en/verb" {
+			t.Errorf("%s: exit %d: %v %s", name, code, ws, stderr)
+		}
+	}
+
+	// an unreadable index is reported and the dump scanned
+	if err := os.WriteFile(in+".idx", []byte("not an index"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, stderr := runWith(t, "", "lookup", "-format", "json", "water", in)
+	if code != 0 || len(wordsOf(t, out)) != 2 || !strings.Contains(stderr, jsonlindex.ErrFormat.Error()) {
+		t.Errorf("unreadable index: exit %d: %s\n%s", code, stderr, out)
+	}
+
+	// a stale index is an error
+	if code, _, stderr := runWith(t, "", "index", in); code != 0 {
+		t.Fatalf("index: exit %d: %s", code, stderr)
+	}
+	if err := os.WriteFile(in, []byte(words+`{"word":"fire","lang":"English","lang_code":"en","pos":"noun"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, stderr = runWith(t, "", "lookup", "-format", "json", "fire", in)
+	if code != 1 || out != "" || !strings.Contains(stderr, jsonlindex.ErrStale.Error()) {
+		t.Errorf("stale index: exit %d: %s\n%s", code, stderr, out)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if code, out, stderr := runWith(t, words, "validate", "-strict"); code != 0 {
+		t.Errorf("exit %d: %s%s", code, out, stderr)
+	}
+	in := words + `{"word":"x","lang":"English","lang_code":"en"}
+{"word":"y","lang":"English","lang_code":"en","pos":"noun","unknown":1}
+not json
+`
+	code, out, _ := runWith(t, in, "validate")
+	if code != 1 || !strings.Contains(out, "-:6: missing pos\n") || !strings.Contains(out, "-:8: ") || strings.Contains(out, "-:7:") {
+		t.Errorf("exit %d:\n%s", code, out)
+	}
+	code, out, stderr := runWith(t, in, "validate", "-strict")
+	if code != 1 || !strings.Contains(out, `-:7: json: unknown field "unknown"`) || !strings.Contains(stderr, "3 of 7 lines invalid") {
+		t.Errorf("exit %d:\n%s%s", code, out, stderr)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{nil, 2},
+		{[]string{"help"}, 0},
+		{[]string{"frobnicate"}, 2},
+		{[]string{"filter", "-nope"}, 2},
+		{[]string{"convert", "-to", "pdf"}, 2},
+		{[]string{"lookup"}, 2},
+		{[]string{"index"}, 2},
+		{[]string{"stats", "-h"}, 0},
+	}
+	for _, tt := range tests {
+		if code, _, _ := runWith(t, "", tt.args...); code != tt.code {
+			t.Errorf("%v: exit %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
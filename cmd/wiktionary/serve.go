@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/server"
+)
+
+func runServe(e *env, args []string) error {
+	fs := newFlagSet(e, "serve")
+	addr := fs.String("addr", "localhost:8080", "`address` to listen on")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	start := time.Now()
+	c, err := server.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	fmt.Fprintf(e.stderr, "indexed %d entries in %v, serving on http://%s/\n", c.Len(), time.Since(start).Round(time.Millisecond), *addr)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(c),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
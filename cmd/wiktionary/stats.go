@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+)
+
+// Stats are the counts reported by the stats command.
+type Stats struct {
+	Records int `json:"records"`
+	Senses  int `json:"senses"`
+	// by language code
+	Languages map[string]int `json:"languages"`
+	// by part of speech
+	Pos map[string]int `json:"pos"`
+	// number of records in which each top-level field is set
+	Fields map[string]int `json:"fields"`
+	// number of senses in which each sense field is set
+	SenseFields map[string]int `json:"sense_fields"`
+}
+
+func newStats() *Stats {
+	return &Stats{
+		Languages:   make(map[string]int),
+		Pos:         make(map[string]int),
+		Fields:      make(map[string]int),
+		SenseFields: make(map[string]int),
+	}
+}
+
+// add counts a record given as its top-level fields.
+func (s *Stats) add(fields map[string]json.RawMessage) error {
+	s.Records++
+	for k, v := range fields {
+		if isSet(v) {
+			s.Fields[k]++
+		}
+	}
+	var code, pos string
+	json.Unmarshal(fields["lang_code"], &code)
+	json.Unmarshal(fields["pos"], &pos)
+	s.Languages[code]++
+	s.Pos[pos]++
+
+	if !isSet(fields["senses"]) {
+		return nil
+	}
+	var senses []map[string]json.RawMessage
+	if err := json.Unmarshal(fields["senses"], &senses); err != nil {
+		return fmt.Errorf("senses: %w", err)
+	}
+	for _, sense := range senses {
+		s.Senses++
+		for k, v := range sense {
+			if isSet(v) {
+				s.SenseFields[k]++
+			}
+		}
+	}
+	return nil
+}
+
+// isSet reports whether a JSON value is not null, "", [] or {}.
+func isSet(v json.RawMessage) bool {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 || string(v) == "null" || string(v) == `""` {
+		return false
+	}
+	if v[0] == '[' || v[0] == '{' {
+		return len(bytes.TrimSpace(v[1:len(v)-1])) > 0
+	}
+	return true
+}
+
+func runStats(e *env, args []string) error {
+	fs := newFlagSet(e, "stats")
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	top := fs.Int("top", 20, "number of languages and parts of speech listed, 0 for all")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	s := newStats()
+	err := eachLine(e, fs.Args(), func(l line) error {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(l.data, &fields); err != nil {
+			return fmt.Errorf("%s: %w", l, err)
+		}
+		if err := s.add(fields); err != nil {
+			return fmt.Errorf("%s: %w", l, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	return s.write(e.stdout, *top)
+}
+
+// write prints the statistics as aligned tables.
+func (s *Stats) write(w io.Writer, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "records\t%d\t\n", s.Records)
+	fmt.Fprintf(tw, "senses\t%d\t\n", s.Senses)
+	writeCounts(tw, "languages", s.Languages, s.Records, top)
+	writeCounts(tw, "parts of speech", s.Pos, s.Records, top)
+	writeCounts(tw, "fields", s.Fields, s.Records, 0)
+	writeCounts(tw, "sense fields", s.SenseFields, s.Senses, 0)
+	return tw.Flush()
+}
+
+// writeCounts prints the `top` largest counts with their share of
+// `total`.
+func writeCounts(w io.Writer, title string, counts map[string]int, total, top int) {
+	keys := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	fmt.Fprintf(w, "\t\t\n%s\t\t\n", title)
+	for i, k := range keys {
+		if top > 0 && i == top {
+			fmt.Fprintf(w, "  (%d more)\t\t\n", len(keys)-top)
+			break
+		}
+		label := k
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%.1f%%\t\n", label, counts[k], 100*float64(counts[k])/float64(max(total, 1)))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// validateWord decodes a line and checks the fields every word has. In
+// strict mode, fields unknown to [en.WordData] are errors too.
+func validateWord(data []byte, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	var w en.WordData
+	if err := dec.Decode(&w); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("data after the JSON object")
+	}
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"word", w.Word}, {"lang", w.Lang}, {"lang_code", w.LangCode}, {"pos", w.Pos},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func runValidate(e *env, args []string) error {
+	fs := newFlagSet(e, "validate")
+	strict := fs.Bool("strict", false, "report fields that are not in the schema")
+	maxErrors := fs.Int("max", 100, "stop reporting after this many invalid lines, 0 for no limit")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	o, err := create(e, "")
+	if err != nil {
+		return err
+	}
+	total, invalid := 0, 0
+	err = eachLine(e, fs.Args(), func(l line) error {
+		total++
+		if err := validateWord(l.data, *strict); err != nil {
+			invalid++
+			if *maxErrors == 0 || invalid <= *maxErrors {
+				fmt.Fprintf(o, "%s: %v\n", l, err)
+			}
+		}
+		return nil
+	})
+	if cerr := o.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		fmt.Fprintf(e.stderr, "%d of %d lines invalid\n", invalid, total)
+		return errInvalid
+	}
+	return nil
+}
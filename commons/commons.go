@@ -0,0 +1,128 @@
+// Package commons resolves the Wikimedia Commons file names found in
+// `SoundData.Audio` into download URLs. Everything is computed offline
+// from the file name, the same way MediaWiki lays out its uploads.
+package commons
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// UPLOAD_BASE is the root of Wikimedia Commons uploads.
+const UPLOAD_BASE string = "https://upload.wikimedia.org/wikipedia/commons/"
+
+// FileName normalizes a Commons file name the way MediaWiki does: the
+// "File:" namespace is dropped, spaces become underscores and the first
+// letter is upper-cased.
+func FileName(name string) string {
+	name = strings.TrimSpace(name)
+	for _, ns := range []string{"File:", "file:", "Image:", "image:"} {
+		name = strings.TrimPrefix(name, ns)
+	}
+	name = strings.Join(strings.Fields(name), "_")
+	r, size := utf8.DecodeRuneInString(name)
+	if r == utf8.RuneError {
+		return name
+	}
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// hashPath returns the "a/ab/" directory of a normalized file name,
+// made of the first one and two hex digits of its MD5 sum.
+func hashPath(name string) string {
+	sum := md5.Sum([]byte(name))
+	h := hex.EncodeToString(sum[:1])
+	return h[:1] + "/" + h + "/"
+}
+
+// URL returns the URL of the original upload of a file, e.g.
+// https://upload.wikimedia.org/wikipedia/commons/a/a9/Example.jpg.
+func URL(name string) string {
+	name = FileName(name)
+	return UPLOAD_BASE + hashPath(name) + escape(name)
+}
+
+// TranscodedURL returns the URL of a transcoded version of a file, where
+// `ext` is the extension of the wanted format (e.g. "mp3" or "ogg").
+func TranscodedURL(name, ext string) string {
+	name = FileName(name)
+	escaped := escape(name)
+	return UPLOAD_BASE + "transcoded/" + hashPath(name) + escaped + "/" + escaped + "." + ext
+}
+
+// Mp3URL returns the URL of the MP3 version of an audio file: the
+// original for .mp3 files and a transcoded version otherwise.
+func Mp3URL(name string) string {
+	if hasExt(name, ".mp3") {
+		return URL(name)
+	}
+	return TranscodedURL(name, "mp3")
+}
+
+// OggURL returns the URL of the Ogg version of an audio file: the
+// original for .ogg and .oga files and a transcoded version otherwise.
+func OggURL(name string) string {
+	if hasExt(name, ".ogg") || hasExt(name, ".oga") {
+		return URL(name)
+	}
+	return TranscodedURL(name, "ogg")
+}
+
+func hasExt(name, ext string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(name)), ext)
+}
+
+// Fill sets `Mp3Url` and `OggUrl` of a sound that has an `Audio` file
+// name, unless they are already present. It reports whether anything
+// was changed.
+func Fill(s *en.SoundData) bool {
+	if s.Audio == nil || strings.TrimSpace(*s.Audio) == "" {
+		return false
+	}
+	changed := false
+	if s.Mp3Url == nil {
+		u := Mp3URL(*s.Audio)
+		s.Mp3Url = &u
+		changed = true
+	}
+	if s.OggUrl == nil {
+		u := OggURL(*s.Audio)
+		s.OggUrl = &u
+		changed = true
+	}
+	return changed
+}
+
+// FillWord calls [Fill] on every sound of `w` and returns the number of
+// sounds changed.
+func FillWord(w *en.WordData) int {
+	n := 0
+	for i := range w.Sounds {
+		if Fill(&w.Sounds[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// escape percent-encodes a file name like MediaWiki's wfUrlencode, which
+// keeps a few reserved characters such as parentheses and commas.
+func escape(name string) string {
+	const keep = "-_.~;:@$!*(),/"
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < utf8.RuneSelf && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(keep, c) >= 0) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return sb.String()
+}
@@ -0,0 +1,137 @@
+package commons_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/commons"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"Example.jpg":                       "Example.jpg",
+		"File:En-us-tomato.ogg":             "En-us-tomato.ogg",
+		"en-us-tomato.ogg":                  "En-us-tomato.ogg",
+		"LL-Q1860 (eng)-Vealhurl-water.wav": "LL-Q1860_(eng)-Vealhurl-water.wav",
+		" fr-château.ogg ":                  "Fr-château.ogg",
+		"éclair.ogg":                        "Éclair.ogg",
+	}
+	for in, want := range tests {
+		if got := commons.FileName(in); got != want {
+			t.Errorf("FileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestURLs(t *testing.T) {
+	tests := []struct {
+		name, url, mp3, ogg string
+	}{
+		{
+			"Example.jpg",
+			"https://upload.wikimedia.org/wikipedia/commons/a/a9/Example.jpg",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/a/a9/Example.jpg/Example.jpg.mp3",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/a/a9/Example.jpg/Example.jpg.ogg",
+		},
+		{
+			"En-us-tomato.ogg",
+			"https://upload.wikimedia.org/wikipedia/commons/7/78/En-us-tomato.ogg",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/7/78/En-us-tomato.ogg/En-us-tomato.ogg.mp3",
+			"https://upload.wikimedia.org/wikipedia/commons/7/78/En-us-tomato.ogg",
+		},
+		{
+			"LL-Q1860 (eng)-Vealhurl-water.wav",
+			"https://upload.wikimedia.org/wikipedia/commons/f/f9/LL-Q1860_(eng)-Vealhurl-water.wav",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/f/f9/LL-Q1860_(eng)-Vealhurl-water.wav/LL-Q1860_(eng)-Vealhurl-water.wav.mp3",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/f/f9/LL-Q1860_(eng)-Vealhurl-water.wav/LL-Q1860_(eng)-Vealhurl-water.wav.ogg",
+		},
+		{
+			"Fr-château.ogg",
+			"https://upload.wikimedia.org/wikipedia/commons/3/32/Fr-ch%C3%A2teau.ogg",
+			"https://upload.wikimedia.org/wikipedia/commons/transcoded/3/32/Fr-ch%C3%A2teau.ogg/Fr-ch%C3%A2teau.ogg.mp3",
+			"https://upload.wikimedia.org/wikipedia/commons/3/32/Fr-ch%C3%A2teau.ogg",
+		},
+	}
+	for _, tt := range tests {
+		if got := commons.URL(tt.name); got != tt.url {
+			t.Errorf("URL(%q) = %q, want %q", tt.name, got, tt.url)
+		}
+		if got := commons.Mp3URL(tt.name); got != tt.mp3 {
+			t.Errorf("Mp3URL(%q) = %q, want %q", tt.name, got, tt.mp3)
+		}
+		if got := commons.OggURL(tt.name); got != tt.ogg {
+			t.Errorf("OggURL(%q) = %q, want %q", tt.name, got, tt.ogg)
+		}
+	}
+}
+
+func TestFillWord(t *testing.T) {
+	w := &en.WordData{Word: "tomato", Sounds: []en.SoundData{
+		{Audio: ptr("En-us-tomato.ogg")},
+		{Audio: ptr("En-uk-tomato.ogg"), Mp3Url: ptr("https://example.org/tomato.mp3")},
+		{Ipa: ptr("/təˈmeɪtoʊ/")},
+	}}
+	if n := commons.FillWord(w); n != 2 {
+		t.Errorf("FillWord changed %d sounds, want 2", n)
+	}
+	if got := *w.Sounds[0].OggUrl; got != "https://upload.wikimedia.org/wikipedia/commons/7/78/En-us-tomato.ogg" {
+		t.Errorf("OggUrl = %q", got)
+	}
+	if got := *w.Sounds[1].Mp3Url; got != "https://example.org/tomato.mp3" {
+		t.Errorf("existing Mp3Url overwritten: %q", got)
+	}
+	if w.Sounds[2].Mp3Url != nil || w.Sounds[2].OggUrl != nil {
+		t.Error("sound without audio got URLs")
+	}
+	if n := commons.FillWord(w); n != 0 {
+		t.Errorf("second FillWord changed %d sounds", n)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	m := commons.NewManifest()
+	m.Add(&en.WordData{Word: "water", Pos: "noun", Sounds: []en.SoundData{
+		{Audio: ptr("En-uk-water.ogg")},
+		{Audio: ptr("LL-Q1860 (eng)-Vealhurl-water.wav")},
+	}})
+	m.Add(&en.WordData{Word: "water", Pos: "verb", Sounds: []en.SoundData{
+		{Audio: ptr("En-uk-water.ogg")},
+	}})
+	m.Add(&en.WordData{Word: "tomato", Sounds: []en.SoundData{
+		{Audio: ptr("En-us-tomato.ogg"), OggUrl: ptr("https://example.org/tomato.ogg")},
+	}})
+
+	entries := m.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].File != "En-uk-water.ogg" || len(entries[0].Words) != 1 {
+		t.Errorf("duplicate audio not merged: %+v", entries[0])
+	}
+	if entries[1].OggUrl != "https://example.org/tomato.ogg" {
+		t.Errorf("explicit OggUrl not kept: %+v", entries[1])
+	}
+
+	var sb strings.Builder
+	if err := m.WriteURLs(&sb, commons.MP3); err != nil {
+		t.Fatal(err)
+	}
+	want := "https://upload.wikimedia.org/wikipedia/commons/transcoded/1/1a/En-uk-water.ogg/En-uk-water.ogg.mp3\n" +
+		"https://upload.wikimedia.org/wikipedia/commons/transcoded/7/78/En-us-tomato.ogg/En-us-tomato.ogg.mp3\n" +
+		"https://upload.wikimedia.org/wikipedia/commons/transcoded/f/f9/LL-Q1860_(eng)-Vealhurl-water.wav/LL-Q1860_(eng)-Vealhurl-water.wav.mp3\n"
+	if got := sb.String(); got != want {
+		t.Errorf("WriteURLs:\n%s\nwant\n%s", got, want)
+	}
+
+	sb.Reset()
+	if err := m.WriteTSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[1], "\twater") {
+		t.Errorf("WriteTSV:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,109 @@
+package commons
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// ManifestEntry is an audio file to download, with the words whose
+// pronunciation it records.
+type ManifestEntry struct {
+	File   string
+	URL    string
+	Mp3Url string
+	OggUrl string
+	Words  []string
+}
+
+// Manifest collects the distinct audio files of a corpus.
+type Manifest struct {
+	entries map[string]*ManifestEntry
+}
+
+// NewManifest returns an empty manifest.
+func NewManifest() *Manifest {
+	return &Manifest{entries: make(map[string]*ManifestEntry)}
+}
+
+// Add records the audio files of `w`. URLs already present in the data
+// take precedence over computed ones.
+func (m *Manifest) Add(w *en.WordData) {
+	for _, s := range w.Sounds {
+		if s.Audio == nil || strings.TrimSpace(*s.Audio) == "" {
+			continue
+		}
+		Fill(&s) // s is a copy
+		file := FileName(*s.Audio)
+		e, ok := m.entries[file]
+		if !ok {
+			e = &ManifestEntry{File: file, URL: URL(file), Mp3Url: *s.Mp3Url, OggUrl: *s.OggUrl}
+			m.entries[file] = e
+		}
+		if !slices.Contains(e.Words, w.Word) {
+			e.Words = append(e.Words, w.Word)
+		}
+	}
+}
+
+// Entries returns the collected files, sorted by name.
+func (m *Manifest) Entries() []ManifestEntry {
+	entries := make([]ManifestEntry, 0, len(m.entries))
+	for _, e := range m.entries {
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b ManifestEntry) int {
+		return strings.Compare(a.File, b.File)
+	})
+	return entries
+}
+
+// Format selects which URL of a file a manifest lists.
+type Format int
+
+const (
+	Original Format = iota
+	MP3
+	OGG
+)
+
+func (e ManifestEntry) url(f Format) string {
+	switch f {
+	case MP3:
+		return e.Mp3Url
+	case OGG:
+		return e.OggUrl
+	}
+	return e.URL
+}
+
+// WriteURLs writes one URL per line, suitable for `wget -i` or
+// `aria2c -i`.
+func (m *Manifest) WriteURLs(w io.Writer, f Format) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range m.Entries() {
+		if _, err := bw.WriteString(e.url(f) + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteTSV writes a `file<TAB>url<TAB>mp3_url<TAB>ogg_url<TAB>words`
+// table with a header line. Words are joined with "|".
+func (m *Manifest) WriteTSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("file\turl\tmp3_url\togg_url\twords\n"); err != nil {
+		return err
+	}
+	for _, e := range m.Entries() {
+		line := strings.Join([]string{e.File, e.URL, e.Mp3Url, e.OggUrl, strings.Join(e.Words, "|")}, "\t")
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
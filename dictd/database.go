@@ -0,0 +1,100 @@
+package dictd
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// Database is an in-memory dictionary served by a [Server].
+type Database struct {
+	// name used in DEFINE and MATCH commands, e.g. "wiktionary-en"
+	Name string
+	// one-line description shown by SHOW DB
+	Description string
+	// text returned by SHOW INFO
+	Info string
+
+	mu        sync.Mutex
+	headwords []string // sorted with compareHeadwords when sorted is set
+	sorted    bool
+	defs      map[string][]string // lower-cased headword -> definitions
+}
+
+// NewDatabase returns an empty database.
+func NewDatabase(name, description string) *Database {
+	return &Database{Name: name, Description: description, defs: make(map[string][]string)}
+}
+
+// Add indexes the definition of `word` under its headword, inflected
+// forms and redirects, as [Writer] does.
+func (db *Database) Add(word *en.WordData) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	def := Definition(word)
+	for _, h := range headwords(word) {
+		key := strings.ToLower(h)
+		if _, ok := db.defs[key]; !ok {
+			db.headwords = append(db.headwords, h)
+			db.sorted = false
+		}
+		db.defs[key] = append(db.defs[key], def)
+	}
+}
+
+// Define returns the definitions of `word`, compared case-insensitively.
+func (db *Database) Define(word string) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.defs[strings.ToLower(word)]
+}
+
+// Strategy is a MATCH strategy.
+type Strategy struct {
+	Name        string
+	Description string
+	match       func(headword, word string) bool
+}
+
+// Strategies lists the supported MATCH strategies; the first one is
+// used for the "." default strategy.
+var Strategies = []Strategy{
+	{"prefix", "Match prefixes", strings.HasPrefix},
+	{"exact", "Match headwords exactly", func(h, w string) bool { return h == w }},
+	{"substring", "Match substring occurring anywhere in a headword", strings.Contains},
+}
+
+func strategy(name string) (Strategy, bool) {
+	if name == "." {
+		return Strategies[0], true
+	}
+	for _, s := range Strategies {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return Strategy{}, false
+}
+
+// Match returns the headwords matching `word` with strategy `s`, in
+// index order. Comparisons are case-insensitive.
+func (db *Database) Match(s Strategy, word string) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	// headwords are sorted once after they are added rather than on
+	// each insertion
+	if !db.sorted {
+		slices.SortFunc(db.headwords, compareHeadwords)
+		db.sorted = true
+	}
+	word = strings.ToLower(word)
+	var found []string
+	for _, h := range db.headwords {
+		if s.match(strings.ToLower(h), word) {
+			found = append(found, h)
+		}
+	}
+	return found
+}
@@ -0,0 +1,91 @@
+// Package dictd exports [en.WordData] as a dictd database (.index and
+// .dict or .dict.dz files) and serves it over the DICT protocol
+// (RFC 2229).
+package dictd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dictd encodes offsets and lengths in base 64, most significant digit
+// first and without padding.
+const b64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// EncodeNumber encodes `n` the way dictd .index files do.
+func EncodeNumber(n uint64) string {
+	if n == 0 {
+		return "A"
+	}
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = b64[n&63]
+		n >>= 6
+	}
+	return string(buf[i:])
+}
+
+// DecodeNumber decodes a number encoded by [EncodeNumber].
+func DecodeNumber(s string) (uint64, error) {
+	if s == "" || len(s) > 11 {
+		return 0, fmt.Errorf("dictd: invalid number %q", s)
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(b64, s[i])
+		if d < 0 {
+			return 0, fmt.Errorf("dictd: invalid number %q", s)
+		}
+		n = n<<6 | uint64(d)
+	}
+	return n, nil
+}
+
+// IndexEntry is a line of a .index file.
+type IndexEntry struct {
+	Headword string
+	Offset   uint64
+	Length   uint64
+}
+
+// ReadIndex parses a .index file.
+func ReadIndex(r io.Reader) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("dictd: index line %d: expected 3 fields", line)
+		}
+		offset, err := DecodeNumber(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		length, err := DecodeNumber(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, IndexEntry{Headword: fields[0], Offset: offset, Length: length})
+	}
+	return entries, scanner.Err()
+}
+
+// compareHeadwords orders the index the way dictd expects for a
+// database declaring 00-database-allchars and 00-database-utf8: case
+// insensitively, then bytewise.
+func compareHeadwords(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// errLineBreak is returned for headwords that cannot be written to an
+// index line.
+var errLineBreak = errors.New("dictd: headword contains a tab or line break")
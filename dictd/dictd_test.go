@@ -0,0 +1,326 @@
+package dictd_test
+
+import (
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dictd"
+	"github.com/FreeDictionary/wiktionary-schema-go/dictzip"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+var words = []*en.WordData{
+	{
+		Word: "water", Pos: "noun", Lang: "English", LangCode: "en",
+		Forms:  []en.FormData{{Form: "waters", Tags: []string{"plural"}}},
+		Senses: []en.SenseData{{Glosses: []string{"A clear liquid."}}},
+	},
+	{
+		Word: "water", Pos: "verb", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"To pour water on."}}},
+	},
+	{
+		Word: "watermelon", Pos: "noun", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"A large fruit."}}},
+	},
+	{
+		Word: "Zealand", Pos: "name", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"An island.", "The largest island of Denmark."}}},
+	},
+	{
+		Word: "saltwater", Pos: "adj", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"Of the sea."}}},
+	},
+}
+
+func TestNumbers(t *testing.T) {
+	tests := map[uint64]string{0: "A", 1: "B", 63: "/", 64: "BA", 4096: "BAA", 1<<32 - 1: "D/////"}
+	for n, s := range tests {
+		if got := dictd.EncodeNumber(n); got != s {
+			t.Errorf("EncodeNumber(%d) = %q, want %q", n, got, s)
+		}
+		if got, err := dictd.DecodeNumber(s); err != nil || got != n {
+			t.Errorf("DecodeNumber(%q) = %d, %v", s, got, err)
+		}
+	}
+	if _, err := dictd.DecodeNumber("a=b"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		w, err := dictd.Create(dir, "wiktionary-en", dictd.Options{Short: "English Wiktionary", URL: "https://kaikki.org", Compress: compress})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, word := range words {
+			if err := w.Add(word); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(filepath.Join(dir, "wiktionary-en.index"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		index, err := dictd.ReadIndex(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var headwords []string
+		for _, e := range index {
+			headwords = append(headwords, e.Headword)
+		}
+		want := []string{
+			"00-database-allchars", "00-database-short", "00-database-url", "00-database-utf8",
+			"saltwater", "water", "water", "watermelon", "waters", "Zealand",
+		}
+		if !slices.Equal(headwords, want) {
+			t.Errorf("headwords = %q, want %q", headwords, want)
+		}
+
+		var data io.ReaderAt
+		if compress {
+			f, err := os.Open(filepath.Join(dir, "wiktionary-en.dict.dz"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if data, err = dictzip.NewReader(f); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			f, err := os.Open(filepath.Join(dir, "wiktionary-en.dict"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			data = f
+		}
+		read := func(e dictd.IndexEntry) string {
+			buf := make([]byte, e.Length)
+			if n, err := data.ReadAt(buf, int64(e.Offset)); n != len(buf) {
+				t.Fatalf("reading %q: %v", e.Headword, err)
+			}
+			return string(buf)
+		}
+		if got := read(index[1]); got != "00-database-short\n  English Wiktionary\n" {
+			t.Errorf("short = %q", got)
+		}
+		if got, want := read(index[8]), "water\n  noun\n  Forms: waters (plural)\n  1. A clear liquid.\n"; got != want {
+			t.Errorf("waters = %q, want %q", got, want)
+		}
+		if got, want := read(index[9]), "Zealand\n  proper noun\n  1. An island.\n    1.1. The largest island of Denmark.\n"; got != want {
+			t.Errorf("Zealand = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestBadHeadwords(t *testing.T) {
+	dir := t.TempDir()
+	w, err := dictd.Create(dir, "bad", dictd.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := &en.WordData{Word: "tab\there", Pos: "noun", Senses: []en.SenseData{{Glosses: []string{"Not indexable."}}}}
+	if err := w.Add(bad); err == nil {
+		t.Error("headword with a tab added")
+	}
+	// forms that cannot be indexed are dropped
+	good := &en.WordData{
+		Word: "good", Pos: "adj",
+		Forms:  []en.FormData{{Form: "bet\nter", Tags: []string{"comparative"}}, {Form: "best", Tags: []string{"superlative"}}},
+		Senses: []en.SenseData{{Glosses: []string{"Not bad."}}},
+	}
+	if err := w.Add(good); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "bad.index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := dictd.ReadIndex(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "bad.dict"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var headwords []string
+	for _, e := range index {
+		if strings.HasPrefix(e.Headword, "00-database-") {
+			continue
+		}
+		headwords = append(headwords, e.Headword)
+		if got, want := string(data[e.Offset:e.Offset+e.Length]), dictd.Definition(good)+"\n"; got != want {
+			t.Errorf("%s = %q, want %q", e.Headword, got, want)
+		}
+	}
+	if !slices.Equal(headwords, []string{"best", "good"}) {
+		t.Errorf("headwords = %q", headwords)
+	}
+}
+
+// client is a scripted DICT client.
+type client struct {
+	t *testing.T
+	*textproto.Conn
+}
+
+func (c *client) cmd(line string, code int) string {
+	c.t.Helper()
+	if err := c.PrintfLine("%s", line); err != nil {
+		c.t.Fatal(err)
+	}
+	return c.expect(code)
+}
+
+func (c *client) expect(code int) string {
+	c.t.Helper()
+	_, msg, err := c.ReadCodeLine(code)
+	if err != nil {
+		c.t.Fatalf("expected %d: %v", code, err)
+	}
+	return msg
+}
+
+func (c *client) text() string {
+	c.t.Helper()
+	lines, err := c.ReadDotLines()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestServer(t *testing.T) {
+	db := dictd.NewDatabase("wiktionary-en", "English Wiktionary")
+	db.Info = "Extracted by wiktextract.\n.hidden line"
+	for _, w := range words {
+		db.Add(w)
+	}
+	srv := &dictd.Server{Databases: []*dictd.Database{db}, Hostname: "test.local"}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- srv.Serve(l) }()
+
+	conn, err := textproto.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &client{t, conn}
+	if banner := c.expect(220); !strings.HasPrefix(banner, "test.local ") || !strings.Contains(banner, "@test.local>") {
+		t.Errorf("banner = %q", banner)
+	}
+
+	c.cmd("CLIENT go test", 250)
+
+	c.cmd("SHOW DB", 110)
+	if got := c.text(); got != `wiktionary-en "English Wiktionary"` {
+		t.Errorf("SHOW DB = %q", got)
+	}
+	c.expect(250)
+
+	c.cmd("SHOW STRAT", 111)
+	if got := c.text(); !strings.Contains(got, "exact ") || !strings.Contains(got, "substring ") {
+		t.Errorf("SHOW STRAT = %q", got)
+	}
+	c.expect(250)
+
+	c.cmd("SHOW INFO wiktionary-en", 112)
+	if got := c.text(); got != db.Info {
+		t.Errorf("SHOW INFO = %q", got)
+	}
+	c.expect(250)
+
+	if got := c.cmd("DEFINE wiktionary-en WATER", 150); got != "2 definitions retrieved" {
+		t.Errorf("DEFINE = %q", got)
+	}
+	for _, gloss := range []string{"A clear liquid.", "To pour water on."} {
+		if got := c.expect(151); got != `"WATER" wiktionary-en "English Wiktionary"` {
+			t.Errorf("151 = %q", got)
+		}
+		if got := c.text(); !strings.Contains(got, gloss) {
+			t.Errorf("definition %q lacks %q", got, gloss)
+		}
+	}
+	c.expect(250)
+
+	c.cmd(`DEFINE * "zealand"`, 150)
+	c.expect(151)
+	c.text()
+	c.expect(250)
+
+	// inflected forms are indexed as in the files of Writer
+	c.cmd("DEFINE wiktionary-en waters", 150)
+	c.expect(151)
+	if got := c.text(); !strings.Contains(got, "A clear liquid.") {
+		t.Errorf("definition of waters = %q", got)
+	}
+	c.expect(250)
+
+	c.cmd("DEFINE wiktionary-en nothing", 552)
+	c.cmd("DEFINE nope water", 550)
+
+	for _, tt := range []struct{ strat, word, want string }{
+		{"exact", "water", `wiktionary-en "water"`},
+		{"prefix", "WATER", "wiktionary-en \"water\"\nwiktionary-en \"watermelon\"\nwiktionary-en \"waters\""},
+		{"substring", "water", "wiktionary-en \"saltwater\"\nwiktionary-en \"water\"\nwiktionary-en \"watermelon\"\nwiktionary-en \"waters\""},
+		{".", "zea", `wiktionary-en "Zealand"`},
+	} {
+		c.cmd("MATCH ! "+tt.strat+" "+tt.word, 152)
+		if got := c.text(); got != tt.want {
+			t.Errorf("MATCH %s %s = %q, want %q", tt.strat, tt.word, got, tt.want)
+		}
+		c.expect(250)
+	}
+	c.cmd("MATCH * soundex water", 551)
+	c.cmd("MATCH * exact lava", 552)
+
+	c.cmd("OPTION MIME", 250)
+	c.cmd("DEFINE wiktionary-en watermelon", 150)
+	c.expect(151)
+	if got := c.text(); !strings.HasPrefix(got, "\nwatermelon\n") {
+		t.Errorf("MIME definition = %q", got)
+	}
+	c.expect(250)
+
+	c.cmd("STATUS", 210)
+	c.cmd("HELP", 113)
+	c.text()
+	c.expect(250)
+	c.cmd("FROB", 500)
+	c.cmd("DEFINE water", 501)
+	c.cmd(`DEFINE * "water`, 501)
+	c.cmd("QUIT", 221)
+	if _, err := c.ReadLine(); err != io.EOF {
+		t.Errorf("connection not closed after QUIT: %v", err)
+	}
+	conn.Close()
+
+	srv.Close()
+	if err := <-done; err != dictd.ErrServerClosed {
+		t.Errorf("Serve returned %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package dictd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Server answers DICT protocol (RFC 2229) requests from its databases.
+// It supports DEFINE, MATCH, SHOW DB, SHOW STRAT, SHOW INFO,
+// SHOW SERVER, CLIENT, STATUS, OPTION MIME, HELP and QUIT.
+type Server struct {
+	Databases []*Database
+	// host name announced in the banner; defaults to os.Hostname
+	Hostname string
+	// closes connections idle for longer than this; zero means never
+	IdleTimeout time.Duration
+
+	mu        sync.Mutex
+	listeners map[net.Listener]bool
+	conns     map[net.Conn]bool
+	closed    bool
+	nextID    atomic.Uint64
+}
+
+// ErrServerClosed is returned by [Server.Serve] after [Server.Close].
+var ErrServerClosed = errors.New("dictd: server closed")
+
+// ListenAndServe listens on the TCP address `addr` (the standard DICT
+// port is 2628) and serves connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on `l` until it fails or the server is
+// closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]bool)
+		s.conns = make(map[net.Conn]bool)
+	}
+	s.listeners[l] = true
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		go func() {
+			s.ServeConn(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops the listeners and closes open connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	return nil
+}
+
+// session is the state of one client connection.
+type session struct {
+	s    *Server
+	conn *textproto.Conn
+	mime bool
+}
+
+// ServeConn runs a DICT session on `conn` and closes it when the client
+// quits or the connection fails.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	ss := &session{s: s, conn: textproto.NewConn(conn)}
+
+	host := s.Hostname
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	msgID := fmt.Sprintf("<%d.%d@%s>", os.Getpid(), s.nextID.Add(1), host)
+	if ss.reply(220, "%s wiktionary-schema-go dictd <mime> %s", host, msgID) != nil {
+		return
+	}
+	for {
+		if s.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		line, err := ss.conn.ReadLine()
+		if err != nil {
+			return
+		}
+		args, err := splitArgs(line)
+		if err != nil {
+			ss.reply(501, "syntax error, illegal parameters")
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		quit, err := ss.handle(args)
+		if err != nil || quit {
+			return
+		}
+	}
+}
+
+func (ss *session) reply(code int, format string, args ...any) error {
+	return ss.conn.PrintfLine("%d "+format, append([]any{code}, args...)...)
+}
+
+// text sends a dot-terminated text block.
+func (ss *session) text(body string) error {
+	w := ss.conn.DotWriter()
+	if ss.mime {
+		w.Write([]byte("\n"))
+	}
+	if _, err := w.Write([]byte(strings.TrimSuffix(body, "\n") + "\n")); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (ss *session) handle(args []string) (quit bool, err error) {
+	cmd := strings.ToUpper(args[0])
+	switch {
+	case cmd == "DEFINE" && len(args) == 3:
+		return false, ss.define(args[1], args[2])
+	case cmd == "MATCH" && len(args) == 4:
+		return false, ss.match(args[1], args[2], args[3])
+	case cmd == "SHOW" && len(args) >= 2:
+		return false, ss.show(strings.ToUpper(args[1]), args[2:])
+	case cmd == "CLIENT" && len(args) >= 2:
+		return false, ss.reply(250, "ok")
+	case cmd == "STATUS" && len(args) == 1:
+		return false, ss.reply(210, "status [d/m/c = %d/%d/%d]", len(ss.s.Databases), len(Strategies), 0)
+	case cmd == "OPTION" && len(args) == 2 && strings.EqualFold(args[1], "MIME"):
+		ss.mime = true
+		return false, ss.reply(250, "ok - using MIME headers")
+	case cmd == "HELP" && len(args) == 1:
+		if err := ss.reply(113, "help text follows"); err != nil {
+			return false, err
+		}
+		if err := ss.text(helpText); err != nil {
+			return false, err
+		}
+		return false, ss.reply(250, "ok")
+	case cmd == "QUIT":
+		return true, ss.reply(221, "bye")
+	case cmd == "DEFINE" || cmd == "MATCH" || cmd == "SHOW" || cmd == "CLIENT" ||
+		cmd == "STATUS" || cmd == "OPTION" || cmd == "HELP":
+		return false, ss.reply(501, "syntax error, illegal parameters")
+	}
+	return false, ss.reply(500, "syntax error, command not recognized")
+}
+
+const helpText = `DEFINE database word         -- look up word in database
+MATCH database strategy word -- match word in database using strategy
+SHOW DB                      -- list all accessible databases
+SHOW STRAT                   -- list available matching strategies
+SHOW INFO database           -- provide information about the database
+SHOW SERVER                  -- provide site-specific information
+OPTION MIME                  -- use MIME headers
+CLIENT info                  -- identify client to server
+STATUS                       -- display timing information
+HELP                         -- display this help information
+QUIT                         -- terminate connection`
+
+// databases resolves a database name: "*" means all of them and "!"
+// the first one with results, which the caller handles by stopping at
+// the first hit.
+func (ss *session) databases(name string) ([]*Database, bool) {
+	if name == "*" || name == "!" {
+		return ss.s.Databases, true
+	}
+	for _, db := range ss.s.Databases {
+		if db.Name == name {
+			return []*Database{db}, true
+		}
+	}
+	return nil, false
+}
+
+func (ss *session) define(dbName, word string) error {
+	dbs, ok := ss.databases(dbName)
+	if !ok {
+		return ss.reply(550, `invalid database, use "SHOW DB" for list of databases`)
+	}
+	type def struct {
+		db   *Database
+		text string
+	}
+	var defs []def
+	for _, db := range dbs {
+		for _, text := range db.Define(word) {
+			defs = append(defs, def{db, text})
+		}
+		if dbName == "!" && len(defs) > 0 {
+			break
+		}
+	}
+	if len(defs) == 0 {
+		return ss.reply(552, "no match")
+	}
+	if err := ss.reply(150, "%d definitions retrieved", len(defs)); err != nil {
+		return err
+	}
+	for _, d := range defs {
+		if err := ss.reply(151, "%s %s %s", quote(word), d.db.Name, quote(d.db.Description)); err != nil {
+			return err
+		}
+		if err := ss.text(d.text); err != nil {
+			return err
+		}
+	}
+	return ss.reply(250, "ok")
+}
+
+func (ss *session) match(dbName, strat, word string) error {
+	dbs, ok := ss.databases(dbName)
+	if !ok {
+		return ss.reply(550, `invalid database, use "SHOW DB" for list of databases`)
+	}
+	st, ok := strategy(strat)
+	if !ok {
+		return ss.reply(551, `invalid strategy, use "SHOW STRAT" for a list of strategies`)
+	}
+	var lines []string
+	for _, db := range dbs {
+		for _, h := range db.Match(st, word) {
+			lines = append(lines, db.Name+" "+quote(h))
+		}
+		if dbName == "!" && len(lines) > 0 {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ss.reply(552, "no match")
+	}
+	if err := ss.reply(152, "%d matches found", len(lines)); err != nil {
+		return err
+	}
+	if err := ss.text(strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return ss.reply(250, "ok")
+}
+
+func (ss *session) show(what string, args []string) error {
+	var code int
+	var header, body string
+	switch {
+	case (what == "DB" || what == "DATABASES") && len(args) == 0:
+		if len(ss.s.Databases) == 0 {
+			return ss.reply(554, "no databases present")
+		}
+		var lines []string
+		for _, db := range ss.s.Databases {
+			lines = append(lines, db.Name+" "+quote(db.Description))
+		}
+		code, header, body = 110, fmt.Sprintf("%d databases present", len(lines)), strings.Join(lines, "\n")
+	case (what == "STRAT" || what == "STRATEGIES") && len(args) == 0:
+		var lines []string
+		for _, st := range Strategies {
+			lines = append(lines, st.Name+" "+quote(st.Description))
+		}
+		code, header, body = 111, fmt.Sprintf("%d strategies available", len(lines)), strings.Join(lines, "\n")
+	case what == "INFO" && len(args) == 1:
+		dbs, ok := ss.databases(args[0])
+		if !ok || len(dbs) != 1 {
+			return ss.reply(550, `invalid database, use "SHOW DB" for list of databases`)
+		}
+		info := dbs[0].Info
+		if info == "" {
+			info = dbs[0].Description
+		}
+		code, header, body = 112, "database information follows", info
+	case what == "SERVER" && len(args) == 0:
+		code, header, body = 114, "server information follows", "wiktionary-schema-go dictd, serving Wiktionary data"
+	default:
+		return ss.reply(501, "syntax error, illegal parameters")
+	}
+	if err := ss.reply(code, "%s", header); err != nil {
+		return err
+	}
+	if err := ss.text(body); err != nil {
+		return err
+	}
+	return ss.reply(250, "ok")
+}
+
+// quote quotes a word for a status line.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// splitArgs splits a command line into words; single or double quotes
+// group words and a backslash escapes the next character.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inWord := false
+	var q rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inWord = true, true
+		case q != 0 && r == q:
+			q = 0
+		case q != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			q, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if q != 0 || escaped {
+		return nil, errors.New("dictd: unterminated quote")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
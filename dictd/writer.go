@@ -0,0 +1,197 @@
+package dictd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dictzip"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Options configures a [Writer].
+type Options struct {
+	// one-line description (00-database-short)
+	Short string
+	// longer description returned by SHOW INFO (00-database-info)
+	Info string
+	// where the data comes from (00-database-url)
+	URL string
+	// compress the definitions into a .dict.dz file
+	Compress bool
+	// do not index Forms and Redirects as additional headwords
+	NoForms bool
+}
+
+// Writer builds a dictd database. Definitions are written to the .dict
+// file as they are added and the sorted .index by [Writer.Close].
+type Writer struct {
+	dir, name string
+	opts      Options
+
+	dict    *os.File
+	buf     *bufio.Writer
+	offset  uint64
+	entries []IndexEntry
+}
+
+// Create starts a database named `name` (the file names without
+// extension) in `dir`.
+func Create(dir, name string, opts Options) (*Writer, error) {
+	f, err := os.Create(filepath.Join(dir, name+".dict"))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Short == "" {
+		opts.Short = name
+	}
+	w := &Writer{dir: dir, name: name, opts: opts, dict: f, buf: bufio.NewWriter(f)}
+
+	// the 00-database-* entries hold the metadata dictd reads
+	meta := [][2]string{{"00-database-short", opts.Short}}
+	if opts.Info != "" {
+		meta = append(meta, [2]string{"00-database-info", opts.Info})
+	}
+	if opts.URL != "" {
+		meta = append(meta, [2]string{"00-database-url", opts.URL})
+	}
+	meta = append(meta, [2]string{"00-database-utf8", ""}, [2]string{"00-database-allchars", ""})
+	for _, m := range meta {
+		if err := w.write([]string{m[0]}, m[0]+"\n"+indent(m[1])); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Add writes the definition of `word`: its headword followed by the
+// indented plain text article. Every [en.WordData] is a separate
+// definition, and its forms and redirects point to it too.
+func (w *Writer) Add(word *en.WordData) error {
+	if w.opts.NoForms {
+		return w.write([]string{word.Word}, Definition(word))
+	}
+	return w.write(headwords(word), Definition(word))
+}
+
+// headwords returns the headword of `word`, then its shown inflected
+// forms and its redirects. Forms and redirects that cannot be written to
+// an index line are dropped.
+func headwords(word *en.WordData) []string {
+	hs := []string{word.Word}
+	for _, f := range word.Forms {
+		if article.ShowForm(f) && indexable(f.Form) && !slices.Contains(hs, f.Form) {
+			hs = append(hs, f.Form)
+		}
+	}
+	for _, r := range word.Redirects {
+		if r != "" && indexable(r) && !slices.Contains(hs, r) {
+			hs = append(hs, r)
+		}
+	}
+	return hs
+}
+
+// indexable reports whether `h` can be written to an index line.
+func indexable(h string) bool {
+	return !strings.ContainsAny(h, "\t\n\r")
+}
+
+// Definition renders the definition text of `word` as stored in the
+// .dict file.
+func Definition(word *en.WordData) string {
+	return word.Word + "\n" + indent(article.Plain(article.New(word)))
+}
+
+func (w *Writer) write(headwords []string, text string) error {
+	// checked first: a definition without all its index entries would
+	// shift the offsets of the next ones
+	for _, h := range headwords {
+		if !indexable(h) {
+			return errLineBreak
+		}
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if _, err := w.buf.WriteString(text); err != nil {
+		return err
+	}
+	for _, h := range headwords {
+		w.entries = append(w.entries, IndexEntry{Headword: h, Offset: w.offset, Length: uint64(len(text))})
+	}
+	w.offset += uint64(len(text))
+	return nil
+}
+
+// indent prefixes every non-empty line with two spaces.
+func indent(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "  " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Close writes the sorted .index file and compresses the definitions if
+// requested.
+func (w *Writer) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		w.dict.Close()
+		return err
+	}
+	if err := w.dict.Close(); err != nil {
+		return err
+	}
+	base := filepath.Join(w.dir, w.name)
+
+	slices.SortStableFunc(w.entries, func(a, b IndexEntry) int {
+		return compareHeadwords(a.Headword, b.Headword)
+	})
+	f, err := os.Create(base + ".index")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	for _, e := range w.entries {
+		bw.WriteString(e.Headword + "\t" + EncodeNumber(e.Offset) + "\t" + EncodeNumber(e.Length) + "\n")
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if !w.opts.Compress {
+		return nil
+	}
+	in, err := os.Open(base + ".dict")
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(base + ".dict.dz")
+	if err != nil {
+		return err
+	}
+	if err := dictzip.Compress(out, in, int64(w.offset)); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(base + ".dict")
+}
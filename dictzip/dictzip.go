@@ -0,0 +1,249 @@
+// Package dictzip reads and writes dictzip files (.dict.dz), the gzip
+// variant used by dictd and StarDict. The data is compressed in chunks
+// that are listed in a "RA" gzip extra field, so that any range of the
+// uncompressed data can be read without inflating the whole file.
+package dictzip
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+// CHUNK_LENGTH is the uncompressed size of every chunk but the last
+// one, the same as the reference dictzip implementation.
+const CHUNK_LENGTH int = 58315
+
+// MAX_CHUNKS is the number of chunks whose sizes fit in the 64 KiB gzip
+// extra field, which limits dictzip files to about 1.9 GB of data.
+const MAX_CHUNKS int = (0xffff - 10) / 2
+
+const (
+	flagHcrc    = 1 << 1
+	flagExtra   = 1 << 2
+	flagName    = 1 << 3
+	flagComment = 1 << 4
+)
+
+// Compress writes the dictzip compression of the `size` bytes read from
+// `r` to `w`.
+func Compress(w io.Writer, r io.Reader, size int64) error {
+	count := int((size + int64(CHUNK_LENGTH) - 1) / int64(CHUNK_LENGTH))
+	if count == 0 {
+		count = 1
+	}
+	if count > MAX_CHUNKS {
+		return fmt.Errorf("dictzip: %d bytes exceed the maximum of %d chunks", size, MAX_CHUNKS)
+	}
+
+	// compress every chunk before writing, since the header lists the
+	// compressed chunk sizes
+	var data bytes.Buffer
+	sizes := make([]uint16, count)
+	crc := crc32.NewIEEE()
+	chunk := make([]byte, CHUNK_LENGTH)
+	var read int64
+	for i := range count {
+		n, err := io.ReadFull(r, chunk[:min(int64(CHUNK_LENGTH), size-read)])
+		if err != nil {
+			return fmt.Errorf("dictzip: reading chunk %d: %w", i, err)
+		}
+		read += int64(n)
+		crc.Write(chunk[:n])
+
+		start := data.Len()
+		// a new writer per chunk, so that no chunk refers to the data
+		// of the previous ones
+		fw, err := flate.NewWriter(&data, flate.BestCompression)
+		if err != nil {
+			return err
+		}
+		fw.Write(chunk[:n])
+		if i == count-1 {
+			err = fw.Close()
+		} else {
+			err = fw.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		sizes[i] = uint16(data.Len() - start)
+	}
+
+	header := []byte{0x1f, 0x8b, 8, flagExtra, 0, 0, 0, 0, 2, 3}
+	ra := 6 + 2*count
+	header = binary.LittleEndian.AppendUint16(header, uint16(4+ra))
+	header = append(header, 'R', 'A')
+	header = binary.LittleEndian.AppendUint16(header, uint16(ra))
+	header = binary.LittleEndian.AppendUint16(header, 1) // version
+	header = binary.LittleEndian.AppendUint16(header, uint16(CHUNK_LENGTH))
+	header = binary.LittleEndian.AppendUint16(header, uint16(count))
+	for _, s := range sizes {
+		header = binary.LittleEndian.AppendUint16(header, s)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := data.WriteTo(w); err != nil {
+		return err
+	}
+	trailer := binary.LittleEndian.AppendUint32(nil, crc.Sum32())
+	trailer = binary.LittleEndian.AppendUint32(trailer, uint32(size))
+	_, err := w.Write(trailer)
+	return err
+}
+
+// Reader gives random access to the uncompressed content of a dictzip
+// file. It is safe for concurrent use.
+type Reader struct {
+	r        io.ReaderAt
+	chunkLen int
+	offsets  []int64 // start of each compressed chunk, plus the end
+	size     int64
+
+	mu     sync.Mutex
+	cached int
+	chunk  []byte
+}
+
+// ErrFormat is returned for gzip files without a dictzip "RA" field.
+var ErrFormat = errors.New("dictzip: not a dictzip file")
+
+// NewReader parses the header of the dictzip file read from `r`.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	head := make([]byte, 12)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, err
+	}
+	if head[0] != 0x1f || head[1] != 0x8b || head[2] != 8 || head[3]&flagExtra == 0 {
+		return nil, ErrFormat
+	}
+	flags := head[3]
+	xlen := int(binary.LittleEndian.Uint16(head[10:]))
+	extra := make([]byte, xlen)
+	if _, err := r.ReadAt(extra, 12); err != nil {
+		return nil, err
+	}
+	pos := int64(12 + xlen)
+
+	z := &Reader{r: r, cached: -1}
+	var sizes []byte
+	for len(extra) >= 4 {
+		n := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+n {
+			break
+		}
+		if extra[0] == 'R' && extra[1] == 'A' && n >= 6 {
+			field := extra[4 : 4+n]
+			z.chunkLen = int(binary.LittleEndian.Uint16(field[2:]))
+			count := int(binary.LittleEndian.Uint16(field[4:]))
+			sizes = field[6:]
+			if binary.LittleEndian.Uint16(field) != 1 || len(sizes) < 2*count || z.chunkLen == 0 {
+				return nil, ErrFormat
+			}
+			sizes = sizes[:2*count]
+		}
+		extra = extra[4+n:]
+	}
+	if sizes == nil {
+		return nil, ErrFormat
+	}
+
+	// skip the optional zero-terminated name and comment
+	for _, flag := range []byte{flagName, flagComment} {
+		if flags&flag == 0 {
+			continue
+		}
+		b := make([]byte, 1)
+		for {
+			if _, err := r.ReadAt(b, pos); err != nil {
+				return nil, err
+			}
+			pos++
+			if b[0] == 0 {
+				break
+			}
+		}
+	}
+	if flags&flagHcrc != 0 {
+		pos += 2
+	}
+
+	z.offsets = make([]int64, 0, len(sizes)/2+1)
+	z.offsets = append(z.offsets, pos)
+	for i := 0; i < len(sizes); i += 2 {
+		pos += int64(binary.LittleEndian.Uint16(sizes[i:]))
+		z.offsets = append(z.offsets, pos)
+	}
+
+	last := len(z.offsets) - 2
+	if last >= 0 {
+		chunk, err := z.inflate(last)
+		if err != nil {
+			return nil, err
+		}
+		z.size = int64(last)*int64(z.chunkLen) + int64(len(chunk))
+	}
+	return z, nil
+}
+
+// Size returns the size of the uncompressed data.
+func (z *Reader) Size() int64 {
+	return z.size
+}
+
+// inflate decompresses chunk i; the caller must hold z.mu or be the
+// constructor.
+func (z *Reader) inflate(i int) ([]byte, error) {
+	if i == z.cached {
+		return z.chunk, nil
+	}
+	compressed := make([]byte, z.offsets[i+1]-z.offsets[i])
+	if _, err := z.r.ReadAt(compressed, z.offsets[i]); err != nil {
+		return nil, err
+	}
+	fr := flate.NewReader(bytes.NewReader(compressed))
+	defer fr.Close()
+	chunk := make([]byte, z.chunkLen)
+	n, err := io.ReadFull(fr, chunk)
+	// chunks other than the last end with a sync flush, which flate
+	// reports as an unexpected EOF once the data has been read
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("dictzip: chunk %d: %w", i, err)
+	}
+	z.cached, z.chunk = i, chunk[:n]
+	return z.chunk, nil
+}
+
+// ReadAt reads uncompressed data starting at `off`.
+func (z *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("dictzip: negative offset")
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if off >= z.size {
+			return n, io.EOF
+		}
+		i := int(off / int64(z.chunkLen))
+		chunk, err := z.inflate(i)
+		if err != nil {
+			return n, err
+		}
+		m := copy(p[n:], chunk[off-int64(i)*int64(z.chunkLen):])
+		if m == 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += m
+		off += int64(m)
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package dictzip_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dictzip"
+)
+
+// sample returns `n` bytes of compressible text.
+func sample(n int) []byte {
+	var buf bytes.Buffer
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; buf.Len() < n; i++ {
+		fmt.Fprintf(&buf, "entry %d: %x\n", i, rng.Uint32())
+	}
+	return buf.Bytes()[:n]
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 10, dictzip.CHUNK_LENGTH, 3*dictzip.CHUNK_LENGTH + 17} {
+		data := sample(size)
+		var dz bytes.Buffer
+		if err := dictzip.Compress(&dz, bytes.NewReader(data), int64(size)); err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		// a dictzip file is a valid gzip file
+		gz, err := gzip.NewReader(bytes.NewReader(dz.Bytes()))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		all, err := io.ReadAll(gz)
+		if err != nil || !bytes.Equal(all, data) {
+			t.Fatalf("size %d: gzip decompression mismatch (err %v)", size, err)
+		}
+
+		r, err := dictzip.NewReader(bytes.NewReader(dz.Bytes()))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if r.Size() != int64(size) {
+			t.Errorf("Size() = %d, want %d", r.Size(), size)
+		}
+		all, err = io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+		if err != nil || !bytes.Equal(all, data) {
+			t.Fatalf("size %d: ReadAt mismatch (err %v)", size, err)
+		}
+
+		// ranges across chunk boundaries
+		rng := rand.New(rand.NewPCG(3, 4))
+		for range 50 {
+			if size == 0 {
+				break
+			}
+			off := rng.IntN(size)
+			n := rng.IntN(2 * dictzip.CHUNK_LENGTH)
+			p := make([]byte, n)
+			m, err := r.ReadAt(p, int64(off))
+			want := data[off:min(off+n, size)]
+			if m != len(want) || !bytes.Equal(p[:m], want) {
+				t.Fatalf("ReadAt(%d, %d) = %d, %v", n, off, m, err)
+			}
+			if off+n > size && err != io.EOF {
+				t.Fatalf("ReadAt past the end: err = %v, want EOF", err)
+			}
+		}
+	}
+}
+
+func TestNotDictzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("plain gzip"))
+	gz.Close()
+	if _, err := dictzip.NewReader(bytes.NewReader(buf.Bytes())); err != dictzip.ErrFormat {
+		t.Errorf("got %v, want ErrFormat", err)
+	}
+}
@@ -1,7 +1,21 @@
-package main
-
-// # wiktionary-schema-go
-// Go structures to describe Wiktionary data.
+// Package wiktionary describes Wiktionary data with Go structures.
 //
-// For best compatibility, use this package with
-// `GOEXPERIMENT=jsonv2`.
+// Schemas of different editions are stored in packages named after
+// their language code, e.g. [github.com/FreeDictionary/wiktionary-schema-go/en].
+// The wiktionary command in cmd/wiktionary filters, converts and
+// inspects wiktextract JSONL dumps.
+//
+// The packages need only encoding/json, and build without
+// `GOEXPERIMENT=jsonv2`. When encoding/json/v2 is built too, the
+// structures encode to the same bytes with both versions, given the same
+// escaping: encoding/json escapes <, >, & and the line separators, which
+// encoding/json/v2 does with the jsontext.EscapeForHTML and
+// jsontext.EscapeForJS options. Optional fields are omitted when nil, or
+// when empty for lists, template arguments are sorted by name, and nil
+// lists within lists are written as []. What one version encodes decodes
+// to the same values with both. encoding/json/v2 is stricter with input
+// that wiktextract does not write, such as offsets that are not pairs,
+// duplicate keys or keys in another case; pass it the
+// json.DefaultOptionsV1 of encoding/json to decode such input as
+// encoding/json does.
+package wiktionary
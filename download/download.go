@@ -0,0 +1,283 @@
+// Package download fetches wiktextract dumps, which are several
+// gigabytes, so that an interrupted transfer resumes where it stopped and
+// a file that did not change is not fetched again.
+//
+// [File] writes to name+".part" and renames it to the file name only when
+// the transfer is complete and its checksum, if given, matches. The
+// validators the server sent, ETag and Last-Modified, are kept in
+// name+".meta": they make the next request for a partial file a range
+// request, which the server answers with the whole file if it changed
+// meanwhile, and the next request for a complete file a conditional one.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksum is returned when a downloaded file does not have the
+// expected checksum. The file is deleted.
+var ErrChecksum = errors.New("download: checksum mismatch")
+
+// Options configure a download. The zero value is valid.
+type Options struct {
+	// client making the requests, http.DefaultClient if nil
+	Client *http.Client
+	// called as the file is written with the bytes written so far,
+	// including those resumed, and the size of the file, -1 if unknown
+	Progress func(done, total int64)
+	// expected SHA-256 of the file in hex, not checked if empty
+	SHA256 string
+}
+
+// Result describes what [File] did.
+type Result struct {
+	// false if the file was up to date
+	Downloaded bool
+	// bytes of an interrupted download that were not fetched again
+	Resumed int64
+	// size of the file
+	Size int64
+}
+
+// meta is kept in name+".meta" for the next download.
+type meta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// File downloads `url` to the file `name`, resuming a partial download
+// of the same URL and skipping the transfer if the file is up to date.
+// After an error, calling it again resumes the download.
+func File(ctx context.Context, url, name string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	part, metaName := name+".part", name+".meta"
+
+	m, err := readMeta(metaName)
+	if err != nil || m.URL != url {
+		m = meta{URL: url}
+		os.Remove(part)
+	}
+	var offset int64
+	if fi, err := os.Stat(part); err == nil {
+		offset = fi.Size()
+	}
+	_, statErr := os.Stat(name)
+	exists := statErr == nil
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// a strong validator is needed for If-Range
+	validator := m.LastModified
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		validator = m.ETag
+	}
+	switch {
+	case offset > 0 && validator != "":
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	case offset == 0 && exists:
+		if m.ETag != "" {
+			req.Header.Set("If-None-Match", m.ETag)
+		}
+		if m.LastModified != "" {
+			req.Header.Set("If-Modified-Since", m.LastModified)
+		}
+	default:
+		offset = 0
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Size: fi.Size()}, nil
+	case http.StatusPartialContent:
+		if start, ok := rangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return nil, fmt.Errorf("%s: unexpected range %q", url, resp.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is complete if File stopped before renaming
+		// it; otherwise it is not a prefix of the file anymore and the
+		// next call starts over
+		if size, ok := rangeSize(resp.Header.Get("Content-Range")); ok && offset > 0 && size == offset {
+			h, err := hashPrefix(opts.SHA256, part, offset)
+			if err != nil {
+				return nil, err
+			}
+			if err := finish(url, part, name, metaName, h, opts.SHA256); err != nil {
+				return nil, err
+			}
+			return &Result{Downloaded: true, Resumed: offset, Size: offset}, nil
+		}
+		os.Remove(part)
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	default:
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	m.ETag, m.LastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if err := writeMeta(metaName, m); err != nil {
+		return nil, err
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	h, err := hashPrefix(opts.SHA256, part, offset)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w := &progressWriter{f: f, h: h, done: offset, total: total, progress: opts.Progress}
+	_, err = io.Copy(w, resp.Body)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && total >= 0 && w.done != total {
+		err = fmt.Errorf("got %d of %d bytes", w.done, total)
+	}
+	if err != nil {
+		// the partial file is kept to resume
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+
+	if err := finish(url, part, name, metaName, h, opts.SHA256); err != nil {
+		return nil, err
+	}
+	return &Result{Downloaded: true, Resumed: offset, Size: w.done}, nil
+}
+
+// finish renames the complete partial file to `name` if `h`, nil if no
+// checksum is expected, has the sum `sum`. Otherwise it deletes it.
+func finish(url, part, name, metaName string, h hash.Hash, sum string) error {
+	if h != nil && !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), sum) {
+		os.Remove(part)
+		os.Remove(metaName)
+		return fmt.Errorf("%s: %w", url, ErrChecksum)
+	}
+	return os.Rename(part, name)
+}
+
+// rangeStart returns the first byte of a "bytes first-last/size"
+// Content-Range.
+func rangeStart(cr string) (int64, bool) {
+	first, _, ok := strings.Cut(strings.TrimPrefix(cr, "bytes "), "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(first, 10, 64)
+	return n, err == nil
+}
+
+// rangeSize returns the size of the file of a "bytes */size"
+// Content-Range.
+func rangeSize(cr string) (int64, bool) {
+	_, size, ok := strings.Cut(cr, "/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	return n, err == nil
+}
+
+// hashPrefix returns a SHA-256 hash of the first `n` bytes of the file
+// `name`, or nil if `sum`, the expected checksum, is empty.
+func hashPrefix(sum, name string, n int64) (hash.Hash, error) {
+	if sum == "" {
+		return nil, nil
+	}
+	h := sha256.New()
+	if n == 0 {
+		return h, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.CopyN(h, f, n); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func readMeta(name string) (meta, error) {
+	var m meta
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func writeMeta(name string, m meta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+// progressWriter writes to the partial file and the hash, and reports
+// the progress.
+type progressWriter struct {
+	f        *os.File
+	h        hash.Hash
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	if w.h != nil {
+		w.h.Write(p[:n])
+	}
+	w.done += int64(n)
+	if w.progress != nil {
+		w.progress(w.done, w.total)
+	}
+	return n, err
+}
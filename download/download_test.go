@@ -0,0 +1,346 @@
+package download_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/download"
+)
+
+// server serves a file with ETag and Last-Modified validators. It can
+// break the connection after sending part of the file, as a flaky
+// network does.
+type server struct {
+	mu    sync.Mutex
+	data  []byte
+	etag  string
+	cut   int
+	reqs  []*http.Request
+	codes []int
+}
+
+func newServer(data []byte) *server {
+	s := &server{}
+	s.set(data)
+	return s
+}
+
+func (s *server) set(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	sum := sha256.Sum256(data)
+	s.etag = `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// interrupt breaks the next response after `n` bytes of the body.
+func (s *server) interrupt(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cut = n
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, etag, cut := s.data, s.etag, s.cut
+	s.cut = 0
+	s.reqs = append(s.reqs, r)
+	s.mu.Unlock()
+
+	rec := &statusRecorder{ResponseWriter: w, cut: cut}
+	w.Header().Set("ETag", etag)
+	http.ServeContent(rec, r, "dump.jsonl.gz", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), bytes.NewReader(data))
+	s.mu.Lock()
+	s.codes = append(s.codes, rec.code)
+	s.mu.Unlock()
+}
+
+// last returns the last request and the status of its response.
+func (s *server) last() (*http.Request, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reqs[len(s.reqs)-1], s.codes[len(s.codes)-1]
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code    int
+	written int
+	cut     int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	if r.cut > 0 && r.written+len(p) > r.cut {
+		r.ResponseWriter.Write(p[:r.cut-r.written])
+		r.ResponseWriter.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	r.written += len(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func dump(size int, seed byte) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i*7) + seed
+	}
+	return data
+}
+
+func checkFile(t *testing.T, name string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: %d bytes differ from the %d served", name, len(got), len(want))
+	}
+	if _, err := os.Stat(name + ".part"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial file left: %v", err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	data := dump(100_000, 0)
+	s := newServer(data)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	name := filepath.Join(t.TempDir(), "dump.jsonl.gz")
+	ctx := context.Background()
+
+	s.interrupt(30_000)
+	if _, err := download.File(ctx, ts.URL, name, nil); err == nil {
+		t.Fatal("interrupted download succeeded")
+	}
+	fi, err := os.Stat(name + ".part")
+	if err != nil || fi.Size() != 30_000 {
+		t.Fatalf("partial file: %v, %v", fi, err)
+	}
+
+	var done, total int64
+	opts := &download.Options{Progress: func(d, t int64) { done, total = d, t }}
+	res, err := download.File(ctx, ts.URL, name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, code := s.last(); code != http.StatusPartialContent || r.Header.Get("Range") != "bytes=30000-" {
+		t.Errorf("resumed with %d for range %q", code, r.Header.Get("Range"))
+	}
+	if *res != (download.Result{Downloaded: true, Resumed: 30_000, Size: 100_000}) {
+		t.Errorf("result = %+v", *res)
+	}
+	if done != 100_000 || total != 100_000 {
+		t.Errorf("progress = %d of %d", done, total)
+	}
+	checkFile(t, name, data)
+
+	// the complete file is not fetched again
+	res, err = download.File(ctx, ts.URL, name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, code := s.last(); code != http.StatusNotModified || res.Downloaded || res.Size != 100_000 {
+		t.Errorf("second download: %d, %+v", code, *res)
+	}
+
+	// until it changes
+	changed := dump(50_000, 1)
+	s.set(changed)
+	res, err = download.File(ctx, ts.URL, name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Downloaded || res.Resumed != 0 {
+		t.Errorf("changed file: %+v", *res)
+	}
+	checkFile(t, name, changed)
+}
+
+func TestResumeChanged(t *testing.T) {
+	s := newServer(dump(100_000, 0))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	name := filepath.Join(t.TempDir(), "dump.jsonl.gz")
+	ctx := context.Background()
+
+	s.interrupt(40_000)
+	if _, err := download.File(ctx, ts.URL, name, nil); err == nil {
+		t.Fatal("interrupted download succeeded")
+	}
+	// the partial file is not a prefix of the new file, which is sent
+	// whole
+	changed := dump(80_000, 3)
+	s.set(changed)
+	res, err := download.File(ctx, ts.URL, name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, code := s.last(); code != http.StatusOK || r.Header.Get("If-Range") == "" {
+		t.Errorf("got %d for If-Range %q", code, r.Header.Get("If-Range"))
+	}
+	if res.Resumed != 0 || res.Size != 80_000 {
+		t.Errorf("result = %+v", *res)
+	}
+	checkFile(t, name, changed)
+}
+
+func TestChecksum(t *testing.T) {
+	data := dump(10_000, 0)
+	s := newServer(data)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	sum := sha256.Sum256(data)
+	name := filepath.Join(dir, "good")
+	s.interrupt(5_000)
+	download.File(ctx, ts.URL, name, nil)
+	if _, err := download.File(ctx, ts.URL, name, &download.Options{SHA256: hex.EncodeToString(sum[:])}); err != nil {
+		t.Fatalf("resumed download with checksum: %v", err)
+	}
+	checkFile(t, name, data)
+
+	name = filepath.Join(dir, "bad")
+	sum[0] ^= 1
+	_, err := download.File(ctx, ts.URL, name, &download.Options{SHA256: hex.EncodeToString(sum[:])})
+	if !errors.Is(err, download.ErrChecksum) {
+		t.Errorf("err = %v", err)
+	}
+	for _, n := range []string{name, name + ".part"} {
+		if _, err := os.Stat(n); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left: %v", n, err)
+		}
+	}
+}
+
+func TestCompletePart(t *testing.T) {
+	data := dump(10_000, 0)
+	s := newServer(data)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	name := filepath.Join(t.TempDir(), "dump")
+	ctx := context.Background()
+	sum := sha256.Sum256(data)
+	opts := &download.Options{SHA256: hex.EncodeToString(sum[:])}
+
+	// a crash after the partial file is complete but before it is
+	// renamed
+	crash := func(data []byte) {
+		if _, err := download.File(ctx, ts.URL, name, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name+".part", data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	crash(data)
+	res, err := download.File(ctx, ts.URL, name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, code := s.last(); code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("status %d", code)
+	}
+	if *res != (download.Result{Downloaded: true, Resumed: 10_000, Size: 10_000}) {
+		t.Errorf("result = %+v", *res)
+	}
+	checkFile(t, name, data)
+
+	corrupt := bytes.Clone(data)
+	corrupt[5_000] ^= 1
+	crash(corrupt)
+	if _, err := download.File(ctx, ts.URL, name, opts); !errors.Is(err, download.ErrChecksum) {
+		t.Errorf("corrupt partial file: %v", err)
+	}
+	if _, err := os.Stat(name + ".part"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("corrupt partial file left: %v", err)
+	}
+}
+
+// roundTripFunc is an [http.RoundTripper] answering requests itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestShortBody(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader("short")),
+			ContentLength: 100,
+			Request:       r,
+		}, nil
+	})}
+	name := filepath.Join(t.TempDir(), "dump")
+	if _, err := download.File(context.Background(), "http://example.com/dump", name, &download.Options{Client: client}); err == nil {
+		t.Error("short body succeeded")
+	}
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file created: %v", err)
+	}
+	if fi, err := os.Stat(name + ".part"); err != nil || fi.Size() != 5 {
+		t.Errorf("partial file: %v, %v", fi, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	name := filepath.Join(t.TempDir(), "dump")
+	if _, err := download.File(context.Background(), ts.URL, name, nil); err == nil {
+		t.Error("404 succeeded")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := download.File(ctx, ts.URL, name, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: %v", err)
+	}
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file created: %v", err)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{download.RawURL("en"), "https://kaikki.org/dictionary/raw-wiktextract-data.jsonl.gz"},
+		{download.RawURL("fr"), "https://kaikki.org/frwiktionary/raw-wiktextract-data.jsonl.gz"},
+		{download.LanguageURL("en", "English"), "https://kaikki.org/dictionary/English/kaikki.org-dictionary-English.jsonl"},
+		{download.LanguageURL("en", "Old English"), "https://kaikki.org/dictionary/Old%20English/kaikki.org-dictionary-OldEnglish.jsonl"},
+		{download.LanguageURL("fr", "Français"), "https://kaikki.org/frwiktionary/Fran%C3%A7ais/kaikki.org-dictionary-Fran%C3%A7ais.jsonl"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %s, want %s", tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package download
+
+import (
+	"net/url"
+	"strings"
+)
+
+// KAIKKI_BASE is the root of the dumps published by kaikki.org.
+const KAIKKI_BASE string = "https://kaikki.org/"
+
+// editionDir returns the directory of the dumps extracted from the
+// Wiktionary edition `edition`, a language code: "dictionary/" for the
+// English edition and e.g. "frwiktionary/" for the others.
+func editionDir(edition string) string {
+	if edition == "" || edition == "en" {
+		return "dictionary/"
+	}
+	return edition + "wiktionary/"
+}
+
+// RawURL returns the URL of the complete dump of a Wiktionary edition,
+// every entry of every language as extracted, gzip-compressed, e.g.
+// https://kaikki.org/dictionary/raw-wiktextract-data.jsonl.gz for "en".
+func RawURL(edition string) string {
+	return KAIKKI_BASE + editionDir(edition) + "raw-wiktextract-data.jsonl.gz"
+}
+
+// LanguageURL returns the URL of the dump of the entries of a language,
+// named as in the edition, e.g.
+// https://kaikki.org/dictionary/Old%20English/kaikki.org-dictionary-OldEnglish.jsonl
+// for "en" and "Old English".
+func LanguageURL(edition, lang string) string {
+	return KAIKKI_BASE + editionDir(edition) + url.PathEscape(lang) +
+		"/kaikki.org-dictionary-" + url.PathEscape(strings.ReplaceAll(lang, " ", "")) + ".jsonl"
+}
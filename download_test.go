@@ -1,50 +1,57 @@
-package main_test
+package wiktionary_test
 
 import (
 	"compress/gzip"
-	"fmt"
+	"context"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/download"
 )
 
 const (
-	RAW_WIKTEXTRACT_DATA_GZ string = "https://kaikki.org/dictionary/raw-wiktextract-data.jsonl.gz"
-	SAVE_PATH               string = "./test_data/raw-wiktextract-data.jsonl.gz"
-	RAW_DATA_PATH           string = "./test_data/raw-wiktextract-data.jsonl"
+	SAVE_PATH     string = "./test_data/raw-wiktextract-data.jsonl.gz"
+	RAW_DATA_PATH string = "./test_data/raw-wiktextract-data.jsonl"
 )
 
-var FORCE_DOWNLOAD bool = false
+var (
+	// DOWNLOAD enables the download, which needs the network; the tests
+	// of the schemas run on the fixtures of their packages without it.
+	DOWNLOAD       bool = false
+	FORCE_DOWNLOAD bool = false
+)
 
 func init() {
-	// Read `FORCE_DOWNLOAD` from environment variable
+	// Read `DOWNLOAD` and `FORCE_DOWNLOAD` from environment variables
 	FORCE_DOWNLOAD = (strings.ToLower(os.Getenv("FORCE_DOWNLOAD")) == "true")
-	log.Println("FORCE_DOWNLOAD:", FORCE_DOWNLOAD)
+	DOWNLOAD = FORCE_DOWNLOAD || (strings.ToLower(os.Getenv("DOWNLOAD")) == "true")
+	log.Println("DOWNLOAD:", DOWNLOAD, "FORCE_DOWNLOAD:", FORCE_DOWNLOAD)
 }
 
-// Download `RAW_WIKTIONARY_DATA` to `SAVE_PATH`
-func downloadRawWiktionaryData() error {
-	resp, err := http.Get(RAW_WIKTEXTRACT_DATA_GZ)
+// Download the raw English dump to `SAVE_PATH`, resuming an
+// interrupted download; a complete file is fetched again only if the
+// dump changed.
+func downloadRawWiktionaryData(t *testing.T) error {
+	last := time.Now()
+	res, err := download.File(context.Background(), download.RawURL("en"), SAVE_PATH, &download.Options{
+		Progress: func(done, total int64) {
+			if time.Since(last) > 10*time.Second {
+				last = time.Now()
+				t.Logf("%d of %d MB", done>>20, total>>20)
+			}
+		},
+	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download: %s", resp.Status)
-	}
-
-	out, err := os.Create(SAVE_PATH)
-	if err != nil {
-		return err
+	if res.Resumed > 0 {
+		t.Logf("resumed after %d MB", res.Resumed>>20)
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
+	return nil
 }
 
 // decompress the .gz file to `RAW_DATA_PATH`
@@ -72,6 +79,9 @@ func decompressRawWiktionaryData() error {
 }
 
 func TestDownloadAndDecompressRawWiktionaryData(t *testing.T) {
+	if !DOWNLOAD {
+		t.Skip("set DOWNLOAD=true to download the dump")
+	}
 	err := os.MkdirAll("./test_data", 0755)
 	if err != nil {
 		t.Fatalf("failed to create test_data directory: %v", err)
@@ -84,20 +94,25 @@ func TestDownloadAndDecompressRawWiktionaryData(t *testing.T) {
 		return
 	}
 
-	// Step 2: Ensure the compressed file exists
-	// If it doesn't exist, or FORCE_DOWNLOAD is true, download it
+	// Step 2: Ensure the compressed file exists and is up to date
+	// If FORCE_DOWNLOAD is true, delete it and its validators so that it
+	// is downloaded again; otherwise a partial file is resumed and a
+	// complete one only fetched again if the dump changed
 	if FORCE_DOWNLOAD {
 		t.Logf("FORCE_DOWNLOAD is true, re-downloading...")
-	}
-	if _, err := os.Stat(SAVE_PATH); os.IsNotExist(err) || FORCE_DOWNLOAD {
-		err = downloadRawWiktionaryData()
-		if err != nil {
-			t.Fatalf("failed to download: %v", err)
+		for _, name := range []string{SAVE_PATH, SAVE_PATH + ".meta"} {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				t.Fatalf("failed to remove %s: %v", name, err)
+			}
 		}
-		t.Logf("downloaded %s", SAVE_PATH)
-	} else {
-		t.Logf("file %s already exists, skipping download", SAVE_PATH)
+	} else if _, err := os.Stat(SAVE_PATH); err == nil {
+		t.Logf("file %s already exists, checking for a newer dump...", SAVE_PATH)
+	}
+	err = downloadRawWiktionaryData(t)
+	if err != nil {
+		t.Fatalf("failed to download: %v", err)
 	}
+	t.Logf("%s is up to date", SAVE_PATH)
 
 	// Step 3: Decompress the file (always do this since we need fresh decompressed file)
 	err = decompressRawWiktionaryData()
@@ -0,0 +1,97 @@
+// Package dsl exports [en.WordData] as an ABBYY Lingvo DSL source
+// dictionary, as read by GoldenDict.
+//
+// A DSL file is UTF-16LE text with a byte order mark. It starts with
+// #DIRECTIVE lines, followed by cards: one or more headword lines at
+// the start of a line, then body lines indented by a tab.
+package dsl
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// headwordSpecial are the characters with a meaning in headwords:
+// {unsorted} and (optional) parts, the ~ placeholder, escapes, and @
+// and # at the start of a line.
+const headwordSpecial = `\[]{}()~@#^`
+
+// bodySpecial are the characters with a meaning in card bodies: markup
+// tags, <<references>>, the ~ placeholder and @ subentries.
+const bodySpecial = `\[]{}~<>@`
+
+func escape(s, special string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune(special, r):
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\t' || r == '\n' || r == '\r':
+			// would break the line structure of a card
+			sb.WriteByte(' ')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// EscapeHeadword escapes the DSL metacharacters of a headword and trims
+// its spaces: a line starting with whitespace is a body line.
+func EscapeHeadword(s string) string {
+	return strings.TrimSpace(escape(s, headwordSpecial))
+}
+
+// EscapeText escapes the DSL metacharacters of body text.
+func EscapeText(s string) string {
+	return escape(s, bodySpecial)
+}
+
+// Unescape removes backslash escapes.
+func Unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// utf16Writer encodes text as UTF-16LE.
+type utf16Writer struct {
+	w   *bufio.Writer
+	buf []uint16
+}
+
+func newUTF16Writer(w io.Writer) *utf16Writer {
+	return &utf16Writer{w: bufio.NewWriter(w)}
+}
+
+func (u *utf16Writer) WriteString(s string) (int, error) {
+	u.buf = u.buf[:0]
+	for _, r := range s {
+		u.buf = utf16.AppendRune(u.buf, r)
+	}
+	for _, c := range u.buf {
+		u.w.WriteByte(byte(c))
+		if err := u.w.WriteByte(byte(c >> 8)); err != nil {
+			return 0, err
+		}
+	}
+	return len(s), nil
+}
+
+func (u *utf16Writer) Flush() error {
+	return u.w.Flush()
+}
@@ -0,0 +1,141 @@
+package dsl_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dsl"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// decode checks the byte order mark and decodes UTF-16LE.
+func decode(t *testing.T, b []byte) string {
+	t.Helper()
+	if len(b) < 2 || b[0] != 0xff || b[1] != 0xfe || len(b)%2 != 0 {
+		t.Fatalf("not UTF-16LE with a BOM: % x", b[:min(len(b), 4)])
+	}
+	units := make([]uint16, len(b)/2-1)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2+2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := dsl.NewWriter(&buf, dsl.Options{Name: `My "DSL"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Add(&en.WordData{
+		Word: "C++ [lang]", Pos: "name",
+		Sounds: []en.SoundData{
+			{Ipa: ptr("/siː plʌs plʌs/"), Tags: []string{"General-American"}},
+			{Audio: ptr("en-us-C++.ogg"), Tags: []string{"US"}},
+		},
+		Forms: []en.FormData{{Form: "C++s", Tags: []string{"plural"}}},
+		Senses: []en.SenseData{
+			{
+				Glosses: []string{"A language {see ~C}."},
+				Tags:    []string{"computing"},
+				Examples: []en.ExampleData{
+					{Text: "Use C++ <here>.", BoldTextOffsets: [][2]int{{4, 7}}, Translation: ptr("Utilisez C++ ici.")},
+				},
+			},
+			{Glosses: []string{"A language {see ~C}.", "A standard\nrevision."}, Qualifier: ptr("dated")},
+		},
+		EtymologyText: ptr("From C + @increment."),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(&en.WordData{Word: "𝄞", Pos: "symbol", Senses: []en.SenseData{{Glosses: []string{"G clef"}}}})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`#NAME "My DSL"`,
+		`#INDEX_LANGUAGE "English"`,
+		`#CONTENTS_LANGUAGE "English"`,
+		``,
+		`C++ \[lang\]`,
+		`C++s`,
+		"\t[m0][p]proper noun[/p][/m]",
+		"\t[m0][t]/siː plʌs plʌs/[/t] [p]General American[/p][/m]",
+		"\t[m0][s]En-us-C++.ogg[/s] [p]US[/p][/m]",
+		"\t[m1]1. [p]computing[/p] [trn]A language \\{see \\~C\\}.[/trn][/m]",
+		"\t[m2][*][ex]Use [b]C++[/b] \\<here\\>. — Utilisez C++ ici.[/ex][/*][/m]",
+		"\t[m2]1.1. [i](dated)[/i] [trn]A standard revision.[/trn][/m]",
+		"\t[m1][*][i]Etymology:[/i] From C + \\@increment.[/*][/m]",
+		``,
+		`𝄞`,
+		"\t[m0][p]symbol[/p][/m]",
+		"\t[m1]1. [trn]G clef[/trn][/m]",
+		``,
+		``,
+	}, "\r\n")
+	if got := decode(t, buf.Bytes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, s := range []string{`plain`, `a\b`, `[m1]{x}(y)~@#^<<ref>>`, "tab\there"} {
+		h, b := dsl.EscapeHeadword(s), dsl.EscapeText(s)
+		want := strings.ReplaceAll(s, "\t", " ")
+		if got := dsl.Unescape(h); got != want {
+			t.Errorf("Unescape(EscapeHeadword(%q)) = %q", s, got)
+		}
+		if got := dsl.Unescape(b); got != want {
+			t.Errorf("Unescape(EscapeText(%q)) = %q", s, got)
+		}
+	}
+	if got := dsl.EscapeHeadword("a (b) {c}"); got != `a \(b\) \{c\}` {
+		t.Errorf("EscapeHeadword = %s", got)
+	}
+	if got := dsl.EscapeText("(b) <<c>>"); got != `(b) \<\<c\>\>` {
+		t.Errorf("EscapeText = %s", got)
+	}
+	if got := dsl.EscapeHeadword(" \t(x) "); got != `\(x\)` {
+		t.Errorf("EscapeHeadword = %s", got)
+	}
+}
+
+func TestBlankHeadwords(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := dsl.NewWriter(&buf, dsl.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range []en.WordData{
+		{Word: "", Pos: "noun", Senses: []en.SenseData{{Glosses: []string{"Nothing."}}}},
+		{Word: " \t", Pos: "noun", Senses: []en.SenseData{{Glosses: []string{"Blank."}}}},
+		{Word: "  indented", Pos: "noun", Forms: []en.FormData{{Form: " "}, {Form: "indented "}, {Form: " indents"}}},
+	} {
+		if err := w.Add(&word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`#NAME "Wiktionary"`,
+		`#INDEX_LANGUAGE "English"`,
+		`#CONTENTS_LANGUAGE "English"`,
+		``,
+		`indented`,
+		`indents`,
+		"\t[m0][p]noun[/p][/m]",
+		``,
+		``,
+	}, "\r\n")
+	if got := decode(t, buf.Bytes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package dsl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/commons"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Options configures a [Writer].
+type Options struct {
+	// #NAME, defaults to "Wiktionary"
+	Name string
+	// #INDEX_LANGUAGE and #CONTENTS_LANGUAGE, as Lingvo language
+	// names; both default to "English"
+	IndexLanguage    string
+	ContentsLanguage string
+	// do not add inflected forms as alternative headwords
+	NoForms bool
+	// do not add [s] references to audio files
+	NoAudio bool
+}
+
+// Writer writes a DSL dictionary.
+type Writer struct {
+	w    *utf16Writer
+	opts Options
+}
+
+// NewWriter writes the byte order mark and the directives to `w`.
+func NewWriter(w io.Writer, opts Options) (*Writer, error) {
+	if opts.Name == "" {
+		opts.Name = "Wiktionary"
+	}
+	if opts.IndexLanguage == "" {
+		opts.IndexLanguage = "English"
+	}
+	if opts.ContentsLanguage == "" {
+		opts.ContentsLanguage = "English"
+	}
+	dw := &Writer{w: newUTF16Writer(w), opts: opts}
+	dw.w.WriteString("\ufeff")
+	dw.line(0, `#NAME "`+directive(opts.Name)+`"`)
+	dw.line(0, `#INDEX_LANGUAGE "`+directive(opts.IndexLanguage)+`"`)
+	_, err := dw.w.WriteString(`#CONTENTS_LANGUAGE "` + directive(opts.ContentsLanguage) + `"` + "\r\n\r\n")
+	return dw, err
+}
+
+// directive drops the quotes and line breaks that would end a
+// directive value.
+func directive(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '"' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// line writes a line, indented with a tab for body lines.
+func (w *Writer) line(indent int, s string) {
+	if indent > 0 {
+		s = "\t" + s
+	}
+	w.w.WriteString(s + "\r\n")
+}
+
+// Add writes `word` as a card: the headword and its inflected forms,
+// then the part of speech, transcriptions with their audio files,
+// senses nested with [m1], [m2]… and the etymology. Words whose
+// headword is blank are skipped.
+func (w *Writer) Add(word *en.WordData) error {
+	e := article.New(word)
+	head := EscapeHeadword(e.Word)
+	if head == "" {
+		return nil
+	}
+	w.line(0, head)
+	if !w.opts.NoForms {
+		for _, f := range e.Inflections() {
+			// an empty line would end the card
+			if f := EscapeHeadword(f); f != "" && f != head {
+				w.line(0, f)
+			}
+		}
+	}
+
+	if e.Pos != "" {
+		w.line(1, "[m0][p]"+EscapeText(e.Pos)+"[/p][/m]")
+	}
+	for _, p := range e.Pronunciations {
+		s := "[m0][t]" + EscapeText(p.Text) + "[/t]"
+		if len(p.Accents) > 0 {
+			s += " " + labels(p.Accents)
+		}
+		w.line(1, s+"[/m]")
+	}
+	if !w.opts.NoAudio {
+		for _, s := range word.Sounds {
+			if s.Audio != nil && *s.Audio != "" {
+				line := "[m0][s]" + EscapeText(commons.FileName(*s.Audio)) + "[/s]"
+				if len(s.Tags) > 0 {
+					line += " " + labels(s.Tags)
+				}
+				w.line(1, line+"[/m]")
+			}
+		}
+	}
+	w.senses(e.Senses, "", 1)
+	if e.Etymology != "" {
+		w.line(1, "[m1][*][i]Etymology:[/i] "+EscapeText(e.Etymology)+"[/*][/m]")
+	}
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// senses writes numbered senses at margin `depth`, their examples one
+// level deeper.
+func (w *Writer) senses(senses []*article.Sense, prefix string, depth int) {
+	for i, s := range senses {
+		number := fmt.Sprintf("%s%d.", prefix, i+1)
+		line := fmt.Sprintf("[m%d]%s ", depth, number)
+		if tags := labels(s.Tags); tags != "" {
+			line += tags + " "
+		}
+		if s.Qualifier != "" {
+			line += "[i](" + EscapeText(s.Qualifier) + ")[/i] "
+		}
+		w.line(1, line+"[trn]"+EscapeText(s.Gloss)+"[/trn][/m]")
+		for _, ex := range s.Examples {
+			line := fmt.Sprintf("[m%d][*][ex]%s", depth+1, spans(ex.Text))
+			if ex.Roman != "" {
+				line += " [i]" + EscapeText(ex.Roman) + "[/i]"
+			}
+			if len(ex.Translation) > 0 {
+				line += " — " + spans(ex.Translation)
+			}
+			w.line(1, line+"[/ex][/*][/m]")
+		}
+		w.senses(s.Subsenses, number, depth+1)
+	}
+}
+
+// labels marks tags as [p] abbreviations.
+func labels(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		parts[i] = "[p]" + EscapeText(strings.ReplaceAll(t, "-", " ")) + "[/p]"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func spans(spans []article.Span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		if s.Bold {
+			sb.WriteString("[b]" + EscapeText(s.Text) + "[/b]")
+		} else {
+			sb.WriteString(EscapeText(s.Text))
+		}
+	}
+	return sb.String()
+}
+
+// Flush writes any buffered data.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
@@ -3,17 +3,36 @@ package en_test
 import (
 	"bufio"
 	"bytes"
-	"encoding/json/v2"
+	"compress/gzip"
+	jsonv1 "encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"math/rand/v2"
 	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/FreeDictionary/wiktionary-schema-go/en"
 )
 
-const RAW_DATA_PATH string = "../test_data/raw-wiktextract-data.jsonl"
+const (
+	RAW_DATA_PATH string = "../test_data/raw-wiktextract-data.jsonl"
+	FIXTURES_PATH string = "testdata/fixtures.jsonl"
+)
+
+var (
+	update = flag.Bool("update", false, "rewrite the golden files")
+	sample = flag.String("sample", "", "rewrite the fixtures with entries sampled from this `dump`, plain or gzip-compressed")
+)
 
 func TestCurrentDir(t *testing.T) {
 	// print current directory
@@ -26,6 +45,9 @@ func TestCurrentDir(t *testing.T) {
 
 func TestMarshalUnmarshalEnglish(t *testing.T) {
 	file, err := os.Open(RAW_DATA_PATH)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("%s not found; DOWNLOAD=true go test . downloads it", RAW_DATA_PATH)
+	}
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,7 +73,7 @@ func TestMarshalUnmarshalEnglish(t *testing.T) {
 		}
 
 		var schema map[string]any
-		if err := json.Unmarshal(line, &schema); err != nil {
+		if err := jsonv1.Unmarshal(line, &schema); err != nil {
 			t.Fatal(err)
 		}
 
@@ -63,15 +85,827 @@ func TestMarshalUnmarshalEnglish(t *testing.T) {
 
 		// test marshal
 		var enSchema en.WordData
-		if err := json.Unmarshal(line, &enSchema); err != nil {
+		if err := jsonv1.Unmarshal(line, &enSchema); err != nil {
 			t.Fatal(err)
 		}
 
 		// test unmarshal
-		_, err = json.Marshal(enSchema)
+		_, err = jsonv1.Marshal(enSchema)
 		if err != nil {
 			t.Fatal(err)
 		}
 
 	}
 }
+
+// fixtureKinds are the kinds of entries the fixtures cover.
+var fixtureKinds = []struct {
+	name  string
+	match func(w *en.WordData) bool
+}{
+	{"multiple etymologies", func(w *en.WordData) bool {
+		return w.EtymologyNumber != nil && *w.EtymologyNumber > 1
+	}},
+	{"ruby", func(w *en.WordData) bool {
+		for _, f := range w.Forms {
+			if len(f.Ruby) > 0 {
+				return true
+			}
+		}
+		for _, s := range w.Senses {
+			for _, e := range s.Examples {
+				if len(e.Ruby) > 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}},
+	{"nested descendants", func(w *en.WordData) bool {
+		for _, d := range w.Descendants {
+			if len(d.Descendants) > 0 {
+				return true
+			}
+		}
+		return false
+	}},
+	{"deep senses", func(w *en.WordData) bool {
+		deep := 0
+		for _, s := range w.Senses {
+			if len(s.Examples) > 0 && len(s.Synonyms)+len(s.Hypernyms)+len(s.Related) > 0 {
+				deep++
+			}
+		}
+		return len(w.Senses) >= 3 && deep >= 2
+	}},
+	{"redirects", func(w *en.WordData) bool { return len(w.Redirects) > 0 }},
+	{"form of", func(w *en.WordData) bool {
+		for _, s := range w.Senses {
+			if len(s.FormOf) > 0 {
+				return true
+			}
+		}
+		return false
+	}},
+	{"translations", func(w *en.WordData) bool { return len(w.Translations) > 0 }},
+	{"audio", func(w *en.WordData) bool {
+		for _, s := range w.Sounds {
+			if s.Audio != nil {
+				return true
+			}
+		}
+		return false
+	}},
+}
+
+// readFixtures returns the lines of the fixtures.
+func readFixtures(t *testing.T) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(FIXTURES_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+}
+
+// goldenFile compares `got` with a file of testdata, or rewrites it with
+// -update.
+func goldenFile(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := "testdata/" + name
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n%s", name, got)
+	}
+}
+
+// codec is a version of encoding/json the tests encode and decode with.
+type codec struct {
+	name      string
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+// codecs are encoding/json, and encoding/json/v2 when it is built, which
+// json_v2_test.go adds.
+var codecs = []codec{{"v1", jsonv1.Marshal, jsonv1.Unmarshal}}
+
+// TestFixtures decodes and encodes the fixtures with every codec, and
+// compares the results, which are the same, with the golden file. The
+// fixtures are resampled from a dump with
+//
+//	go test ./en -run TestFixtures -sample dump.jsonl.gz -update
+func TestFixtures(t *testing.T) {
+	if *sample != "" {
+		sampleFixtures(t, *sample)
+	}
+	lines := readFixtures(t)
+	for _, kind := range fixtureKinds {
+		found := false
+		for _, l := range lines {
+			var w en.WordData
+			if jsonv1.Unmarshal(l, &w) == nil && kind.match(&w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no fixture with %s", kind.name)
+		}
+	}
+
+	var outs [][]byte
+	for _, c := range codecs {
+		var out bytes.Buffer
+		for i, l := range lines {
+			var w en.WordData
+			if err := c.unmarshal(l, &w); err != nil {
+				t.Fatalf("%s: fixture %d: %v", c.name, i+1, err)
+			}
+			data, err := c.marshal(&w)
+			if err != nil {
+				t.Fatalf("%s: fixture %d: %v", c.name, i+1, err)
+			}
+
+			// what is encoded decodes to the same word
+			var again en.WordData
+			if err := c.unmarshal(data, &again); err != nil {
+				t.Fatalf("%s: fixture %d: %v", c.name, i+1, err)
+			}
+			if !reflect.DeepEqual(again, w) {
+				t.Errorf("%s: fixture %d does not round-trip:\n%+v\n%+v", c.name, i+1, again, w)
+			}
+			out.Write(data)
+			out.WriteByte('\n')
+		}
+		outs = append(outs, out.Bytes())
+	}
+	for i, out := range outs[1:] {
+		if !bytes.Equal(out, outs[0]) {
+			t.Errorf("%s and %s encode differently:\n%s\n%s", codecs[0].name, codecs[i+1].name, outs[0], out)
+		}
+	}
+	goldenFile(t, "fixtures.golden.jsonl", outs[0])
+}
+
+// sampleFixtures rewrites the fixtures with the first short entries of
+// the dump `name` that cover every kind.
+func sampleFixtures(t *testing.T, name string) {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		if r, err = gzip.NewReader(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const maxLen = 8 << 10
+	covered := make([]bool, len(fixtureKinds))
+	left := len(covered)
+	var out []byte
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 256<<20)
+	for left > 0 && s.Scan() {
+		if len(s.Bytes()) > maxLen {
+			continue
+		}
+		var w en.WordData
+		if jsonv1.Unmarshal(s.Bytes(), &w) != nil {
+			continue
+		}
+		picked := false
+		for i, kind := range fixtureKinds {
+			if !covered[i] && kind.match(&w) {
+				covered[i] = true
+				left--
+				picked = true
+			}
+		}
+		if picked {
+			out = append(append(out, s.Bytes()...), '\n')
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range covered {
+		if !ok {
+			t.Errorf("no entry of %d bytes or less with %s", maxLen, fixtureKinds[i].name)
+		}
+	}
+	if err := os.WriteFile(FIXTURES_PATH, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// corpus returns `n` synthetic JSON lines resembling the English
+// dump: every word has a few senses, forms, sounds and translations
+// whose tags, topics, categories and languages are drawn from large
+// vocabularies, the frequent values more often, as in the dump.
+func corpus(n int) [][]byte {
+	r := rand.New(rand.NewPCG(1, 2))
+	// zipf draws from a vocabulary of `size` values named after `prefix`
+	zipf := func(prefix string, size uint64) func() string {
+		z := rand.NewZipf(r, 1.1, 1, size-1)
+		return func() string { return fmt.Sprintf("%s%d", prefix, z.Uint64()) }
+	}
+	tag, topic, category := zipf("tag", 2_000), zipf("topic", 500), zipf("English terms derived from the category ", 50_000)
+	lang, template := zipf("Language ", 400), zipf("template-", 1_000)
+	draw := func(f func() string, k int) []string {
+		out := make([]string, k)
+		for i := range out {
+			out[i] = f()
+		}
+		return out
+	}
+	lines := make([][]byte, n)
+	for i := range lines {
+		w := en.WordData{
+			Word:          fmt.Sprintf("word%d", i),
+			Lang:          "English",
+			LangCode:      "en",
+			Pos:           []string{"noun", "verb", "adj", "adv", "name"}[r.IntN(5)],
+			Categories:    draw(category, 1+r.IntN(4)),
+			HeadTemplates: []en.TemplateData{{Name: template(), Args: en.TemplateArgs{"1": "s"}}},
+		}
+		for j := range r.IntN(4) {
+			w.Forms = append(w.Forms, en.FormData{Form: fmt.Sprintf("word%d-%d", i, j), Tags: draw(tag, 1+r.IntN(3))})
+		}
+		for range r.IntN(3) {
+			ipa := fmt.Sprintf("/wɜːd%d/", i)
+			w.Sounds = append(w.Sounds, en.SoundData{Ipa: &ipa, Tags: draw(tag, 1+r.IntN(2))})
+		}
+		for j := range 1 + r.IntN(4) {
+			w.Senses = append(w.Senses, en.SenseData{
+				Glosses:    []string{fmt.Sprintf("Meaning %d of word %d.", j, i)},
+				Tags:       draw(tag, r.IntN(4)),
+				Topics:     draw(topic, r.IntN(2)),
+				Categories: draw(category, r.IntN(3)),
+			})
+		}
+		if r.IntN(2) == 0 {
+			args := en.TemplateArgs{}
+			for k := range 1 + r.IntN(6) {
+				args[fmt.Sprint(k+1)] = fmt.Sprintf("word%d-%d", i, k)
+			}
+			w.InflectionTemplates = []en.TemplateData{{Name: template(), Args: args}}
+		}
+		for range r.IntN(2) {
+			l := lang()
+			w.Descendants = append(w.Descendants, en.DescendantData{
+				Lang:        l,
+				LangCode:    strings.ToLower(l[len("Language "):]),
+				Word:        fmt.Sprintf("wort%d", i),
+				Descendants: []en.DescendantData{{Depth: 1, Lang: l, Word: fmt.Sprintf("wört%d", i)}},
+			})
+		}
+		for j := range r.IntN(20) {
+			word := fmt.Sprintf("mot%d", i+j)
+			l := lang()
+			w.Translations = append(w.Translations, en.TranslationData{
+				Lang:     l,
+				LangCode: strings.ToLower(l[len("Language "):]),
+				Word:     &word,
+				Tags:     draw(tag, r.IntN(2)),
+			})
+		}
+		data, err := jsonv1.Marshal(w)
+		if err != nil {
+			panic(err)
+		}
+		lines[i] = data
+	}
+	return lines
+}
+
+func TestInterner(t *testing.T) {
+	lines := []string{
+		`{"word":"water","lang":"English","lang_code":"en","pos":"noun","categories":["en:Liquids"],"head_templates":[{"name":"en-noun","args":{}}],"forms":[{"form":"waters","tags":["plural"]}],"senses":[{"glosses":["A clear liquid."],"tags":["uncountable"]}],"translations":[{"lang":"French","lang_code":"fr","word":"eau","tags":["feminine"]}]}`,
+		`{"word":"wine","lang":"English","lang_code":"en","pos":"noun","categories":["en:Liquids"],"head_templates":[{"name":"en-noun","args":{}}],"forms":[{"form":"wines","tags":["plural"]}],"senses":[{"glosses":["A clear liquid."],"tags":["countable","uncountable"]}],"translations":[{"lang":"French","lang_code":"fr","word":"vin","tags":["masculine"]}]}`,
+	}
+	in := en.NewInterner()
+	var a, b en.WordData
+	for i, w := range []*en.WordData{&a, &b} {
+		if err := in.Unmarshal([]byte(lines[i]), w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var want en.WordData
+	if err := jsonv1.Unmarshal([]byte(lines[0]), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("interning changed the word:\n%+v\nwant\n%+v", a, want)
+	}
+	// English, en, noun, en:Liquids, en-noun, plural, uncountable,
+	// French, fr, feminine, countable and masculine
+	if in.Len() != 12 {
+		t.Errorf("%d strings interned", in.Len())
+	}
+
+	tests := []struct {
+		name string
+		x, y string
+	}{
+		{"lang", a.Lang, b.Lang},
+		{"lang_code", a.LangCode, b.LangCode},
+		{"pos", a.Pos, b.Pos},
+		{"category", a.Categories[0], b.Categories[0]},
+		{"template", a.HeadTemplates[0].Name, b.HeadTemplates[0].Name},
+		{"form tag", a.Forms[0].Tags[0], b.Forms[0].Tags[0]},
+		{"sense tag", a.Senses[0].Tags[0], b.Senses[0].Tags[1]},
+		{"translation lang", a.Translations[0].Lang, b.Translations[0].Lang},
+	}
+	for _, tt := range tests {
+		if tt.x != tt.y || unsafe.StringData(tt.x) != unsafe.StringData(tt.y) {
+			t.Errorf("%s: %q and %q are not shared", tt.name, tt.x, tt.y)
+		}
+	}
+
+}
+
+func TestLazyWordData(t *testing.T) {
+	for _, data := range corpus(50) {
+		var want en.WordData
+		if err := jsonv1.Unmarshal(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range codecs {
+			name := c.name
+			var w en.LazyWordData
+			if err := c.unmarshal(data, &w); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if w.Word != want.Word || !reflect.DeepEqual(w.Senses, want.Senses) {
+				t.Errorf("%s: %s: eager fields differ", name, want.Word)
+			}
+			if w.WordData.Translations != nil || w.WordData.InflectionTemplates != nil {
+				t.Errorf("%s: %s: heavy fields decoded eagerly", name, want.Word)
+			}
+			if ts, err := w.Translations(); err != nil || !reflect.DeepEqual(ts, want.Translations) {
+				t.Errorf("%s: %s: Translations() = %v, %v", name, want.Word, ts, err)
+			}
+
+			// the heavy fields are written back as they were read
+			out, err := jsonv1.Marshal(&w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back en.WordData
+			if err := jsonv1.Unmarshal(out, &back); err != nil {
+				t.Fatal(err)
+			}
+			full, err := w.Full()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*full, want) || !reflect.DeepEqual(back, want) {
+				t.Errorf("%s: %s: full word differs:\n%+v\nwant\n%+v", name, want.Word, *full, want)
+			}
+		}
+	}
+
+	var w en.LazyWordData
+	if err := jsonv1.Unmarshal([]byte(`{"word":"a","translations":{"lang":"French"}}`), &w); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Full(); err == nil || !strings.HasPrefix(err.Error(), "translations: ") {
+		t.Errorf("Full() = %v", err)
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	lines := corpus(1000)
+	for _, bb := range benchmarkDecoders {
+		b.Run(bb.name, func(b *testing.B) {
+			unmarshal := bb.new()
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				var w en.WordData
+				if err := unmarshal(lines[i%len(lines)], &w); err != nil {
+					b.Fatal(err)
+				}
+				i++
+			}
+		})
+	}
+}
+
+var benchmarkDecoders = []struct {
+	name string
+	new  func() func([]byte, *en.WordData) error
+}{
+	{"v1", func() func([]byte, *en.WordData) error {
+		return func(data []byte, w *en.WordData) error { return jsonv1.Unmarshal(data, w) }
+	}},
+	{"interned", func() func([]byte, *en.WordData) error {
+		return en.NewInterner().Unmarshal
+	}},
+}
+
+// BenchmarkLazy compares decoding words to read their glosses eagerly
+// and lazily, and lazily then completely.
+func BenchmarkLazy(b *testing.B) {
+	lines := corpus(1000)
+	glosses := func(senses []en.SenseData) (n int) {
+		for _, s := range senses {
+			n += len(s.Glosses)
+		}
+		return n
+	}
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			var w en.WordData
+			if err := jsonv1.Unmarshal(lines[i%len(lines)], &w); err != nil {
+				b.Fatal(err)
+			}
+			glosses(w.Senses)
+			i++
+		}
+	})
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			var w en.LazyWordData
+			if err := jsonv1.Unmarshal(lines[i%len(lines)], &w); err != nil {
+				b.Fatal(err)
+			}
+			glosses(w.Senses)
+			i++
+		}
+	})
+	b.Run("lazy-full", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			var w en.LazyWordData
+			if err := jsonv1.Unmarshal(lines[i%len(lines)], &w); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := w.Full(); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkLoad decodes 100,000 words and reports the heap they keep
+// live, also scaled to a million words, about the English entries of
+// the dump.
+func BenchmarkLoad(b *testing.B) {
+	lines := corpus(100_000)
+	const scale = 10
+	for _, bb := range benchmarkDecoders {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var live uint64
+			for b.Loop() {
+				before := heapAlloc()
+				unmarshal := bb.new()
+				words := make([]en.WordData, len(lines))
+				for i, l := range lines {
+					if err := unmarshal(l, &words[i]); err != nil {
+						b.Fatal(err)
+					}
+				}
+				live = heapAlloc() - before
+				runtime.KeepAlive(words)
+			}
+			b.ReportMetric(float64(live)/float64(len(lines)), "live-B/word")
+			b.ReportMetric(float64(live*scale)/(1<<20), "live-MB/1M-words")
+		})
+	}
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// schemaTypes are the struct types of the schema, with the name of
+// their TypedDict in the wiktextract reference quoted in doc.go.
+var schemaTypes = []struct {
+	typ  reflect.Type
+	dict string
+}{
+	{reflect.TypeFor[en.AltOf](), "AltOf"},
+	{reflect.TypeFor[en.LinkageData](), "LinkageData"},
+	{reflect.TypeFor[en.ExampleData](), "ExampleData"},
+	{reflect.TypeFor[en.FormOf](), "FormOf"},
+	{reflect.TypeFor[en.ExtraTemplateData](), "PlusObjTemplateData"},
+	{reflect.TypeFor[en.TemplateData](), "TemplateData"},
+	{reflect.TypeFor[en.DescendantData](), "DescendantData"},
+	{reflect.TypeFor[en.FormData](), "FormData"},
+	{reflect.TypeFor[en.Hyphenation](), "Hyphenation"},
+	{reflect.TypeFor[en.SoundData](), "SoundData"},
+	{reflect.TypeFor[en.TranslationData](), "TranslationData"},
+	{reflect.TypeFor[en.EtymologyExample](), "EtymologyExample"},
+	{reflect.TypeFor[en.ReferenceData](), "ReferenceData"},
+	{reflect.TypeFor[en.AttestationData](), "AttestationData"},
+	{reflect.TypeFor[en.SenseData](), "SenseData"},
+	{reflect.TypeFor[en.WordData](), "WordData"},
+}
+
+// unlistedKeys are keys that wiktextract writes although its TypedDicts
+// do not declare them.
+var unlistedKeys = map[string]bool{
+	"DescendantData.depth":  true,
+	"ExampleData.alt":       true,
+	"SoundData.hyphenation": true,
+}
+
+// referenceKeys parses the TypedDicts quoted in doc.go into their keys.
+func referenceKeys(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+	data, err := os.ReadFile("doc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	class := regexp.MustCompile(`^// (?:class (\w+)\(TypedDict|(\w+) = TypedDict\()`)
+	key := regexp.MustCompile(`^//\s+"?([\w-]+)"?:\s`)
+	dicts := map[string]map[string]bool{}
+	var keys map[string]bool
+	for l := range strings.Lines(string(data)) {
+		if m := class.FindStringSubmatch(l); m != nil {
+			keys = map[string]bool{}
+			dicts[m[1]+m[2]] = keys
+		} else if !strings.HasPrefix(l, "//") {
+			keys = nil
+		} else if m := key.FindStringSubmatch(l); m != nil && keys != nil {
+			keys[m[1]] = true
+		}
+	}
+	return dicts
+}
+
+// jsonName returns the JSON name of a field and whether it has the
+// omitempty or omitzero option.
+func jsonName(f reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	opts = "," + opts + ","
+	return name, strings.Contains(opts, ",omitempty,") || strings.Contains(opts, ",omitzero,")
+}
+
+// TestReferenceKeys checks the JSON names of the schema against the keys
+// of the wiktextract TypedDicts, in both directions.
+func TestReferenceKeys(t *testing.T) {
+	dicts := referenceKeys(t)
+	for _, st := range schemaTypes {
+		keys, ok := dicts[st.dict]
+		if !ok {
+			t.Errorf("%s: no TypedDict %s in doc.go", st.typ.Name(), st.dict)
+			continue
+		}
+		names := map[string]bool{}
+		for f := range st.typ.Fields() {
+			name, _ := jsonName(f)
+			names[name] = true
+			if !keys[name] && !unlistedKeys[st.dict+"."+name] {
+				t.Errorf("%s.%s: key %q is not in %s", st.typ.Name(), f.Name, name, st.dict)
+			}
+		}
+		for k := range keys {
+			if !names[k] {
+				t.Errorf("%s: no field for key %q of %s", st.typ.Name(), k, st.dict)
+			}
+		}
+	}
+}
+
+// generator builds random values of the schema types, as wiktextract
+// would write them: optional fields are missing or set to a non-empty
+// value, and the other fields are always set.
+type generator struct {
+	r *rand.Rand
+}
+
+var generatorRunes = []rune("abcxyz -'\"\\/<>&éß日本\U0001F600́\t\n\u2028")
+
+func (g generator) string() string {
+	rs := make([]rune, 1+g.r.IntN(8))
+	for i := range rs {
+		rs[i] = generatorRunes[g.r.IntN(len(generatorRunes))]
+	}
+	return string(rs)
+}
+
+// value returns a random value of type `t`. `required` is false for
+// fields with the omitempty or omitzero option.
+func (g generator) value(t reflect.Type, required bool, depth int) reflect.Value {
+	v := reflect.New(t).Elem()
+	if t == reflect.TypeFor[jsonv1.RawMessage]() {
+		if required || g.r.IntN(2) == 0 {
+			data, _ := jsonv1.Marshal(g.value(reflect.TypeFor[[]string](), true, depth).Interface())
+			v.SetBytes(data)
+		}
+		return v
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if required || g.r.IntN(2) == 0 {
+			v.SetString(g.string())
+		}
+	case reflect.Int:
+		v.SetInt(int64(g.r.IntN(100)))
+	case reflect.Pointer:
+		if g.r.IntN(2) == 0 {
+			v.Set(g.value(t.Elem(), g.r.IntN(4) != 0, depth).Addr())
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			v.Index(i).Set(g.value(t.Elem(), true, depth))
+		}
+	case reflect.Slice:
+		n := 1 + g.r.IntN(3)
+		if depth > 3 || !required && g.r.IntN(2) == 0 {
+			if !required {
+				return v
+			}
+			n = 0
+		}
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := range n {
+			v.Index(i).Set(g.value(t.Elem(), true, depth+1))
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		for range g.r.IntN(3) {
+			v.SetMapIndex(g.value(t.Key(), true, depth), g.value(t.Elem(), true, depth))
+		}
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			_, omitempty := jsonName(f)
+			v.Field(i).Set(g.value(f.Type, !omitempty, depth+1))
+		}
+	default:
+		panic("generator: unsupported type " + t.String())
+	}
+	return v
+}
+
+// hasNull reports whether decoded JSON has a null.
+func hasNull(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case []any:
+		return slices.ContainsFunc(v, hasNull)
+	case map[string]any:
+		for _, e := range v {
+			if hasNull(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestRoundTrip encodes random values of every schema type, checks that
+// the JSON has no null, which wiktextract never writes, and that it
+// decodes to the same value.
+func TestRoundTrip(t *testing.T) {
+	g := generator{rand.New(rand.NewPCG(3, 4))}
+	for _, st := range schemaTypes {
+		for range 200 {
+			v := g.value(st.typ, true, 0)
+			data, err := jsonv1.Marshal(v.Interface())
+			if err != nil {
+				t.Fatalf("%s: %v", st.typ.Name(), err)
+			}
+			var tree any
+			if err := jsonv1.Unmarshal(data, &tree); err != nil {
+				t.Fatal(err)
+			}
+			if hasNull(tree) {
+				t.Errorf("%s: null in %s", st.typ.Name(), data)
+				break
+			}
+			got := reflect.New(st.typ)
+			if err := jsonv1.Unmarshal(data, got.Interface()); err != nil {
+				t.Fatalf("%s: %v", st.typ.Name(), err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), v.Interface()) {
+				t.Errorf("%s: %s decodes to\n%+v\nwant\n%+v", st.typ.Name(), data, got.Elem(), v)
+				break
+			}
+		}
+	}
+}
+
+var empty = ""
+
+// marshalTests are values encoding/json and encoding/json/v2 would encode
+// differently without the methods of the schema types or omitzero.
+var marshalTests = []struct {
+	v    any
+	want string
+}{
+	{en.TemplateData{Name: "m"}, `{"args":{},"expansion":"","name":"m"}`},
+	{en.TemplateData{Args: en.TemplateArgs{"2": "b", "10": "c", "1": "a", "lang": "<en>"}}, `{"args":{"1":"a","10":"c","2":"b","lang":"\u003cen\u003e"},"expansion":"","name":""}`},
+	{en.LinkageData{Roman: &empty, Tags: []string{}, Ruby: en.RubyData{nil, {"日本", "にほん"}}}, `{"translation":"","roman":"","ruby":[[],["日本","にほん"]],"word":""}`},
+	{en.SenseData{Links: []en.LinkData{nil, {"bank", "bank#English"}}}, `{"head_nr":0,"links":[[],["bank","bank#English"]]}`},
+	{en.ExampleData{Text: "a & b\u2028", BoldTextOffsets: [][2]int{}, BoldRomanOffsets: [][2]int{{0, 0}}, Type: &empty}, `{"bold_roman_offsets":[[0,0]],"text":"a \u0026 b\u2028","type":""}`},
+	{en.LazyWordData{RawTranslations: jsonv1.RawMessage(`[ {"word": "<b>"} ]`), RawDescendants: jsonv1.RawMessage(`[]`)}, `{"lang":"","lang_code":"","literal_meaning":"","original_title":"","pos":"","word":"","translations":[{"word":"\u003cb\u003e"}],"descendants":[]}`},
+}
+
+func TestMarshal(t *testing.T) {
+	for _, c := range codecs {
+		for _, tt := range marshalTests {
+			got, err := c.marshal(tt.v)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("%s: %+v encodes to %s, %v, want %s", c.name, tt.v, got, err, tt.want)
+			}
+		}
+	}
+}
+
+// fuzzRoundTrip checks that any input decoding as a T decodes with
+// allocations bounded by its size, that what it encodes to encodes the
+// same once decoded, and that the other codecs encode it the same.
+func fuzzRoundTrip[T any](f *testing.F) {
+	g := generator{rand.New(rand.NewPCG(5, 6))}
+	// shallow seeds: the fuzzer spends its time minimizing large ones
+	for range 5 {
+		data, err := jsonv1.Marshal(g.value(reflect.TypeFor[T](), true, 3).Interface())
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	for _, s := range []string{`null`, `{}`, `[]`, `{"tags":null}`, `{"senses":[{"examples":[{"bold_text_offsets":[[1,2,3]]}]}]}`} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		before := m.TotalAlloc
+		var v T
+		if jsonv1.Unmarshal(data, &v) != nil {
+			return
+		}
+		runtime.ReadMemStats(&m)
+		if n := m.TotalAlloc - before; n > 64*uint64(len(data))+64<<10 {
+			t.Errorf("%d bytes allocated to decode %d", n, len(data))
+		}
+		out, err := jsonv1.Marshal(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again T
+		if err := jsonv1.Unmarshal(out, &again); err != nil {
+			t.Fatalf("%s: %v", out, err)
+		}
+		if out2, _ := jsonv1.Marshal(&again); !bytes.Equal(out, out2) {
+			t.Errorf("encoded differently once decoded:\n%s\n%s", out, out2)
+		}
+		for _, c := range codecs[1:] {
+			if out2, err := c.marshal(&v); err != nil || !bytes.Equal(out, out2) {
+				t.Errorf("%s encodes differently:\n%s\n%s %v", c.name, out, out2, err)
+			}
+		}
+	})
+}
+
+func FuzzAltOf(f *testing.F)             { fuzzRoundTrip[en.AltOf](f) }
+func FuzzLinkageData(f *testing.F)       { fuzzRoundTrip[en.LinkageData](f) }
+func FuzzExampleData(f *testing.F)       { fuzzRoundTrip[en.ExampleData](f) }
+func FuzzFormOf(f *testing.F)            { fuzzRoundTrip[en.FormOf](f) }
+func FuzzLinkData(f *testing.F)          { fuzzRoundTrip[en.LinkData](f) }
+func FuzzExtraTemplateData(f *testing.F) { fuzzRoundTrip[en.ExtraTemplateData](f) }
+func FuzzTemplateArgs(f *testing.F)      { fuzzRoundTrip[en.TemplateArgs](f) }
+func FuzzTemplateData(f *testing.F)      { fuzzRoundTrip[en.TemplateData](f) }
+func FuzzDescendantData(f *testing.F)    { fuzzRoundTrip[en.DescendantData](f) }
+func FuzzFormData(f *testing.F)          { fuzzRoundTrip[en.FormData](f) }
+func FuzzHyphenation(f *testing.F)       { fuzzRoundTrip[en.Hyphenation](f) }
+func FuzzSoundData(f *testing.F)         { fuzzRoundTrip[en.SoundData](f) }
+func FuzzTranslationData(f *testing.F)   { fuzzRoundTrip[en.TranslationData](f) }
+func FuzzEtymologyExample(f *testing.F)  { fuzzRoundTrip[en.EtymologyExample](f) }
+func FuzzReferenceData(f *testing.F)     { fuzzRoundTrip[en.ReferenceData](f) }
+func FuzzAttestationData(f *testing.F)   { fuzzRoundTrip[en.AttestationData](f) }
+func FuzzSenseData(f *testing.F)         { fuzzRoundTrip[en.SenseData](f) }
+func FuzzWordData(f *testing.F)          { fuzzRoundTrip[en.WordData](f) }
+func FuzzLazyWordData(f *testing.F)      { fuzzRoundTrip[en.LazyWordData](f) }
@@ -0,0 +1,146 @@
+package en
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Interner decodes words sharing a single copy of the strings with few
+// distinct values, which repeat in most words: language names and
+// codes, parts of speech, tags, raw tags, topics, categories and
+// template names. The copies decoded from JSON are then garbage, which
+// shrinks the heap of a fully loaded corpus; encoding/json already
+// reuses the short strings it decoded recently, so the saving is mostly
+// in long values such as categories. BenchmarkLoad measures it.
+//
+// The table grows with the number of distinct strings and is freed with
+// the Interner. Its methods may be called concurrently.
+type Interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// NewInterner returns an empty Interner.
+func NewInterner() *Interner {
+	return &Interner{strings: make(map[string]string)}
+}
+
+// Unmarshal decodes the JSON line `data` as `w`, as encoding/json does,
+// and interns its strings.
+func (in *Interner) Unmarshal(data []byte, w *WordData) error {
+	if err := json.Unmarshal(data, w); err != nil {
+		return err
+	}
+	in.Intern(w)
+	return nil
+}
+
+// Len returns the number of distinct strings interned.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strings)
+}
+
+// Intern replaces the strings of `w` by the copies in the table.
+func (in *Interner) Intern(w *WordData) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.one(&w.Lang)
+	in.one(&w.LangCode)
+	in.one(&w.Pos)
+	in.all(w.Categories)
+	for _, ls := range [][]LinkageData{
+		w.Abbreviations, w.Anagrams, w.Antonyms, w.CoordinateTerms, w.Derived,
+		w.Holonyms, w.Hypernyms, w.Hyponyms, w.Instances, w.Meronyms,
+		w.Proverbs, w.Related, w.Synonyms, w.Troponyms,
+	} {
+		in.linkages(ls)
+	}
+	in.descendants(w.Descendants)
+	for i := range w.EtymologyExamples {
+		e := &w.EtymologyExamples[i]
+		in.all(e.Tags)
+		in.all(e.RawTags)
+	}
+	for _, ts := range [][]TemplateData{w.EtymologyTemplates, w.HeadTemplates, w.InflectionTemplates, w.InfoTemplates} {
+		for i := range ts {
+			in.one(&ts[i].Name)
+		}
+	}
+	for i := range w.Forms {
+		f := &w.Forms[i]
+		in.all(f.Tags)
+		in.all(f.RawTags)
+		in.all(f.Topics)
+	}
+	for i := range w.Hyphenations {
+		in.all(w.Hyphenations[i].Tags)
+	}
+	for i := range w.Senses {
+		in.sense(&w.Senses[i])
+	}
+	for i := range w.Sounds {
+		s := &w.Sounds[i]
+		in.all(s.Tags)
+		in.all(s.Topics)
+	}
+	for i := range w.Translations {
+		t := &w.Translations[i]
+		in.one(&t.Lang)
+		in.one(&t.LangCode)
+		in.all(t.Tags)
+		in.all(t.Topics)
+	}
+}
+
+func (in *Interner) sense(s *SenseData) {
+	in.all(s.Categories)
+	in.all(s.Tags)
+	in.all(s.Topics)
+	for _, ls := range [][]LinkageData{
+		s.Antonyms, s.CoordinateTerms, s.Holonyms, s.Hypernyms, s.Hyponyms,
+		s.Instances, s.Meronyms, s.Related, s.Synonyms,
+	} {
+		in.linkages(ls)
+	}
+	for i := range s.Examples {
+		e := &s.Examples[i]
+		in.all(e.Tags)
+		in.all(e.RawTags)
+	}
+}
+
+func (in *Interner) linkages(ls []LinkageData) {
+	for i := range ls {
+		l := &ls[i]
+		in.all(l.Tags)
+		in.all(l.RawTags)
+		in.all(l.Topics)
+	}
+}
+
+func (in *Interner) descendants(ds []DescendantData) {
+	for i := range ds {
+		d := &ds[i]
+		in.one(&d.Lang)
+		in.one(&d.LangCode)
+		in.all(d.Tags)
+		in.all(d.RawTags)
+		in.descendants(d.Descendants)
+	}
+}
+
+func (in *Interner) all(ss []string) {
+	for i := range ss {
+		in.one(&ss[i])
+	}
+}
+
+func (in *Interner) one(s *string) {
+	if c, ok := in.strings[*s]; ok {
+		*s = c
+	} else if *s != "" {
+		in.strings[*s] = *s
+	}
+}
@@ -0,0 +1,59 @@
+package en
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+)
+
+// The types below have methods for encoding/json, and for
+// encoding/json/v2 in json_v2.go, where the two versions would encode
+// them differently: encoding/json encodes nil maps and slices as null,
+// which wiktextract never writes, and encoding/json/v2 does not sort map
+// keys.
+
+// MarshalJSON encodes the arguments sorted by name, and nil arguments as
+// {}.
+func (a TemplateArgs) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return marshalV1(map[string]string(a))
+}
+
+// MarshalJSON encodes a nil link as [].
+func (l LinkData) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		l = LinkData{}
+	}
+	return marshalV1([]string(l))
+}
+
+// MarshalJSON encodes nil pairs as [].
+func (r RubyData) MarshalJSON() ([]byte, error) {
+	pairs := [][]string(r)
+	if pairs == nil {
+		pairs = [][]string{}
+	}
+	if slices.ContainsFunc(pairs, func(p []string) bool { return p == nil }) {
+		pairs = slices.Clone(pairs)
+		for i := range pairs {
+			if pairs[i] == nil {
+				pairs[i] = []string{}
+			}
+		}
+	}
+	return marshalV1(pairs)
+}
+
+// marshalV1 encodes `v` for a MarshalJSON method, without escaping HTML:
+// encoding/json escapes the result as it escapes its other strings.
+func marshalV1(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
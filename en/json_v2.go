@@ -0,0 +1,57 @@
+//go:build goexperiment.jsonv2
+
+package en
+
+import (
+	"encoding/json/jsontext"
+	"maps"
+	"slices"
+)
+
+// MarshalJSONTo encodes the arguments sorted by name, and nil arguments
+// as {}, as [TemplateArgs.MarshalJSON] does.
+func (a TemplateArgs) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(a)) {
+		if err := enc.WriteToken(jsontext.String(name)); err != nil {
+			return err
+		}
+		if err := enc.WriteToken(jsontext.String(a[name])); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndObject)
+}
+
+// MarshalJSONTo encodes a nil link as [], as [LinkData.MarshalJSON]
+// does.
+func (l LinkData) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return writeStrings(enc, l)
+}
+
+// MarshalJSONTo encodes nil pairs as [], as [RubyData.MarshalJSON] does.
+func (r RubyData) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for _, pair := range r {
+		if err := writeStrings(enc, pair); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
+
+func writeStrings(enc *jsontext.Encoder, ss []string) error {
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for _, s := range ss {
+		if err := enc.WriteToken(jsontext.String(s)); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
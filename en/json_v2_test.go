@@ -0,0 +1,99 @@
+//go:build goexperiment.jsonv2
+
+package en_test
+
+import (
+	"bytes"
+	jsonv1 "encoding/json"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+func init() {
+	codecs = append(codecs, codec{"v2", marshalV2, func(data []byte, v any) error { return json.Unmarshal(data, v) }})
+}
+
+// marshalV2 encodes with encoding/json/v2, escaping as encoding/json
+// does, which is up to the encoder rather than the types.
+func marshalV2(v any) ([]byte, error) {
+	return json.Marshal(v, jsontext.EscapeForHTML(true), jsontext.EscapeForJS(true))
+}
+
+// TestJSONVersions checks that encoding/json and encoding/json/v2 encode
+// the values of every schema type to the same bytes, and decode them to
+// the same values.
+func TestJSONVersions(t *testing.T) {
+	var values []any
+	for _, tt := range marshalTests {
+		values = append(values, tt.v)
+	}
+	g := generator{rand.New(rand.NewPCG(7, 8))}
+	for _, st := range schemaTypes {
+		values = append(values, reflect.Zero(st.typ).Interface())
+		for range 200 {
+			values = append(values, g.value(st.typ, true, 0).Interface())
+		}
+	}
+	for _, v := range values {
+		typ := reflect.TypeOf(v)
+		v1, err := jsonv1.Marshal(v)
+		if err != nil {
+			t.Fatalf("%s: %v", typ.Name(), err)
+		}
+		v2, err := marshalV2(v)
+		if err != nil {
+			t.Fatalf("%s: %v", typ.Name(), err)
+		}
+		if !bytes.Equal(v1, v2) {
+			t.Errorf("%s: encoded differently:\nv1 %s\nv2 %s", typ.Name(), v1, v2)
+			continue
+		}
+		got1, got2 := reflect.New(typ), reflect.New(typ)
+		if err := jsonv1.Unmarshal(v1, got1.Interface()); err != nil {
+			t.Fatalf("%s: %v", typ.Name(), err)
+		}
+		if err := json.Unmarshal(v1, got2.Interface()); err != nil {
+			t.Fatalf("%s: %v", typ.Name(), err)
+		}
+		if !reflect.DeepEqual(got1.Interface(), got2.Interface()) {
+			t.Errorf("%s: %s decoded differently:\nv1 %+v\nv2 %+v", typ.Name(), v1, got1.Elem(), got2.Elem())
+		}
+	}
+}
+
+// TestJSONVersionsInput checks how both versions decode input that
+// wiktextract does not write. encoding/json/v2 rejects or ignores some of
+// it, and decodes it as encoding/json does with its v1 options.
+func TestJSONVersionsInput(t *testing.T) {
+	tests := []struct {
+		in      string
+		differs bool
+	}{
+		{`{"text":"a","bold_text_offsets":[[0,1]],"ruby":[]}`, false},
+		{`{"text":null,"roman":null,"bold_text_offsets":null,"tags":[]}`, false},
+		{`{"bold_text_offsets":[[1,2,3]]}`, true},
+		{`{"bold_text_offsets":[[1]]}`, true},
+		{`{"Text":"a"}`, true},
+		{`{"text":"a","text":"b"}`, true},
+		{"{\"text\":\"\xff\"}", true},
+	}
+	for _, tt := range tests {
+		var v1, v2, compat en.ExampleData
+		err1 := jsonv1.Unmarshal([]byte(tt.in), &v1)
+		err2 := json.Unmarshal([]byte(tt.in), &v2)
+		if err1 != nil {
+			t.Fatalf("%s: %v", tt.in, err1)
+		}
+		if differs := err2 != nil || !reflect.DeepEqual(v1, v2); differs != tt.differs {
+			t.Errorf("%s: v2 decodes to %+v, %v; v1 to %+v", tt.in, v2, err2, v1)
+		}
+		if err := json.Unmarshal([]byte(tt.in), &compat, jsonv1.DefaultOptionsV1()); err != nil || !reflect.DeepEqual(v1, compat) {
+			t.Errorf("%s: v2 with v1 options decodes to %+v, %v; v1 to %+v", tt.in, compat, err, v1)
+		}
+	}
+}
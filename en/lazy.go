@@ -0,0 +1,86 @@
+package en
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LazyWordData is a [WordData] whose heaviest fields, translations,
+// descendants, head templates and inflection templates, are kept as raw
+// JSON when it is decoded, and decoded on first access. Decoding it is
+// much cheaper for queries that only read the headword and senses.
+//
+// The other fields are those of the embedded WordData. The accessors
+// decode a heavy field into it once and return it, so a LazyWordData
+// must not be accessed concurrently, nor decoded into twice. Encoding it
+// writes the heavy fields as they were read; encode [LazyWordData.Full]
+// to write changes to them.
+type LazyWordData struct {
+	WordData
+	RawTranslations        json.RawMessage `json:"translations,omitzero"`
+	RawDescendants         json.RawMessage `json:"descendants,omitzero"`
+	RawHeadTemplates       json.RawMessage `json:"head_templates,omitzero"`
+	RawInflectionTemplates json.RawMessage `json:"inflection_templates,omitzero"`
+
+	// a bit for each raw field decoded into WordData
+	decoded uint8
+}
+
+const (
+	lazyTranslations uint8 = 1 << iota
+	lazyDescendants
+	lazyHeadTemplates
+	lazyInflectionTemplates
+)
+
+// Translations decodes the translations on first use.
+func (w *LazyWordData) Translations() ([]TranslationData, error) {
+	return decodeLazy(w, lazyTranslations, "translations", w.RawTranslations, &w.WordData.Translations)
+}
+
+// Descendants decodes the descendants on first use.
+func (w *LazyWordData) Descendants() ([]DescendantData, error) {
+	return decodeLazy(w, lazyDescendants, "descendants", w.RawDescendants, &w.WordData.Descendants)
+}
+
+// HeadTemplates decodes the head templates on first use.
+func (w *LazyWordData) HeadTemplates() ([]TemplateData, error) {
+	return decodeLazy(w, lazyHeadTemplates, "head_templates", w.RawHeadTemplates, &w.WordData.HeadTemplates)
+}
+
+// InflectionTemplates decodes the inflection templates on first use.
+func (w *LazyWordData) InflectionTemplates() ([]TemplateData, error) {
+	return decodeLazy(w, lazyInflectionTemplates, "inflection_templates", w.RawInflectionTemplates, &w.WordData.InflectionTemplates)
+}
+
+// Full decodes the heavy fields not decoded yet and returns the complete
+// word, which is the embedded WordData.
+func (w *LazyWordData) Full() (*WordData, error) {
+	if _, err := w.Translations(); err != nil {
+		return nil, err
+	}
+	if _, err := w.Descendants(); err != nil {
+		return nil, err
+	}
+	if _, err := w.HeadTemplates(); err != nil {
+		return nil, err
+	}
+	if _, err := w.InflectionTemplates(); err != nil {
+		return nil, err
+	}
+	return &w.WordData, nil
+}
+
+// decodeLazy decodes `raw` into `v` unless the field `bit` of `w` was
+// decoded before.
+func decodeLazy[T any](w *LazyWordData, bit uint8, name string, raw json.RawMessage, v *T) (T, error) {
+	if w.decoded&bit == 0 {
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, v); err != nil {
+				return *v, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		w.decoded |= bit
+	}
+	return *v, nil
+}
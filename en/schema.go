@@ -5,31 +5,33 @@ package en
 // optional additional text.
 type AltOf struct {
 	Word  string  `json:"word" db:"INDEX"`
-	Extra *string `json:"extra,omitempty"`
+	Extra *string `json:"extra,omitzero"`
 }
 
 type LinkageData struct {
 	// optional alternative form of the target (e.g., in a different script)
-	Alt *string `json:"alt,omitempty"`
+	Alt *string `json:"alt,omitzero"`
 	// optional English text associated with the sense, usually identifying the
 	// linked target sense.
-	English     *string  `json:"english,omitempty"` // DEPRECATED in favour of "translation"
+	English     *string  `json:"english,omitzero"` // DEPRECATED in favour of "translation"
 	Translation string   `json:"translation"`
-	Extra       *string  `json:"extra,omitempty"`
-	Qualifier   *string  `json:"qualifier,omitempty"`
+	Extra       *string  `json:"extra,omitzero"`
+	Qualifier   *string  `json:"qualifier,omitzero"`
 	RawTags     []string `json:"raw_tags,omitempty"`
 	// optional romanization of a linked word in a non-Latin script
-	Roman *string `json:"roman,omitempty"`
+	Roman *string `json:"roman,omitzero"`
 	// Japanese Kanji and furigana
-	Ruby [][]string `json:"ruby,omitempty"`
+	Ruby RubyData `json:"ruby,omitempty"`
 	// text identifying the word sense or context (e.g., `"to
 	// rain very heavily"`)
 	Sense string `json:"sense,omitempty"`
+	// optional source of the linkage, e.g. a thesaurus page
+	Source *string `json:"source,omitzero"`
 	// qualifiers specified for the sense (e.g., field of study, region,
 	// dialect, style)
 	Tags []string `json:"tags,omitempty"`
 	// Optional taxonomic name associated with the linkage
-	Taxonomic *string `json:"taxonomic,omitempty"`
+	Taxonomic *string `json:"taxonomic,omitzero"`
 	// list of topic descriptors for the linkage (e.g., `military`)
 	Topics []string `json:"topics,omitempty"`
 	Urls   []string `json:"urls,omitempty"`
@@ -38,35 +40,44 @@ type LinkageData struct {
 }
 
 type ExampleData struct {
-	Alt                    *string  `json:"alt,omitempty"`
-	English                *string  `json:"english,omitempty"` // DEPRECATED in favour of "translation"
-	Translation            *string  `json:"translation,omitempty"`
+	Alt                    *string  `json:"alt,omitzero"`
+	English                *string  `json:"english,omitzero"` // DEPRECATED in favour of "translation"
+	Translation            *string  `json:"translation,omitzero"`
 	BoldTranslationOffsets [][2]int `json:"bold_translation_offsets,omitempty"`
 	// English-language parenthesized note from the beginning of a non-english example
-	Note *string `json:"note,omitempty"`
-	Ref  *string `json:"ref,omitempty"`
+	Note *string `json:"note,omitzero"`
+	Ref  *string `json:"ref,omitzero"`
 	// romanization (for some languages written in non-Latin scripts)
-	Roman            *string  `json:"roman,omitempty"`
+	Roman            *string  `json:"roman,omitzero"`
 	BoldRomanOffsets [][2]int `json:"bold_roman_offsets,omitempty"`
 	// Japanese Kanji and furigana
-	Ruby [][]string `json:"ruby,omitempty"`
+	Ruby RubyData `json:"ruby,omitempty"`
 	// the example text
 	Text            string   `json:"text"`
 	BoldTextOffsets [][2]int `json:"bold_text_offsets,omitempty"`
-	Tags            []string `json:"tags,omitempty"`
-	RawTags         []string `json:"raw_tags,omitempty"`
+	// example type, `example` or `quotation`
+	Type *string `json:"type,omitzero"`
+	// literal meaning of an idiomatic example
+	LiteralMeaning     *string  `json:"literal_meaning,omitzero"`
+	BoldLiteralOffsets [][2]int `json:"bold_literal_offsets,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	RawTags            []string `json:"raw_tags,omitempty"`
 }
 
 type FormOf struct {
 	Word  string  `json:"word" db:"INDEX"`
-	Extra *string `json:"extra,omitempty"`
-	Roman *string `json:"roman,omitempty"`
+	Extra *string `json:"extra,omitzero"`
+	Roman *string `json:"roman,omitzero"`
 }
 
 // Although LinkData is `LinkData = list[Sequence[str]]` according
 // to official python implementation, it proves to be `list[str]`.
 type LinkData []string
 
+// RubyData are the (text, reading) pairs of a text with ruby annotations,
+// e.g. [["日本", "にほん"]].
+type RubyData [][]string
+
 // It is the alias of `PlusObjTemplateData`.
 type ExtraTemplateData struct {
 	Tags    []string `json:"tags,omitempty"`
@@ -86,10 +97,10 @@ type TemplateData struct {
 	// arguments have keys that are numeric strings, starting with "1".
 	Args TemplateArgs `json:"args"`
 	// the (cleaned) text the template expands to.
-	Explansion string `json:"explansion"`
+	Expansion string `json:"expansion"`
 	// name of the template
 	Name      string             `json:"name"`
-	ExtraData *ExtraTemplateData `json:"extra_data,omitempty"`
+	ExtraData *ExtraTemplateData `json:"extra_data,omitzero"`
 }
 
 type DescendantData struct {
@@ -106,21 +117,21 @@ type DescendantData struct {
 	RawTags     []string         `json:"raw_tags,omitempty"`
 	Descendants []DescendantData `json:"descendants,omitempty"`
 	// Japanese Kanji and furigana
-	Ruby  [][]string `json:"ruby,omitempty"`
-	Sense string     `json:"sense,omitempty"`
+	Ruby  RubyData `json:"ruby,omitempty"`
+	Sense string   `json:"sense,omitempty"`
 }
 
 type FormData struct {
 	Form   string  `json:"form"`
 	HeadNr int     `json:"head_nr"`
-	Ipa    *string `json:"ipa,omitempty"`
-	Roman  *string `json:"roman,omitempty"`
+	Ipa    *string `json:"ipa,omitzero"`
+	Roman  *string `json:"roman,omitzero"`
 	// Japanese Kanji and furigana
-	Ruby    [][]string `json:"ruby,omitempty"`
-	Source  *string    `json:"source,omitempty"`
-	Tags    []string   `json:"tags,omitempty"`
-	RawTags []string   `json:"raw_tags,omitempty"`
-	Topics  []string   `json:"topics,omitempty"`
+	Ruby    RubyData `json:"ruby,omitempty"`
+	Source  *string  `json:"source,omitzero"`
+	Tags    []string `json:"tags,omitempty"`
+	RawTags []string `json:"raw_tags,omitempty"`
+	Topics  []string `json:"topics,omitempty"`
 }
 
 type Hyphenation struct {
@@ -130,14 +141,14 @@ type Hyphenation struct {
 
 type SoundData struct {
 	// name of a sound file in WikiMedia Commons
-	Audio *string `json:"audio,omitempty"`
+	Audio *string `json:"audio,omitzero"`
 	// IPA string associated with the audio file, generally giving IPA
 	// transcription of what is in the
-	AudioIpa *string `json:"audio-ipa,omitempty"`
+	AudioIpa *string `json:"audio-ipa,omitzero"`
 	// English pronunciation respelling
-	Enpr    *string `json:"enpr,omitempty"`
-	Form    *string `json:"form,omitempty"`
-	Hangeul *string `json:"hangeul,omitempty"`
+	Enpr    *string `json:"enpr,omitzero"`
+	Form    *string `json:"form,omitzero"`
+	Hangeul *string `json:"hangeul,omitzero"`
 	// list of homophones for the word.
 	//
 	// Note: a homophone is a word that is pronounced the same as another
@@ -145,34 +156,34 @@ type SoundData struct {
 	//
 	// This field is not documented in the official python TypedDict
 	// models but added according to the project README.
-	Homophone *string `json:"homophone,omitempty"`
+	Homophone *string `json:"homophone,omitzero"`
 	// list of hyphenations.
 	//
 	// Note: syllabification or syllabication, hyphenation, is the separation of a
 	// word into syllables, whether spoken, written or signed.
-	Hyphenation *string `json:"hyphenation,omitempty"`
+	Hyphenation *string `json:"hyphenation,omitzero"`
 	// International Phonetic Alphabet. /.../ or [...].
-	Ipa *string `json:"ipa,omitempty"`
+	Ipa *string `json:"ipa,omitzero"`
 	// URL for an MP3 format sound file
-	Mp3Url *string `json:"mp3_url,omitempty"`
-	Note   *string `json:"note,omitempty"`
+	Mp3Url *string `json:"mp3_url,omitzero"`
+	Note   *string `json:"note,omitzero"`
 	// URL for an OGG Vorbis format sound file
-	OggUrl *string `json:"ogg_url,omitempty"`
-	Other  *string `json:"other,omitempty"`
-	Rhymes *string `json:"rhymes,omitempty"`
+	OggUrl *string `json:"ogg_url,omitzero"`
+	Other  *string `json:"other,omitzero"`
+	Rhymes *string `json:"rhymes,omitzero"`
 	// other labels or context information attached to the pronunciation
 	// entry (e.g., might indicate regional variant or dialect)
 	Tags []string `json:"tags,omitempty"`
 	// text associated with an audio file (often not very useful)
-	Text   *string  `json:"text,omitempty"`
+	Text   *string  `json:"text,omitzero"`
 	Topics []string `json:"topics,omitempty"`
 	// Chinese word pronunciation
-	ZhPron *string `json:"zh-pron,omitempty"`
+	ZhPron *string `json:"zh-pron,omitzero"`
 }
 
 type TranslationData struct {
 	// optional alternative form of the translation (e.g., in a different script)
-	Alt *string `json:"alt,omitempty"`
+	Alt *string `json:"alt,omitzero"`
 	// Wiktionary's 2 or 3-letter language code for the language the language the
 	// translation is for.
 	LangCode string `json:"lang_code"`
@@ -180,49 +191,49 @@ type TranslationData struct {
 	// translation is for.
 	//
 	// DEPRECATED in favour of `lang_code
-	Code *string `json:"code,omitempty"`
+	Code *string `json:"code,omitzero"`
 	// English text, generally clarifying the target sense of the translation.
 	//
 	// DEPRECATED in favour of `translation`
-	English     *string `json:"english"`
+	English     *string `json:"english,omitzero"`
 	Translation string  `json:"translation"`
 	// The language name that the translation is for.
 	Lang string `json:"lang"`
 	// optional text describing or commenting on the translation
-	Note *string `json:"note,omitempty"`
+	Note *string `json:"note,omitzero"`
 	// optional romanization of the translation (when in non-Latin characters)
-	Roman *string `json:"roman,omitempty"`
+	Roman *string `json:"roman,omitzero"`
 	// Optional sense indicating the meaning for which this is a translation
 	// (this is a free-text string, and may not match any gloss exactly)
 	//
 	// P.S. I doubt there is grammar fault from the official documentation
-	Sense *string `json:"sense,omitempty"`
+	Sense *string `json:"sense,omitzero"`
 	// optional list of qualifiers for the translations, e.g., gender
 	Tags []string `json:"tags,omitempty"`
 	// optional taxonomic name of an organism mentioned in the
 	// translation.
-	Taxonomic *string  `json:"taxonomic,omitempty"`
+	Taxonomic *string  `json:"taxonomic,omitzero"`
 	Topics    []string `json:"topics,omitempty"`
 	// the translation in the specified language (may be missing when `note`
 	// is present)
-	Word *string `json:"word,omitempty"`
+	Word *string `json:"word,omitzero"`
 }
 
 // Xxyzz's East Asian etymology example data
 type EtymologyExample struct {
-	English     *string  `json:"english,omitempty"` // DEPRECATED in favour of `translation`
-	Translation *string  `json:"translation,omitempty"`
+	English     *string  `json:"english,omitzero"` // DEPRECATED in favour of `translation`
+	Translation *string  `json:"translation,omitzero"`
 	RawTags     []string `json:"raw_tags,omitempty"`
-	Ref         *string  `json:"ref,omitempty"`
-	Roman       *string  `json:"roman,omitempty"`
+	Ref         *string  `json:"ref,omitzero"`
+	Roman       *string  `json:"roman,omitzero"`
 	Tags        []string `json:"tags,omitempty"`
-	Text        *string  `json:"text,omitempty"`
-	Type        *string  `json:"type,omitempty"`
+	Text        *string  `json:"text,omitzero"`
+	Type        *string  `json:"type,omitzero"`
 }
 
 type ReferenceData struct {
 	Text string  `json:"text"`
-	Refn *string `json:"refn,omitempty"`
+	Refn *string `json:"refn,omitzero"`
 }
 
 type AttestationData struct {
@@ -267,7 +278,7 @@ type SenseData struct {
 	Links     []LinkData    `json:"links,omitempty"`
 	// sense-disambiguated linkages indicating having a part (fairly rare)
 	Meronyms  []LinkageData `json:"meronyms,omitempty"`
-	Qualifier *string       `json:"qualifier,omitempty"`
+	Qualifier *string       `json:"qualifier,omitzero"`
 	// list of gloss strings for the word sense, with less cleaning than
 	// `glosses`. In particular, parenthesized parts that have been parsed from the gloss
 	// into `tags` and `topics` are still present here. This version may be easier for
@@ -285,7 +296,7 @@ type SenseData struct {
 	// "participle", "plural", "feminine", and many others (new words may
 	// appear arbitrarily).
 	Tags      []string `json:"tags,omitempty"`
-	Taxonomic *string  `json:"taxonomic,omitempty"`
+	Taxonomic *string  `json:"taxonomic,omitzero"`
 	// list of sense-disambiguated topic names (kind of similar to
 	// categories but determined differently).
 	Topics []string `json:"topics,omitempty"`
@@ -332,7 +343,7 @@ type WordData struct {
 	EtymologyExamples []EtymologyExample `json:"etymology_examples,omitempty"`
 	// for words with multiple numbererd etymologies, this contains the number of the etymology
 	// under which this entry appeared.
-	EtymologyNumber *int `json:"etymology_number,omitempty"`
+	EtymologyNumber *int `json:"etymology_number,omitzero"`
 	// templates and their arguments and expansions from the etymology section.
 	// This can be used to easily parse etymological relations. Certain common
 	// templates that do not signify etymological relations are not included.
@@ -349,7 +360,7 @@ type WordData struct {
 	// The `etymology_text` field contains the contents of the whole etymology
 	// section cleaned into human-readable text (i.e., templates have been
 	// expanded and HTML tags removed, among other things).
-	EtymologyText *string  `json:"etymology_text,omitempty"`
+	EtymologyText *string  `json:"etymology_text,omitzero"`
 	FormOf        []FormOf `json:"form_of,omitempty"`
 	// list of inflected or alternative forms specified for the word (e.g.,
 	// plural, comparative, superlative, roman script version). This is a list of dictionaries,
@@ -1,3 +1,3 @@
 module github.com/FreeDictionary/wiktionary-schema-go
 
-go 1.25.6
+go 1.27
@@ -0,0 +1,257 @@
+// Package hunspell generates Hunspell spell-checking dictionaries from
+// the lemmas and inflected forms of [en.WordData].
+//
+// Every form is explained by a suffix rule (SFX) when it shares a
+// prefix with its lemma; lemmas with the same set of rules share a
+// flag. Forms that no suffix rule can produce are listed as words of
+// their own, as are the forms of the least used rule sets beyond
+// MAX_FLAG.
+package hunspell
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// MAX_FLAG is the largest flag Hunspell accepts with "FLAG num".
+const MAX_FLAG int = 65000
+
+// Rule is a suffix rule: a stem ending in Condition loses Strip and
+// gains Add.
+type Rule struct {
+	Strip     string
+	Add       string
+	Condition string
+}
+
+// Stem is a word of the .dic file with the flags of its rule classes.
+type Stem struct {
+	Word  string
+	Flags []int
+}
+
+// Dictionary is a generated rule set.
+type Dictionary struct {
+	// Classes[i] are the rules of flag i+1
+	Classes [][]Rule
+	// sorted by word
+	Stems []Stem
+}
+
+// skippedFormTags mark forms that are not spellings in the language.
+var skippedFormTags = []string{"romanization", "transliteration"}
+
+// Builder collects lemmas and their forms.
+type Builder struct {
+	lang  string
+	forms map[string]map[string]bool
+}
+
+// NewBuilder collects the words whose language code is `lang`, or all
+// words if it is empty.
+func NewBuilder(lang string) *Builder {
+	return &Builder{lang: lang, forms: make(map[string]map[string]bool)}
+}
+
+// Add collects the headword and forms of `w`. Multiword expressions
+// are skipped, as Hunspell checks single words.
+func (b *Builder) Add(w *en.WordData) {
+	if b.lang != "" && w.LangCode != b.lang || !isWord(w.Word) {
+		return
+	}
+	forms := b.forms[w.Word]
+	if forms == nil {
+		forms = make(map[string]bool)
+		b.forms[w.Word] = forms
+	}
+	for _, f := range w.Forms {
+		if !article.ShowForm(f) || !isWord(f.Form) || f.Form == w.Word || slices.ContainsFunc(f.Tags, func(t string) bool {
+			return slices.Contains(skippedFormTags, t)
+		}) {
+			continue
+		}
+		forms[f.Form] = true
+	}
+}
+
+func isWord(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, unicode.IsSpace) && !strings.Contains(s, "/")
+}
+
+// Infer returns the rule turning `lemma` into `form`, if any. The stem
+// left after stripping must not be empty, and strip and add must be
+// representable in an affix file.
+func Infer(lemma, form string) (Rule, bool) {
+	l, f := []rune(lemma), []rune(form)
+	n := 0
+	for n < len(l) && n < len(f) && l[n] == f[n] {
+		n++
+	}
+	if n == 0 {
+		return Rule{}, false
+	}
+	r := Rule{Strip: string(l[n:]), Add: string(f[n:]), Condition: string(l[n:])}
+	if r.Condition == "" {
+		r.Condition = "."
+	}
+	for _, s := range []string{r.Strip, r.Add} {
+		if s == "0" || strings.ContainsAny(s, "[]^./") {
+			return Rule{}, false
+		}
+	}
+	return r, true
+}
+
+// Build infers the rules and assigns flags, the most used class
+// getting flag 1 and none getting more than MAX_FLAG.
+func (b *Builder) Build() *Dictionary {
+	type paradigm struct {
+		rules []Rule
+		key   string
+		count int
+	}
+	paradigms := make(map[string]*paradigm)
+	lemmaParadigm := make(map[string]*paradigm)
+	lemmaForms := make(map[string][]string)
+	words := make(map[string]bool)
+
+	for lemma, forms := range b.forms {
+		words[lemma] = true
+		var rules []Rule
+		for form := range forms {
+			if r, ok := Infer(lemma, form); ok {
+				rules = append(rules, r)
+				lemmaForms[lemma] = append(lemmaForms[lemma], form)
+			} else {
+				words[form] = true
+			}
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		slices.SortFunc(rules, compareRules)
+		rules = slices.Compact(rules)
+		var key strings.Builder
+		for _, r := range rules {
+			key.WriteString(r.Strip + "\x00" + r.Add + "\x00" + r.Condition + "\x00")
+		}
+		p := paradigms[key.String()]
+		if p == nil {
+			p = &paradigm{rules: rules, key: key.String()}
+			paradigms[p.key] = p
+		}
+		p.count++
+		lemmaParadigm[lemma] = p
+	}
+
+	ordered := slices.Collect(maps.Values(paradigms))
+	slices.SortFunc(ordered, func(a, b *paradigm) int {
+		return cmp.Or(cmp.Compare(b.count, a.count), cmp.Compare(a.key, b.key))
+	})
+	if len(ordered) > MAX_FLAG {
+		// the forms of the rarest paradigms, often those of a single
+		// irregular lemma, are listed instead
+		for _, p := range ordered[MAX_FLAG:] {
+			p.count = 0
+		}
+		for lemma, p := range lemmaParadigm {
+			if p.count == 0 {
+				for _, form := range lemmaForms[lemma] {
+					words[form] = true
+				}
+			}
+		}
+		ordered = ordered[:MAX_FLAG]
+	}
+	d := &Dictionary{}
+	flags := make(map[*paradigm]int)
+	for i, p := range ordered {
+		d.Classes = append(d.Classes, p.rules)
+		flags[p] = i + 1
+	}
+	for _, w := range slices.Sorted(maps.Keys(words)) {
+		s := Stem{Word: w}
+		if flag, ok := flags[lemmaParadigm[w]]; ok {
+			s.Flags = []int{flag}
+		}
+		d.Stems = append(d.Stems, s)
+	}
+	return d
+}
+
+func compareRules(a, b Rule) int {
+	return cmp.Or(cmp.Compare(a.Strip, b.Strip), cmp.Compare(a.Add, b.Add), cmp.Compare(a.Condition, b.Condition))
+}
+
+// WriteAff writes the affix file: the encoding, numeric flags, the TRY
+// and WORDCHARS characters found in the words, and one SFX class per
+// flag.
+func (d *Dictionary) WriteAff(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("SET UTF-8\nFLAG num\n")
+
+	freq := make(map[rune]int)
+	wordChars := make(map[rune]bool)
+	for _, s := range d.Stems {
+		for _, r := range s.Word {
+			if unicode.IsLetter(r) {
+				freq[unicode.ToLower(r)]++
+			} else {
+				wordChars[r] = true
+			}
+		}
+	}
+	try := slices.SortedFunc(maps.Keys(freq), func(a, b rune) int {
+		return cmp.Or(cmp.Compare(freq[b], freq[a]), cmp.Compare(a, b))
+	})
+	if len(try) > 0 {
+		bw.WriteString("TRY " + string(try) + "\n")
+	}
+	if len(wordChars) > 0 {
+		bw.WriteString("WORDCHARS " + string(slices.Sorted(maps.Keys(wordChars))) + "\n")
+	}
+
+	for i, rules := range d.Classes {
+		flag := strconv.Itoa(i + 1)
+		bw.WriteString("\nSFX " + flag + " N " + strconv.Itoa(len(rules)) + "\n")
+		for _, r := range rules {
+			bw.WriteString("SFX " + flag + " " + orZero(r.Strip) + " " + orZero(r.Add) + " " + r.Condition + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// WriteDic writes the word count followed by one stem per line.
+func (d *Dictionary) WriteDic(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(strconv.Itoa(len(d.Stems)) + "\n")
+	for _, s := range d.Stems {
+		bw.WriteString(s.Word)
+		for i, f := range s.Flags {
+			if i == 0 {
+				bw.WriteByte('/')
+			} else {
+				bw.WriteByte(',')
+			}
+			bw.WriteString(strconv.Itoa(f))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,263 @@
+package hunspell_test
+
+import (
+	"bufio"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/hunspell"
+)
+
+func forms(fs ...string) []en.FormData {
+	var out []en.FormData
+	for _, f := range fs {
+		out = append(out, en.FormData{Form: f, Tags: []string{"inflected"}})
+	}
+	return out
+}
+
+var words = []*en.WordData{
+	{Word: "cat", LangCode: "en", Forms: forms("cats")},
+	{Word: "dog", LangCode: "en", Forms: forms("dogs")},
+	{Word: "city", LangCode: "en", Forms: forms("cities")},
+	{Word: "pity", LangCode: "en", Forms: forms("pities", "pitied", "pitying")},
+	{Word: "go", LangCode: "en", Forms: forms("goes", "went", "gone", "going")},
+	{Word: "be", LangCode: "en", Forms: forms("am", "is", "are", "was", "were", "been", "being")},
+	{Word: "ice cream", LangCode: "en", Forms: forms("ice creams")},
+	{Word: "mother-in-law", LangCode: "en", Forms: forms("mothers-in-law", "mother-in-laws")},
+	{Word: "naïve", LangCode: "en", Forms: forms("naïver", "naïvest")},
+	{Word: "ox", LangCode: "en", Forms: append(forms("oxen"), en.FormData{Form: "-", Tags: []string{"table-tags"}})},
+	{Word: "cat", LangCode: "en", Pos: "verb", Forms: forms("catted", "catting")},
+	{Word: "Hund", LangCode: "de", Forms: forms("Hunde")},
+	{Word: "a.m.", LangCode: "en", Forms: forms("a.m.s")},
+}
+
+// affixes is a minimal Hunspell suffix expander.
+type affixes map[string][]rule
+
+type rule struct {
+	strip, add string
+	cond       []string // character classes, one per stem position
+}
+
+// parseCondition splits a condition into classes: a character, "." or
+// a [...] / [^...] group.
+func parseCondition(c string) []string {
+	if c == "." {
+		return nil
+	}
+	var classes []string
+	rs := []rune(c)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '[' {
+			j := i
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			classes = append(classes, string(rs[i:j+1]))
+			i = j
+		} else {
+			classes = append(classes, string(rs[i]))
+		}
+	}
+	return classes
+}
+
+func (r rule) matches(stem string) bool {
+	rs := []rune(stem)
+	if len(r.cond) > len(rs) {
+		return false
+	}
+	for i, class := range r.cond {
+		c := string(rs[len(rs)-len(r.cond)+i])
+		switch {
+		case class == ".":
+		case strings.HasPrefix(class, "[^"):
+			if strings.Contains(class[2:len(class)-1], c) {
+				return false
+			}
+		case strings.HasPrefix(class, "["):
+			if !strings.Contains(class[1:len(class)-1], c) {
+				return false
+			}
+		case class != c:
+			return false
+		}
+	}
+	return true
+}
+
+func parseAff(t *testing.T, aff string) affixes {
+	t.Helper()
+	a := affixes{}
+	sc := bufio.NewScanner(strings.NewReader(aff))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || f[0] != "SFX" {
+			continue
+		}
+		if len(f) == 4 {
+			// class header: SFX flag cross count
+			if _, err := strconv.Atoi(f[3]); err != nil {
+				t.Fatalf("bad header %q", sc.Text())
+			}
+			continue
+		}
+		if len(f) != 5 {
+			t.Fatalf("bad rule %q", sc.Text())
+		}
+		unzero := func(s string) string {
+			if s == "0" {
+				return ""
+			}
+			return s
+		}
+		a[f[1]] = append(a[f[1]], rule{strip: unzero(f[2]), add: unzero(f[3]), cond: parseCondition(f[4])})
+	}
+	return a
+}
+
+// expand returns every word generated by a .dic file.
+func (a affixes) expand(t *testing.T, dic string) map[string]bool {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(dic, "\n"), "\n")
+	if n, err := strconv.Atoi(lines[0]); err != nil || n != len(lines)-1 {
+		t.Fatalf("bad word count %q for %d lines", lines[0], len(lines)-1)
+	}
+	words := map[string]bool{}
+	for _, line := range lines[1:] {
+		stem, flags, _ := strings.Cut(line, "/")
+		words[stem] = true
+		if flags == "" {
+			continue
+		}
+		for _, flag := range strings.Split(flags, ",") {
+			rules, ok := a[flag]
+			if !ok {
+				t.Errorf("undefined flag %s", flag)
+			}
+			for _, r := range rules {
+				if r.matches(stem) && strings.HasSuffix(stem, r.strip) {
+					base := strings.TrimSuffix(stem, r.strip)
+					if base == "" {
+						t.Errorf("rule %+v strips all of %s", r, stem)
+					}
+					words[base+r.add] = true
+				}
+			}
+		}
+	}
+	return words
+}
+
+func TestAccepted(t *testing.T) {
+	b := hunspell.NewBuilder("en")
+	for _, w := range words {
+		b.Add(w)
+	}
+	d := b.Build()
+	var aff, dic strings.Builder
+	if err := d.WriteAff(&aff); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteDic(&dic); err != nil {
+		t.Fatal(err)
+	}
+	generated := parseAff(t, aff.String()).expand(t, dic.String())
+
+	want := map[string]bool{}
+	for _, w := range words {
+		if w.LangCode != "en" || strings.Contains(w.Word, " ") {
+			continue
+		}
+		want[w.Word] = true
+		for _, f := range w.Forms {
+			if f.Form != "-" {
+				want[f.Form] = true
+			}
+		}
+	}
+	if !maps.Equal(generated, want) {
+		t.Errorf("generated %v\nwant %v\naff:\n%s\ndic:\n%s", slices.Sorted(maps.Keys(generated)), slices.Sorted(maps.Keys(want)), aff.String(), dic.String())
+	}
+
+	// cat and dog share the plural class, ranked first
+	if d.Stems[slices.IndexFunc(d.Stems, func(s hunspell.Stem) bool { return s.Word == "dog" })].Flags[0] != 1 {
+		t.Errorf("dog should have the most used flag:\n%s", dic.String())
+	}
+	for _, line := range []string{"SET UTF-8", "FLAG num", "WORDCHARS -."} {
+		if !strings.Contains(aff.String(), line+"\n") {
+			t.Errorf("missing %q in\n%s", line, aff.String())
+		}
+	}
+	// suppletive forms are listed explicitly
+	if !strings.Contains(dic.String(), "\nwent\n") || !strings.Contains(dic.String(), "\nam\n") {
+		t.Errorf("explicit forms missing:\n%s", dic.String())
+	}
+}
+
+func TestFlagCeiling(t *testing.T) {
+	b := hunspell.NewBuilder("en")
+	want := map[string]bool{}
+	add := func(w *en.WordData) {
+		b.Add(w)
+		want[w.Word] = true
+		for _, f := range w.Forms {
+			want[f.Form] = true
+		}
+	}
+	// a paradigm of its own for every lemma, and one shared by two
+	for i := range hunspell.MAX_FLAG + 10 {
+		lemma := "w" + strconv.Itoa(i)
+		add(&en.WordData{Word: lemma, LangCode: "en", Forms: forms(lemma + "q" + strconv.Itoa(i))})
+	}
+	add(&en.WordData{Word: "cat", LangCode: "en", Forms: forms("cats")})
+	add(&en.WordData{Word: "dog", LangCode: "en", Forms: forms("dogs")})
+	d := b.Build()
+
+	if len(d.Classes) != hunspell.MAX_FLAG {
+		t.Errorf("%d classes", len(d.Classes))
+	}
+	for _, s := range d.Stems {
+		for _, f := range s.Flags {
+			if f < 1 || f > hunspell.MAX_FLAG {
+				t.Fatalf("%s has flag %d", s.Word, f)
+			}
+		}
+	}
+	var aff, dic strings.Builder
+	d.WriteAff(&aff)
+	d.WriteDic(&dic)
+	if generated := parseAff(t, aff.String()).expand(t, dic.String()); !maps.Equal(generated, want) {
+		t.Errorf("%d words generated, want %d", len(generated), len(want))
+	}
+	if i := slices.IndexFunc(d.Stems, func(s hunspell.Stem) bool { return s.Word == "cat" }); d.Stems[i].Flags[0] != 1 {
+		t.Errorf("cat has flags %v", d.Stems[i].Flags)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	for _, tc := range []struct {
+		lemma, form string
+		want        hunspell.Rule
+		ok          bool
+	}{
+		{"cat", "cats", hunspell.Rule{Add: "s", Condition: "."}, true},
+		{"city", "cities", hunspell.Rule{Strip: "y", Add: "ies", Condition: "y"}, true},
+		{"naïve", "naïvest", hunspell.Rule{Add: "st", Condition: "."}, true},
+		{"mouse", "mice", hunspell.Rule{Strip: "ouse", Add: "ice", Condition: "ouse"}, true},
+		{"go", "went", hunspell.Rule{}, false},
+		{"a.m.", "a.m.s", hunspell.Rule{Add: "s", Condition: "."}, true},
+		{"ab.", "ac.", hunspell.Rule{}, false},
+		{"x", "x0", hunspell.Rule{}, false},
+	} {
+		got, ok := hunspell.Infer(tc.lemma, tc.form)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Infer(%s, %s) = %+v, %v", tc.lemma, tc.form, got, ok)
+		}
+	}
+}
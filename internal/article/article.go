@@ -0,0 +1,228 @@
+// Package article turns [en.WordData] into a display-oriented model
+// shared by the dictionary exporters: senses are nested, bold example
+// spans are resolved and pronunciations are flattened into labels.
+package article
+
+import (
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// Entry is the displayable content of one [en.WordData].
+type Entry struct {
+	Word string
+	// part-of-speech label, e.g. "noun" or "adjective"
+	Pos            string
+	Pronunciations []Pronunciation
+	// inflected and alternative forms worth showing
+	Forms     []Form
+	Senses    []*Sense
+	Etymology string
+	Data      *en.WordData
+}
+
+type Pronunciation struct {
+	// "IPA" or "enPR"
+	System  string
+	Text    string
+	Accents []string
+}
+
+type Form struct {
+	Form string
+	Tags []string
+}
+
+// Sense is a node of the sense hierarchy. Wiktextract flattens nested
+// senses, repeating the parent glosses at the beginning of `Glosses`;
+// they are nested back here.
+type Sense struct {
+	Gloss     string
+	Tags      []string
+	Qualifier string
+	Examples  []Example
+	Subsenses []*Sense
+	// nil for intermediate senses that only exist as a gloss prefix
+	Data *en.SenseData
+}
+
+type Example struct {
+	Text        []Span
+	Translation []Span
+	Roman       string
+	Ref         string
+}
+
+// Span is a run of text that is either bold or not.
+type Span struct {
+	Text string
+	Bold bool
+}
+
+// New builds the entry of `w`.
+func New(w *en.WordData) *Entry {
+	e := &Entry{Word: w.Word, Pos: PosLabel(w.Pos), Data: w}
+	if w.EtymologyText != nil {
+		e.Etymology = strings.TrimSpace(*w.EtymologyText)
+	}
+	for _, s := range w.Sounds {
+		if s.Ipa != nil && *s.Ipa != "" {
+			e.Pronunciations = append(e.Pronunciations, Pronunciation{System: "IPA", Text: *s.Ipa, Accents: s.Tags})
+		}
+		if s.Enpr != nil && *s.Enpr != "" {
+			e.Pronunciations = append(e.Pronunciations, Pronunciation{System: "enPR", Text: *s.Enpr, Accents: s.Tags})
+		}
+	}
+	for _, f := range w.Forms {
+		if ShowForm(f) {
+			e.Forms = append(e.Forms, Form{Form: f.Form, Tags: f.Tags})
+		}
+	}
+	e.Senses = nest(w.Senses)
+	return e
+}
+
+// hiddenFormTags mark forms that only carry metadata about inflection
+// tables.
+var hiddenFormTags = []string{"table-tags", "inflection-template", "class"}
+
+// ShowForm reports whether a form is a real word form rather than
+// inflection table metadata or a "-" placeholder.
+func ShowForm(f en.FormData) bool {
+	if f.Form == "" || f.Form == "-" {
+		return false
+	}
+	for _, t := range f.Tags {
+		for _, h := range hiddenFormTags {
+			if t == h {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Inflections returns the distinct shown forms other than the headword,
+// in order, for dictionary formats that index them.
+func (e *Entry) Inflections() []string {
+	var forms []string
+	seen := map[string]bool{e.Word: true}
+	for _, f := range e.Forms {
+		if !seen[f.Form] {
+			seen[f.Form] = true
+			forms = append(forms, f.Form)
+		}
+	}
+	return forms
+}
+
+// nest rebuilds the sense hierarchy from the gloss prefixes.
+func nest(senses []en.SenseData) []*Sense {
+	var top []*Sense
+	for i := range senses {
+		sd := &senses[i]
+		glosses := sd.Glosses
+		if len(glosses) == 0 {
+			glosses = sd.RawGlosses
+		}
+		if len(glosses) == 0 {
+			continue
+		}
+		siblings := &top
+		for _, g := range glosses[:len(glosses)-1] {
+			var parent *Sense
+			if n := len(*siblings); n > 0 && (*siblings)[n-1].Gloss == g {
+				parent = (*siblings)[n-1]
+			} else {
+				parent = &Sense{Gloss: g}
+				*siblings = append(*siblings, parent)
+			}
+			siblings = &parent.Subsenses
+		}
+		*siblings = append(*siblings, newSense(glosses[len(glosses)-1], sd))
+	}
+	return top
+}
+
+func newSense(gloss string, sd *en.SenseData) *Sense {
+	s := &Sense{Gloss: gloss, Tags: sd.Tags, Data: sd}
+	if sd.Qualifier != nil {
+		s.Qualifier = *sd.Qualifier
+	}
+	for _, ex := range sd.Examples {
+		e := Example{Text: Spans(ex.Text, ex.BoldTextOffsets)}
+		if ex.Translation != nil {
+			e.Translation = Spans(*ex.Translation, ex.BoldTranslationOffsets)
+		} else if ex.English != nil {
+			e.Translation = Spans(*ex.English, ex.BoldTranslationOffsets)
+		}
+		if ex.Roman != nil {
+			e.Roman = *ex.Roman
+		}
+		if ex.Ref != nil {
+			e.Ref = *ex.Ref
+		}
+		s.Examples = append(s.Examples, e)
+	}
+	return s
+}
+
+// Spans splits `text` into bold and regular runs. Offsets are
+// [start, end) pairs counted in code points, as produced by Python;
+// out-of-range or overlapping offsets are clipped.
+func Spans(text string, offsets [][2]int) []Span {
+	if text == "" {
+		return nil
+	}
+	runes := []rune(text)
+	bold := make([]bool, len(runes))
+	for _, o := range offsets {
+		for i := max(o[0], 0); i < min(o[1], len(runes)); i++ {
+			bold[i] = true
+		}
+	}
+	var spans []Span
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || bold[i] != bold[start] {
+			spans = append(spans, Span{Text: string(runes[start:i]), Bold: bold[start]})
+			start = i
+		}
+	}
+	return spans
+}
+
+// PlainText joins spans, dropping the bold markers.
+func PlainText(spans []Span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+var posLabels = map[string]string{
+	"adj":         "adjective",
+	"adv":         "adverb",
+	"abbrev":      "abbreviation",
+	"conj":        "conjunction",
+	"det":         "determiner",
+	"intj":        "interjection",
+	"name":        "proper noun",
+	"num":         "numeral",
+	"postp":       "postposition",
+	"prep":        "preposition",
+	"prep_phrase": "prepositional phrase",
+	"pron":        "pronoun",
+	"punct":       "punctuation mark",
+}
+
+// PosLabel returns a human-readable label for a wiktextract `pos`
+// value, e.g. "adjective" for "adj".
+func PosLabel(pos string) string {
+	if label, ok := posLabels[pos]; ok {
+		return label
+	}
+	return strings.ReplaceAll(pos, "_", " ")
+}
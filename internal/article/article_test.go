@@ -0,0 +1,74 @@
+package article_test
+
+import (
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestSpans(t *testing.T) {
+	spans := article.Spans("I ate a tomáto today", [][2]int{{8, 14}, {30, 40}})
+	want := []article.Span{{"I ate a ", false}, {"tomáto", true}, {" today", false}}
+	if len(spans) != len(want) {
+		t.Fatalf("got %+v", spans)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("span %d = %+v, want %+v", i, spans[i], want[i])
+		}
+	}
+}
+
+func TestNestedSenses(t *testing.T) {
+	w := &en.WordData{Word: "run", Pos: "verb", Senses: []en.SenseData{
+		{Glosses: []string{"To move quickly."}},
+		{Glosses: []string{"To move quickly.", "To jog."}, Tags: []string{"intransitive"}},
+		{Glosses: []string{"To manage.", "To operate a business."}},
+		{Glosses: []string{"To flow."}},
+		{Tags: []string{"no-gloss"}},
+	}}
+	e := article.New(w)
+	if e.Pos != "verb" || len(e.Senses) != 3 {
+		t.Fatalf("got %d top-level senses", len(e.Senses))
+	}
+	if sub := e.Senses[0].Subsenses; len(sub) != 1 || sub[0].Gloss != "To jog." {
+		t.Errorf("subsenses of sense 1: %+v", sub)
+	}
+	if e.Senses[1].Data != nil || e.Senses[1].Gloss != "To manage." || len(e.Senses[1].Subsenses) != 1 {
+		t.Errorf("intermediate sense: %+v", e.Senses[1])
+	}
+
+	want := "verb\n" +
+		"1. To move quickly.\n" +
+		"  1.1. (intransitive) To jog.\n" +
+		"2. To manage.\n" +
+		"  2.1. To operate a business.\n" +
+		"3. To flow."
+	if got := article.Plain(e); got != want {
+		t.Errorf("Plain:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	w := &en.WordData{
+		Word: "tomato", Pos: "noun",
+		Sounds: []en.SoundData{{Ipa: ptr("/təˈmeɪtoʊ/"), Tags: []string{"US"}}},
+		Forms:  []en.FormData{{Form: "tomatoes", Tags: []string{"plural"}}, {Form: "en-noun", Tags: []string{"inflection-template"}}},
+		Senses: []en.SenseData{{
+			Glosses:  []string{"A <red> fruit."},
+			Examples: []en.ExampleData{{Text: "a tomato salad", BoldTextOffsets: [][2]int{{2, 8}}}},
+		}},
+		EtymologyText: ptr("From Spanish tomate."),
+	}
+	want := `<div class="entry"><p class="pos"><i>noun</i></p>` +
+		`<p class="pron">IPA: /təˈmeɪtoʊ/ (US)</p>` +
+		`<p class="forms"><b>tomatoes</b> (plural)</p>` +
+		`<ol><li>A &lt;red&gt; fruit.<ul class="examples"><li>a <b>tomato</b> salad</li></ul></li></ol>` +
+		`<p class="etym">From Spanish tomate.</p></div>`
+	if got := article.HTML(article.New(w)); got != want {
+		t.Errorf("HTML:\n%s\nwant\n%s", got, want)
+	}
+}
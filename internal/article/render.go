@@ -0,0 +1,157 @@
+package article
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Label formats tags and a qualifier as "(tag, tag, qualifier)", or
+// returns "" if there are none.
+func Label(tags []string, qualifier string) string {
+	parts := make([]string, 0, len(tags)+1)
+	for _, t := range tags {
+		parts = append(parts, strings.ReplaceAll(t, "-", " "))
+	}
+	if qualifier != "" {
+		parts = append(parts, qualifier)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// String formats a pronunciation as "IPA: /…/ (US)".
+func (p Pronunciation) String() string {
+	s := p.System + ": " + p.Text
+	if l := Label(p.Accents, ""); l != "" {
+		s += " " + l
+	}
+	return s
+}
+
+// Plain renders the entry as plain text: the part of speech,
+// pronunciations, forms, numbered senses with their examples and the
+// etymology. The headword itself is not repeated.
+func Plain(e *Entry) string {
+	var sb strings.Builder
+	sb.WriteString(e.Pos + "\n")
+	for _, p := range e.Pronunciations {
+		sb.WriteString(p.String() + "\n")
+	}
+	if len(e.Forms) > 0 {
+		forms := make([]string, len(e.Forms))
+		for i, f := range e.Forms {
+			forms[i] = f.Form
+			if l := Label(f.Tags, ""); l != "" {
+				forms[i] += " " + l
+			}
+		}
+		sb.WriteString("Forms: " + strings.Join(forms, "; ") + "\n")
+	}
+	plainSenses(&sb, e.Senses, "", 0)
+	if e.Etymology != "" {
+		sb.WriteString("Etymology: " + e.Etymology + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func plainSenses(sb *strings.Builder, senses []*Sense, prefix string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for i, s := range senses {
+		number := fmt.Sprintf("%s%d.", prefix, i+1)
+		sb.WriteString(indent + number + " ")
+		if l := Label(s.Tags, s.Qualifier); l != "" {
+			sb.WriteString(l + " ")
+		}
+		sb.WriteString(s.Gloss + "\n")
+		for _, ex := range s.Examples {
+			sb.WriteString(indent + "   » " + PlainText(ex.Text))
+			if ex.Roman != "" {
+				sb.WriteString(" (" + ex.Roman + ")")
+			}
+			if len(ex.Translation) > 0 {
+				sb.WriteString(" — " + PlainText(ex.Translation))
+			}
+			sb.WriteString("\n")
+		}
+		plainSenses(sb, s.Subsenses, number, depth+1)
+	}
+}
+
+// HTML renders the same content as [Plain] as an HTML fragment, with
+// bold example spans and nested ordered lists of senses. The fragment
+// is well-formed XML, so it can be embedded in XHTML documents.
+func HTML(e *Entry) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="entry">`)
+	sb.WriteString(`<p class="pos"><i>` + html.EscapeString(e.Pos) + `</i></p>`)
+	if len(e.Pronunciations) > 0 {
+		prons := make([]string, len(e.Pronunciations))
+		for i, p := range e.Pronunciations {
+			prons[i] = html.EscapeString(p.String())
+		}
+		sb.WriteString(`<p class="pron">` + strings.Join(prons, "<br/>") + `</p>`)
+	}
+	if len(e.Forms) > 0 {
+		forms := make([]string, len(e.Forms))
+		for i, f := range e.Forms {
+			forms[i] = "<b>" + html.EscapeString(f.Form) + "</b>"
+			if l := Label(f.Tags, ""); l != "" {
+				forms[i] += " " + html.EscapeString(l)
+			}
+		}
+		sb.WriteString(`<p class="forms">` + strings.Join(forms, "; ") + `</p>`)
+	}
+	htmlSenses(&sb, e.Senses)
+	if e.Etymology != "" {
+		sb.WriteString(`<p class="etym">` + html.EscapeString(e.Etymology) + `</p>`)
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+func htmlSenses(sb *strings.Builder, senses []*Sense) {
+	if len(senses) == 0 {
+		return
+	}
+	sb.WriteString("<ol>")
+	for _, s := range senses {
+		sb.WriteString("<li>")
+		if l := Label(s.Tags, s.Qualifier); l != "" {
+			sb.WriteString(`<span class="tags">` + html.EscapeString(l) + `</span> `)
+		}
+		sb.WriteString(html.EscapeString(s.Gloss))
+		if len(s.Examples) > 0 {
+			sb.WriteString(`<ul class="examples">`)
+			for _, ex := range s.Examples {
+				sb.WriteString("<li>" + HTMLSpans(ex.Text))
+				if ex.Roman != "" {
+					sb.WriteString(" <i>" + html.EscapeString(ex.Roman) + "</i>")
+				}
+				if len(ex.Translation) > 0 {
+					sb.WriteString(" — " + HTMLSpans(ex.Translation))
+				}
+				sb.WriteString("</li>")
+			}
+			sb.WriteString("</ul>")
+		}
+		htmlSenses(sb, s.Subsenses)
+		sb.WriteString("</li>")
+	}
+	sb.WriteString("</ol>")
+}
+
+// HTMLSpans escapes spans, wrapping bold ones in <b>.
+func HTMLSpans(spans []Span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		if s.Bold {
+			sb.WriteString("<b>" + html.EscapeString(s.Text) + "</b>")
+		} else {
+			sb.WriteString(html.EscapeString(s.Text))
+		}
+	}
+	return sb.String()
+}
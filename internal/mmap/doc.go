@@ -0,0 +1,3 @@
+// Package mmap maps files into memory read-only, falling back to
+// reading them on platforms without mmap.
+package mmap
@@ -0,0 +1,312 @@
+// Package ipa parses the IPA transcriptions found in `SoundData.Ipa`
+// (e.g. "/ˈwɔːtə(ɹ)/") into phonemes, stress marks and syllables.
+package ipa
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Notation tells how a transcription was delimited.
+type Notation int
+
+const (
+	// no delimiters
+	Unspecified Notation = iota
+	// broad transcription between slashes, e.g. /kæt/
+	Phonemic
+	// narrow transcription between square brackets, e.g. [kʰæt]
+	Phonetic
+)
+
+func (n Notation) String() string {
+	switch n {
+	case Phonemic:
+		return "phonemic"
+	case Phonetic:
+		return "phonetic"
+	}
+	return "unspecified"
+}
+
+// Kind is the kind of a [Token].
+type Kind int
+
+const (
+	// a phoneme with its diacritics, length marks and tie bars attached,
+	// e.g. "t͡ʃ", "ɔː" or "kʰ"
+	Phoneme Kind = iota
+	// primary stress mark ˈ
+	PrimaryStress
+	// secondary stress mark ˌ
+	SecondaryStress
+	// syllable break .
+	SyllableBreak
+	// linking mark ‿
+	Linking
+	// space, or a foot | or intonation ‖ group boundary
+	WordBoundary
+	// modifier (e.g. ʰ or ː) of the preceding phoneme that lives in a
+	// different optional group, as in "t(ʰ)". It only appears in parsed
+	// transcriptions and is merged into its phoneme by
+	// [Transcription.Variants].
+	Modifier
+)
+
+// Token is a single unit of a transcription.
+type Token struct {
+	Kind Kind
+	Text string
+	// index (starting at 1) of the parenthesized optional group this
+	// token belongs to, or 0 when the token is mandatory.
+	Group int
+}
+
+// IsVowel reports whether the token is a vowel phoneme.
+func (t Token) IsVowel() bool {
+	if t.Kind != Phoneme {
+		return false
+	}
+	for _, r := range t.Text {
+		if strings.ContainsRune(vowels, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSyllabic reports whether the token can be the nucleus of a
+// syllable: a vowel or a consonant carrying the syllabic mark.
+func (t Token) IsSyllabic() bool {
+	return t.IsVowel() || (t.Kind == Phoneme && strings.ContainsAny(t.Text, "̩̍"))
+}
+
+// Transcription is a parsed IPA transcription.
+type Transcription struct {
+	Notation Notation
+	Tokens   []Token
+	// number of optional groups in Tokens
+	Groups int
+}
+
+const vowels = "aeiouyæøœɶɐɑɒɔɘəɚɛɜɝɞɤɨɪɯɵʉʊʌʏᵻ"
+
+// SyntaxError is returned by [Parse] for malformed transcriptions.
+type SyntaxError struct {
+	Ipa    string
+	Offset int // byte offset of the error in Ipa
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ipa: %s at offset %d in %q", e.Msg, e.Offset, e.Ipa)
+}
+
+// Parse tokenizes an IPA transcription. Surrounding slashes or square
+// brackets set the [Notation] and are removed. Parenthesized segments
+// are kept with a non-zero [Token.Group]; use [Transcription.Variants]
+// to expand them.
+func Parse(s string) (*Transcription, error) {
+	t := &Transcription{}
+	body, offset := s, 0
+	if trimmed := strings.TrimSpace(s); len(trimmed) >= 2 {
+		first, last := trimmed[0], trimmed[len(trimmed)-1]
+		switch {
+		case first == '/' && last == '/':
+			t.Notation = Phonemic
+		case first == '[' && last == ']':
+			t.Notation = Phonetic
+		}
+		if t.Notation != Unspecified {
+			offset = strings.Index(s, trimmed) + 1
+			body = trimmed[1 : len(trimmed)-1]
+		}
+	}
+
+	group := 0
+	groupStart := 0
+	tied := false
+	prefix := "" // modifiers seen before any phoneme, e.g. prenasalization
+	for i, r := range body {
+		switch {
+		case r == 'ˈ' || r == '\'':
+			t.Tokens = append(t.Tokens, Token{Kind: PrimaryStress, Text: "ˈ", Group: group})
+		case r == 'ˌ':
+			t.Tokens = append(t.Tokens, Token{Kind: SecondaryStress, Text: "ˌ", Group: group})
+		case r == '.':
+			t.Tokens = append(t.Tokens, Token{Kind: SyllableBreak, Text: ".", Group: group})
+		case r == '‿':
+			t.Tokens = append(t.Tokens, Token{Kind: Linking, Text: "‿", Group: group})
+		case r == ' ' || r == '|' || r == '‖':
+			t.Tokens = append(t.Tokens, Token{Kind: WordBoundary, Text: string(r), Group: group})
+		case r == '-' || r == ',':
+			// affix hyphens and separators carry no sound
+		case r == '(':
+			if group != 0 {
+				return nil, &SyntaxError{Ipa: s, Offset: offset + i, Msg: "nested parentheses"}
+			}
+			t.Groups++
+			group, groupStart = t.Groups, offset+i
+		case r == ')':
+			if group == 0 {
+				return nil, &SyntaxError{Ipa: s, Offset: offset + i, Msg: "unmatched ')'"}
+			}
+			group = 0
+		case r == '͡' || r == '͜':
+			last := t.lastPhoneme()
+			if last == nil {
+				return nil, &SyntaxError{Ipa: s, Offset: offset + i, Msg: "tie bar without a preceding phoneme"}
+			}
+			last.Text += string(r)
+			tied = true
+		case isModifier(r):
+			last := t.lastPhoneme()
+			switch {
+			case last == nil:
+				prefix += string(r)
+			case last.Group != group:
+				t.Tokens = append(t.Tokens, Token{Kind: Modifier, Text: string(r), Group: group})
+			default:
+				last.Text += string(r)
+			}
+		default:
+			if last := t.lastPhoneme(); tied && last != nil {
+				last.Text += string(r)
+			} else {
+				t.Tokens = append(t.Tokens, Token{Kind: Phoneme, Text: prefix + string(r), Group: group})
+				prefix = ""
+			}
+			tied = false
+		}
+	}
+	if group != 0 {
+		return nil, &SyntaxError{Ipa: s, Offset: groupStart, Msg: "unclosed '('"}
+	}
+	if tied {
+		return nil, &SyntaxError{Ipa: s, Offset: offset + len(body), Msg: "dangling tie bar"}
+	}
+	if prefix != "" {
+		t.Tokens = append(t.Tokens, Token{Kind: Phoneme, Text: prefix})
+	}
+	return t, nil
+}
+
+// lastPhoneme returns the last Phoneme token, provided no boundary or
+// stress mark follows it.
+func (t *Transcription) lastPhoneme() *Token {
+	for i := len(t.Tokens) - 1; i >= 0; i-- {
+		switch t.Tokens[i].Kind {
+		case Phoneme:
+			return &t.Tokens[i]
+		case Modifier:
+			continue
+		}
+		return nil
+	}
+	return nil
+}
+
+// isModifier reports whether `r` modifies the preceding phoneme:
+// combining diacritics, length marks, and superscript letters such as
+// aspiration ʰ or labialization ʷ.
+func isModifier(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Lm, unicode.Sk) || r == 'ʼ'
+}
+
+// Phonemes returns the text of every Phoneme token, optional ones
+// included.
+func (t *Transcription) Phonemes() []string {
+	var phonemes []string
+	for _, tok := range t.Tokens {
+		if tok.Kind == Phoneme {
+			phonemes = append(phonemes, tok.Text)
+		}
+	}
+	return phonemes
+}
+
+// String formats the transcription back, with its delimiters and
+// optional groups.
+func (t *Transcription) String() string {
+	var sb strings.Builder
+	switch t.Notation {
+	case Phonemic:
+		sb.WriteByte('/')
+	case Phonetic:
+		sb.WriteByte('[')
+	}
+	group := 0
+	for _, tok := range t.Tokens {
+		if tok.Group != group {
+			if group != 0 {
+				sb.WriteByte(')')
+			}
+			if tok.Group != 0 {
+				sb.WriteByte('(')
+			}
+			group = tok.Group
+		}
+		sb.WriteString(tok.Text)
+	}
+	if group != 0 {
+		sb.WriteByte(')')
+	}
+	switch t.Notation {
+	case Phonemic:
+		sb.WriteByte('/')
+	case Phonetic:
+		sb.WriteByte(']')
+	}
+	return sb.String()
+}
+
+// MAX_VARIANT_GROUPS is the number of optional groups above which
+// [Transcription.Variants] yields only the first and last variants.
+const MAX_VARIANT_GROUPS int = 8
+
+// Variants expands the optional groups. The first variant keeps every
+// optional segment and the last one drops them all, so "/ˈwɔːtə(ɹ)/"
+// yields /ˈwɔːtəɹ/ and /ˈwɔːtə/. Transcriptions without optional groups
+// yield a single variant, and those with more than MAX_VARIANT_GROUPS
+// only the first and the last.
+func (t *Transcription) Variants() []*Transcription {
+	if t.Groups == 0 {
+		return []*Transcription{t.variant(func(int) bool { return true })}
+	}
+	if t.Groups > MAX_VARIANT_GROUPS {
+		return []*Transcription{
+			t.variant(func(int) bool { return true }),
+			t.variant(func(int) bool { return false }),
+		}
+	}
+	n := 1 << t.Groups
+	variants := make([]*Transcription, 0, n)
+	for mask := n - 1; mask >= 0; mask-- {
+		// group g is kept when bit (Groups - g) is set, so that the
+		// first groups are dropped last
+		variants = append(variants, t.variant(func(g int) bool { return mask&(1<<(t.Groups-g)) != 0 }))
+	}
+	return variants
+}
+
+// variant returns the transcription keeping the optional groups for
+// which `keep` is true.
+func (t *Transcription) variant(keep func(group int) bool) *Transcription {
+	v := &Transcription{Notation: t.Notation}
+	for _, tok := range t.Tokens {
+		if tok.Group != 0 && !keep(tok.Group) {
+			continue
+		}
+		tok.Group = 0
+		if tok.Kind == Modifier {
+			if last := v.lastPhoneme(); last != nil {
+				last.Text += tok.Text
+				continue
+			}
+			tok.Kind = Phoneme
+		}
+		v.Tokens = append(v.Tokens, tok)
+	}
+	return v
+}
@@ -0,0 +1,155 @@
+package ipa_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/ipa"
+)
+
+// formatSyllables renders syllables as "ˈwɔː.təɹ".
+func formatSyllables(syllables []ipa.Syllable) string {
+	var parts []string
+	for _, s := range syllables {
+		var sb strings.Builder
+		switch s.Stress {
+		case ipa.Primary:
+			sb.WriteString("ˈ")
+		case ipa.Secondary:
+			sb.WriteString("ˌ")
+		}
+		for _, p := range s.Phonemes {
+			sb.WriteString(p.Text)
+		}
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, ".")
+}
+
+func TestParse(t *testing.T) {
+	// transcriptions taken from English Wiktionary entries
+	tests := []struct {
+		word      string
+		ipa       string
+		notation  ipa.Notation
+		phonemes  string
+		syllables string
+		variants  []string
+	}{
+		{"water", "/ˈwɔːtə(ɹ)/", ipa.Phonemic, "w ɔː t ə ɹ", "ˈwɔː.təɹ", []string{"/ˈwɔːtəɹ/", "/ˈwɔːtə/"}},
+		{"water", "[ˈwɔɾɚ]", ipa.Phonetic, "w ɔ ɾ ɚ", "ˈwɔ.ɾɚ", nil},
+		{"church", "/ˈt͡ʃɜːt͡ʃ/", ipa.Phonemic, "t͡ʃ ɜː t͡ʃ", "ˈt͡ʃɜːt͡ʃ", nil},
+		{"information", "/ˌɪn.fəˈmeɪ.ʃən/", ipa.Phonemic, "ɪ n f ə m e ɪ ʃ ə n", "ˌɪn.fə.ˈmeɪ.ʃən", nil},
+		{"dictionary", "/ˈdɪkʃ(ə)n(ə)ɹi/", ipa.Phonemic, "d ɪ k ʃ ə n ə ɹ i", "ˈdɪk.ʃə.nə.ɹi",
+			[]string{"/ˈdɪkʃənəɹi/", "/ˈdɪkʃənɹi/", "/ˈdɪkʃnəɹi/", "/ˈdɪkʃnɹi/"}},
+		{"cat", "[ˈkʰæt]", ipa.Phonetic, "kʰ æ t", "ˈkʰæt", nil},
+		{"button", "/ˈbʌt.n̩/", ipa.Phonemic, "b ʌ t n̩", "ˈbʌt.n̩", nil},
+		{"new", "/ˈnjuː/", ipa.Phonemic, "n j uː", "ˈnjuː", nil},
+		{"good morning", "/ˈɡʊd ˈmɔːnɪŋ/", ipa.Phonemic, "ɡ ʊ d m ɔː n ɪ ŋ", "ˈɡʊd.ˈmɔː.nɪŋ", nil},
+		{"bon", "/bɔ̃/", ipa.Phonemic, "b ɔ̃", "bɔ̃", nil},
+		{"Pferd", "/p͡feːɐ̯t/", ipa.Phonemic, "p͡f eː ɐ̯ t", "p͡feːɐ̯t", nil},
+		{"strength", "/stɹɛŋ(k)θ/", ipa.Phonemic, "s t ɹ ɛ ŋ k θ", "stɹɛŋkθ", []string{"/stɹɛŋkθ/", "/stɹɛŋθ/"}},
+		{"Rhymes:English/ɑːtəʊ", "-ɑːtəʊ", ipa.Unspecified, "ɑː t ə ʊ", "ɑː.təʊ", []string{"ɑːtəʊ"}},
+	}
+	for _, tt := range tests {
+		tr, err := ipa.Parse(tt.ipa)
+		if err != nil {
+			t.Errorf("%s: Parse(%q): %v", tt.word, tt.ipa, err)
+			continue
+		}
+		if tr.Notation != tt.notation {
+			t.Errorf("%s: notation = %v, want %v", tt.word, tr.Notation, tt.notation)
+		}
+		if got := strings.Join(tr.Phonemes(), " "); got != tt.phonemes {
+			t.Errorf("%s: phonemes = %q, want %q", tt.word, got, tt.phonemes)
+		}
+		if got := formatSyllables(tr.Syllables()); got != tt.syllables {
+			t.Errorf("%s: syllables = %q, want %q", tt.word, got, tt.syllables)
+		}
+		if tt.variants == nil {
+			tt.variants = []string{tt.ipa}
+		}
+		var variants []string
+		for _, v := range tr.Variants() {
+			variants = append(variants, v.String())
+		}
+		if !slices.Equal(variants, tt.variants) {
+			t.Errorf("%s: variants = %q, want %q", tt.word, variants, tt.variants)
+		}
+		if want := tt.variants[0]; tr.Groups == 0 && tr.String() != want {
+			t.Errorf("%s: String() = %q, want %q", tt.word, tr.String(), want)
+		}
+	}
+}
+
+func TestOptionalModifier(t *testing.T) {
+	tr, err := ipa.Parse("[ˈpʰɒt(ʰ)]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(tr.Phonemes(), " "); got != "pʰ ɒ t" {
+		t.Errorf("phonemes = %q", got)
+	}
+	var variants []string
+	for _, v := range tr.Variants() {
+		variants = append(variants, strings.Join(v.Phonemes(), " "))
+	}
+	if want := []string{"pʰ ɒ tʰ", "pʰ ɒ t"}; !slices.Equal(variants, want) {
+		t.Errorf("variants = %q, want %q", variants, want)
+	}
+}
+
+func TestManyGroups(t *testing.T) {
+	for _, n := range []int{ipa.MAX_VARIANT_GROUPS, ipa.MAX_VARIANT_GROUPS + 1, 63, 64, 70} {
+		tr, err := ipa.Parse("/" + strings.Repeat("a(b)", n) + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		variants := tr.Variants()
+		want := 2
+		if n <= ipa.MAX_VARIANT_GROUPS {
+			want = 1 << n
+		}
+		if len(variants) != want {
+			t.Fatalf("%d groups: %d variants, want %d", n, len(variants), want)
+		}
+		first, last := variants[0].String(), variants[len(variants)-1].String()
+		if first != "/"+strings.Repeat("ab", n)+"/" || last != "/"+strings.Repeat("a", n)+"/" {
+			t.Errorf("%d groups: variants %s … %s", n, first, last)
+		}
+	}
+}
+
+func TestTokenClasses(t *testing.T) {
+	tr, err := ipa.Parse("/ˈbʌt.n̩/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vowels, syllabic []string
+	for _, tok := range tr.Tokens {
+		if tok.IsVowel() {
+			vowels = append(vowels, tok.Text)
+		}
+		if tok.IsSyllabic() {
+			syllabic = append(syllabic, tok.Text)
+		}
+	}
+	if !slices.Equal(vowels, []string{"ʌ"}) || !slices.Equal(syllabic, []string{"ʌ", "n̩"}) {
+		t.Errorf("vowels = %q, syllabic = %q", vowels, syllabic)
+	}
+	if kinds := []ipa.Kind{tr.Tokens[0].Kind, tr.Tokens[4].Kind}; !slices.Equal(kinds, []ipa.Kind{ipa.PrimaryStress, ipa.SyllableBreak}) {
+		t.Errorf("kinds = %v", kinds)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{"/ab(c/", "/a)b/", "/a((b))/", "/͡a/", "/t͡/"} {
+		_, err := ipa.Parse(s)
+		var syntaxErr *ipa.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q): got %v, want a *SyntaxError", s, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ipa
+
+// Stress is the stress level of a [Syllable].
+type Stress int
+
+const (
+	Unstressed Stress = iota
+	Primary
+	Secondary
+)
+
+// Syllable is a group of phonemes with a single nucleus.
+type Syllable struct {
+	Stress   Stress
+	Phonemes []Token
+}
+
+// Nucleus returns the index in Phonemes of the first syllabic phoneme,
+// or -1 if there is none.
+func (s Syllable) Nucleus() int {
+	for i, p := range s.Phonemes {
+		if p.IsSyllabic() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Syllables splits the transcription into syllables. Explicit syllable
+// breaks, stress marks and word boundaries always start a new syllable;
+// runs of phonemes holding several nuclei are split further, giving a
+// single consonant between two nuclei to the following syllable and
+// keeping the first of a cluster in the coda. Optional tokens are
+// included; syllabify one of [Transcription.Variants] to leave them out.
+func (t *Transcription) Syllables() []Syllable {
+	var syllables []Syllable
+	cur := Syllable{}
+	flush := func() {
+		if len(cur.Phonemes) > 0 {
+			syllables = append(syllables, splitNuclei(cur)...)
+		}
+		cur = Syllable{}
+	}
+	for _, tok := range t.Tokens {
+		switch tok.Kind {
+		case Phoneme:
+			cur.Phonemes = append(cur.Phonemes, tok)
+		case Modifier:
+			if n := len(cur.Phonemes); n > 0 {
+				cur.Phonemes[n-1].Text += tok.Text
+			}
+		case PrimaryStress:
+			flush()
+			cur.Stress = Primary
+		case SecondaryStress:
+			flush()
+			cur.Stress = Secondary
+		case SyllableBreak, WordBoundary:
+			flush()
+		}
+	}
+	flush()
+	return syllables
+}
+
+// splitNuclei splits a run of phonemes holding more than one nucleus.
+// Adjacent syllabic phonemes (e.g. the two halves of a diphthong written
+// without a tie bar) count as a single nucleus.
+func splitNuclei(s Syllable) []Syllable {
+	var nuclei []int
+	for i, p := range s.Phonemes {
+		if p.IsSyllabic() && (i == 0 || !s.Phonemes[i-1].IsSyllabic()) {
+			nuclei = append(nuclei, i)
+		}
+	}
+	if len(nuclei) <= 1 {
+		return []Syllable{s}
+	}
+
+	var syllables []Syllable
+	start := 0
+	for k := 1; k < len(nuclei); k++ {
+		// end of the previous nucleus
+		end := nuclei[k-1] + 1
+		for end < nuclei[k] && s.Phonemes[end].IsSyllabic() {
+			end++
+		}
+		consonants := nuclei[k] - end
+		split := end
+		if consonants > 1 {
+			split = end + 1
+		}
+		syllables = append(syllables, Syllable{Phonemes: s.Phonemes[start:split]})
+		start = split
+	}
+	syllables = append(syllables, Syllable{Phonemes: s.Phonemes[start:]})
+	syllables[0].Stress = s.Stress
+	return syllables
+}
@@ -0,0 +1,341 @@
+// Package jsonlindex gives random access to the entries of a JSONL dump
+// through a sidecar index of their byte offsets, so that a few entries
+// can be read from a multi-gigabyte file without scanning it.
+//
+// The index maps the key of every entry, its headword, language code,
+// part of speech and etymology number, to the offset and length of its
+// line in the uncompressed data. It is built by one scan of the dump
+// and written next to it with the extension ".idx".
+//
+// Gzip dumps are read from checkpoints: the starts of their gzip
+// members, which can be decompressed independently. A dump compressed
+// as a single member, as gzip and pigz do, has a single checkpoint and
+// every read decompresses it from the start; [Recompress] rewrites it
+// as members of about a megabyte, which any gzip reader still reads as
+// one stream. Checkpoints inside a member, as in zlib's zran example,
+// would need the decompressor state that compress/flate does not
+// expose.
+//
+// An index file is laid out as follows; integers are little-endian.
+//
+//	header       MAGIC, version uint32, flags uint32 (1 for gzip)
+//	keys         uvarint length and bytes of each key:
+//	             word "\x00" lang_code "\x00" pos
+//	entries      offset uint64, key offset uint64, length uint32 and
+//	             etymology number uint32 of each entry, sorted by key,
+//	             etymology number and offset
+//	checkpoints  uncompressed offset uint64 and compressed offset
+//	             uint64 of each gzip member
+//	footer       entry count, checkpoint count, offsets of the entries
+//	             and checkpoints sections, size of the dump and
+//	             fingerprint of the dump, all uint64; MAGIC
+//
+// The fingerprint is the first 8 bytes of the SHA-256 of the first and
+// last blocks of FINGERPRINT_BLOCK bytes of the dump, as stored, so that
+// a dump replaced by another of the same size is not read with a stale
+// index.
+package jsonlindex
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+const (
+	MAGIC   string = "WIKTIDX\x00"
+	VERSION uint32 = 2
+
+	FINGERPRINT_BLOCK int = 64 << 10
+
+	flagGzip uint32 = 1
+
+	// sizes in bytes
+	headerSize     = 16
+	footerSize     = 56
+	entrySize      = 24
+	checkpointSize = 16
+)
+
+// Key identifies an entry. Wiktionary pages have a section per
+// language and part of speech, numbered when a word has several
+// etymologies.
+type Key struct {
+	Word     string
+	LangCode string
+	Pos      string
+	// 0 if the entry is not numbered
+	EtymologyNumber int
+}
+
+// Entry locates an entry in the uncompressed dump.
+type Entry struct {
+	Key
+	Offset int64
+	// length of the line, without the line feed
+	Length int
+}
+
+// checkpoint is the start of a gzip member.
+type checkpoint struct {
+	uncompressed int64
+	compressed   int64
+}
+
+// keyFields are the fields decoded to index an entry.
+type keyFields struct {
+	Word            string `json:"word"`
+	LangCode        string `json:"lang_code"`
+	Pos             string `json:"pos"`
+	EtymologyNumber int    `json:"etymology_number"`
+}
+
+// IndexName returns the name of the index of the dump `name`.
+func IndexName(name string) string {
+	return name + ".idx"
+}
+
+// BuildFile indexes the dump `name`, plain or gzip-compressed, and
+// writes the index to [IndexName](name).
+func BuildFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	out, err := os.Create(IndexName(name))
+	if err != nil {
+		return err
+	}
+	if err := Build(out, f); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return out.Close()
+}
+
+// Build scans the dump read from `r`, plain or gzip-compressed, and
+// writes its index to `w`.
+func Build(w io.Writer, r io.Reader) error {
+	cr := &countingReader{r: bufio.NewReaderSize(r, 1<<20)}
+	magic, _ := cr.r.Peek(3)
+	var data io.Reader = cr
+	var mr *memberReader
+	flags := uint32(0)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		z, err := gzip.NewReader(cr)
+		if err != nil {
+			return err
+		}
+		z.Multistream(false)
+		mr = &memberReader{z: z, cr: cr, checkpoints: []checkpoint{{0, 0}}}
+		data = mr
+		flags = flagGzip
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return errors.New("bzip2 dumps cannot be read at random; decompress them or recompress them with gzip")
+	}
+
+	var entries []Entry
+	br := bufio.NewReaderSize(data, 1<<20)
+	var off int64
+	for n := 1; ; n++ {
+		line, err := readLine(br)
+		if len(line) > 0 {
+			length := len(line)
+			if line[length-1] == '\n' {
+				length--
+			}
+			if trimmed := bytes.TrimSpace(line[:length]); len(trimmed) > 0 {
+				var k keyFields
+				if err := json.Unmarshal(trimmed, &k); err != nil {
+					return fmt.Errorf("line %d: %w", n, err)
+				}
+				entries = append(entries, Entry{
+					Key:    Key{k.Word, k.LangCode, k.Pos, k.EtymologyNumber},
+					Offset: off,
+					Length: length,
+				})
+			}
+			off += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var checkpoints []checkpoint
+	if mr != nil {
+		checkpoints = mr.checkpoints
+	}
+	return write(w, flags, entries, checkpoints, cr.n, fingerprint(cr.head, cr.tail[max(0, len(cr.tail)-FINGERPRINT_BLOCK):]))
+}
+
+// readLine returns the next line with its line feed, however long.
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err != bufio.ErrBufferFull {
+		return line, err
+	}
+	buf := bytes.Clone(line)
+	for err == bufio.ErrBufferFull {
+		line, err = br.ReadSlice('\n')
+		buf = append(buf, line...)
+	}
+	return buf, err
+}
+
+// encodeKey returns the key of the index, which sorts by word, language
+// code and part of speech.
+func encodeKey(k Key) string {
+	return k.Word + "\x00" + k.LangCode + "\x00" + k.Pos
+}
+
+func write(w io.Writer, flags uint32, entries []Entry, checkpoints []checkpoint, size int64, fp uint64) error {
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(encodeKey(a.Key), encodeKey(b.Key)),
+			cmp.Compare(a.EtymologyNumber, b.EtymologyNumber),
+			cmp.Compare(a.Offset, b.Offset),
+		)
+	})
+	bw := bufio.NewWriterSize(w, 1<<16)
+	var b []byte
+	b = append(b, MAGIC...)
+	b = binary.LittleEndian.AppendUint32(b, VERSION)
+	b = binary.LittleEndian.AppendUint32(b, flags)
+	bw.Write(b)
+
+	// keys shared by consecutive entries are written once
+	keyOffs := make([]uint64, len(entries))
+	off := uint64(headerSize)
+	last := ""
+	for i, e := range entries {
+		k := encodeKey(e.Key)
+		if i > 0 && k == last {
+			keyOffs[i] = keyOffs[i-1]
+			continue
+		}
+		last = k
+		keyOffs[i] = off
+		b = binary.AppendUvarint(b[:0], uint64(len(k)))
+		b = append(b, k...)
+		bw.Write(b)
+		off += uint64(len(b))
+	}
+
+	entriesOff := off
+	for i, e := range entries {
+		b = binary.LittleEndian.AppendUint64(b[:0], uint64(e.Offset))
+		b = binary.LittleEndian.AppendUint64(b, keyOffs[i])
+		b = binary.LittleEndian.AppendUint32(b, uint32(e.Length))
+		b = binary.LittleEndian.AppendUint32(b, uint32(e.EtymologyNumber))
+		bw.Write(b)
+	}
+	checkpointsOff := entriesOff + uint64(len(entries)*entrySize)
+	for _, c := range checkpoints {
+		b = binary.LittleEndian.AppendUint64(b[:0], uint64(c.uncompressed))
+		b = binary.LittleEndian.AppendUint64(b, uint64(c.compressed))
+		bw.Write(b)
+	}
+
+	b = b[:0]
+	for _, x := range []uint64{uint64(len(entries)), uint64(len(checkpoints)), entriesOff, checkpointsOff, uint64(size), fp} {
+		b = binary.LittleEndian.AppendUint64(b, x)
+	}
+	b = append(b, MAGIC...)
+	bw.Write(b)
+	return bw.Flush()
+}
+
+// fingerprint hashes the first and last blocks of a dump.
+func fingerprint(head, tail []byte) uint64 {
+	h := sha256.New()
+	h.Write(head)
+	h.Write(tail)
+	return binary.LittleEndian.Uint64(h.Sum(nil))
+}
+
+// countingReader counts the bytes read and keeps the first and last
+// blocks for the fingerprint. As it is a byte reader, gzip and flate
+// read from it exactly up to the end of a member.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+	// the first block, and at least the last one
+	head, tail []byte
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	c.keep(p[:n])
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+		c.keep([]byte{b})
+	}
+	return b, err
+}
+
+func (c *countingReader) keep(p []byte) {
+	if len(c.head) < FINGERPRINT_BLOCK {
+		c.head = append(c.head, p[:min(len(p), FINGERPRINT_BLOCK-len(c.head))]...)
+	}
+	if len(c.tail)+len(p) > 2*FINGERPRINT_BLOCK {
+		// keep the last block only, copying it once per block read
+		c.tail = append(c.tail[:0], c.tail[max(0, len(c.tail)-FINGERPRINT_BLOCK):]...)
+		if len(p) > FINGERPRINT_BLOCK {
+			p = p[len(p)-FINGERPRINT_BLOCK:]
+		}
+	}
+	c.tail = append(c.tail, p...)
+}
+
+// memberReader decompresses the members of a gzip stream one by one,
+// recording where each starts.
+type memberReader struct {
+	z           *gzip.Reader
+	cr          *countingReader
+	n           int64
+	checkpoints []checkpoint
+	done        bool
+}
+
+func (m *memberReader) Read(p []byte) (int, error) {
+	if m.done {
+		return 0, io.EOF
+	}
+	n, err := m.z.Read(p)
+	m.n += int64(n)
+	if err != io.EOF {
+		return n, err
+	}
+	start := m.cr.n
+	if err := m.z.Reset(m.cr); err == io.EOF {
+		m.done = true
+		return n, io.EOF
+	} else if err != nil {
+		return n, err
+	}
+	m.z.Multistream(false)
+	m.checkpoints = append(m.checkpoints, checkpoint{m.n, start})
+	return n, nil
+}
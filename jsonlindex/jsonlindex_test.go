@@ -0,0 +1,237 @@
+package jsonlindex_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/jsonlindex"
+)
+
+// dump returns a dump of `n` filler entries with a few real ones among
+// them.
+func dump(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		switch i {
+		case n / 3:
+			sb.WriteString(`{"word":"bank","lang":"English","lang_code":"en","pos":"noun","etymology_number":1,"senses":[{"glosses":["An institution where one can place money."]}]}` + "\n")
+			sb.WriteString(`{"word":"bank","lang":"English","lang_code":"en","pos":"noun","etymology_number":2,"senses":[{"glosses":["An edge of river."]}]}` + "\r\n")
+		case n / 2:
+			sb.WriteString(`{"word":"bank","lang":"English","lang_code":"en","pos":"verb","etymology_number":1,"senses":[{"glosses":["To deposit in a bank."]}]}` + "\n\n")
+			sb.WriteString(`{"word":"bank","lang":"Dutch","lang_code":"nl","pos":"noun","senses":[{"glosses":["couch"]}]}` + "\n")
+		}
+		fmt.Fprintf(&sb, `{"word":"word%04d","lang":"English","lang_code":"en","pos":"noun","senses":[{"glosses":["Filler number %d."]}]}`+"\n", i, i)
+	}
+	// no final line feed
+	sb.WriteString(`{"word":"zebra","lang":"English","lang_code":"en","pos":"noun"}`)
+	return sb.String()
+}
+
+func gzipped(data string) []byte {
+	var buf bytes.Buffer
+	z := gzip.NewWriter(&buf)
+	z.Write([]byte(data))
+	z.Close()
+	return buf.Bytes()
+}
+
+func recompressed(t *testing.T, data string, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jsonlindex.Recompress(&buf, strings.NewReader(data), size); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// open writes `data` as a dump, indexes it and opens it.
+func open(t *testing.T, data []byte) *jsonlindex.Reader {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "dump.jsonl")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := jsonlindex.BuildFile(name); err != nil {
+		t.Fatal(err)
+	}
+	r, err := jsonlindex.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestLookup(t *testing.T) {
+	data := dump(3000)
+	files := map[string][]byte{
+		"plain":        []byte(data),
+		"gzip":         gzipped(data),
+		"recompressed": recompressed(t, data, 16<<10),
+	}
+	tests := []struct {
+		key  jsonlindex.Key
+		want []string
+	}{
+		{jsonlindex.Key{Word: "bank"}, []string{"en/noun/1", "en/noun/2", "en/verb/1", "nl/noun/0"}},
+		{jsonlindex.Key{Word: "bank", LangCode: "en"}, []string{"en/noun/1", "en/noun/2", "en/verb/1"}},
+		{jsonlindex.Key{Word: "bank", LangCode: "en", Pos: "noun"}, []string{"en/noun/1", "en/noun/2"}},
+		{jsonlindex.Key{Word: "bank", Pos: "noun"}, []string{"en/noun/1", "en/noun/2", "nl/noun/0"}},
+		{jsonlindex.Key{Word: "bank", EtymologyNumber: 1}, []string{"en/noun/1", "en/verb/1"}},
+		{jsonlindex.Key{Word: "bank", LangCode: "en", Pos: "noun", EtymologyNumber: 2}, []string{"en/noun/2"}},
+		{jsonlindex.Key{Word: "bank", LangCode: "fr"}, nil},
+		{jsonlindex.Key{Word: "ban"}, nil},
+		{jsonlindex.Key{Word: "word2999"}, []string{"en/noun/0"}},
+		{jsonlindex.Key{Word: "zebra"}, []string{"en/noun/0"}},
+	}
+	for name, file := range files {
+		r := open(t, file)
+		if r.Len() != 3005 {
+			t.Errorf("%s: %d entries", name, r.Len())
+		}
+		for _, tt := range tests {
+			words, err := r.Lookup(tt.key)
+			if err != nil {
+				t.Fatalf("%s: %+v: %v", name, tt.key, err)
+			}
+			var got []string
+			for _, w := range words {
+				n := 0
+				if w.EtymologyNumber != nil {
+					n = *w.EtymologyNumber
+				}
+				got = append(got, fmt.Sprintf("%s/%s/%d", w.LangCode, w.Pos, n))
+				if w.Word != tt.key.Word {
+					t.Errorf("%s: %+v: got %q", name, tt.key, w.Word)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("%s: Lookup(%+v) = %v, want %v", name, tt.key, got, tt.want)
+			}
+		}
+
+		// lines are returned as they are in the dump
+		entries, err := r.Find(jsonlindex.Key{Word: "bank", LangCode: "en", Pos: "noun", EtymologyNumber: 2})
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("%s: %v, %v", name, entries, err)
+		}
+		raw, err := r.Raw(entries[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(raw), `{"word":"bank"`) || !strings.HasSuffix(string(raw), "}\r") {
+			t.Errorf("%s: raw = %q", name, raw)
+		}
+	}
+}
+
+func TestRecompress(t *testing.T) {
+	data := dump(3000)
+	z := recompressed(t, data, 16<<10)
+
+	// members end at line boundaries, and the stream reads as one
+	var members []string
+	zr, err := gzip.NewReader(bytes.NewReader(z))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr.Multistream(false)
+	br := bytes.NewReader(z)
+	zr.Reset(br)
+	for {
+		zr.Multistream(false)
+		m, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		members = append(members, string(m))
+		if err := zr.Reset(br); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(members) < 10 {
+		t.Errorf("%d members", len(members))
+	}
+	for i, m := range members[:len(members)-1] {
+		if !strings.HasSuffix(m, "\n") || len(m) < 16<<10 {
+			t.Errorf("member %d: %d bytes ending with %q", i, len(m), m[len(m)-1:])
+		}
+	}
+	if strings.Join(members, "") != data {
+		t.Errorf("members do not add up to the data")
+	}
+}
+
+func TestStale(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "dump.jsonl")
+	data := dump(10)
+	os.WriteFile(name, []byte(data), 0o644)
+	if err := jsonlindex.BuildFile(name); err != nil {
+		t.Fatal(err)
+	}
+	for _, changed := range []string{
+		data + "\n",
+		// same size
+		strings.Replace(data, "word", "wort", 1),
+		data[:len(data)-3] + "}}\n",
+	} {
+		os.WriteFile(name, []byte(changed), 0o644)
+		if _, err := jsonlindex.Open(name); !errors.Is(err, jsonlindex.ErrStale) {
+			t.Errorf("Open = %v", err)
+		}
+	}
+
+	// a dump larger than two blocks changed at its end
+	large := dump(5000)
+	if len(large) < 3*jsonlindex.FINGERPRINT_BLOCK {
+		t.Fatalf("dump of %d bytes", len(large))
+	}
+	os.WriteFile(name, []byte(large), 0o644)
+	if err := jsonlindex.BuildFile(name); err != nil {
+		t.Fatal(err)
+	}
+	r, err := jsonlindex.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	os.WriteFile(name, []byte(large[:len(large)-2]+"]\n"), 0o644)
+	if _, err := jsonlindex.Open(name); !errors.Is(err, jsonlindex.ErrStale) {
+		t.Errorf("Open = %v", err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := jsonlindex.NewIndex([]byte(dump(10))); !errors.Is(err, jsonlindex.ErrFormat) {
+		t.Errorf("dump as index: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := jsonlindex.Build(&buf, strings.NewReader("{\"word\":\"a\"}\n{\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("invalid line: %v", err)
+	}
+
+	buf.Reset()
+	if err := jsonlindex.Build(&buf, strings.NewReader(dump(50))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for i := len(jsonlindex.MAGIC) + 8; i < len(data); i++ {
+		damaged := bytes.Clone(data)
+		damaged[i] ^= 0x5a
+		x, err := jsonlindex.NewIndex(damaged)
+		if err != nil {
+			continue
+		}
+		x.Find(jsonlindex.Key{Word: "bank"})
+		x.Find(jsonlindex.Key{Word: "word0042", LangCode: "en"})
+	}
+}
@@ -0,0 +1,291 @@
+package jsonlindex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/mmap"
+)
+
+var (
+	// ErrFormat is returned for files that are not indexes, or of an
+	// unsupported version.
+	ErrFormat = errors.New("jsonlindex: not an index file")
+	// ErrCorrupt is returned for damaged indexes.
+	ErrCorrupt = errors.New("jsonlindex: corrupt index")
+	// ErrStale is returned when the dump changed since it was indexed.
+	ErrStale = errors.New("jsonlindex: index does not match the dump")
+)
+
+// Index is a loaded index. Its methods may be called concurrently.
+type Index struct {
+	data  []byte
+	unmap func() error
+
+	gzip        bool
+	size        int64
+	fingerprint uint64
+	count       int
+	entries     []byte
+	checkpoints []checkpoint
+}
+
+// OpenIndex memory-maps the index file `name`.
+func OpenIndex(name string) (*Index, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, unmap, err := mmap.Map(f)
+	if err != nil {
+		return nil, err
+	}
+	x, err := NewIndex(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	x.unmap = unmap
+	return x, nil
+}
+
+// NewIndex reads an index held in memory.
+func NewIndex(data []byte) (*Index, error) {
+	if len(data) < headerSize+footerSize || string(data[:len(MAGIC)]) != MAGIC || string(data[len(data)-len(MAGIC):]) != MAGIC {
+		return nil, ErrFormat
+	}
+	if v := binary.LittleEndian.Uint32(data[len(MAGIC):]); v != VERSION {
+		return nil, fmt.Errorf("%w: version %d", ErrFormat, v)
+	}
+	var f [6]uint64
+	for i := range f {
+		f[i] = binary.LittleEndian.Uint64(data[len(data)-footerSize+8*i:])
+	}
+	count, ncheckpoints, entriesOff, checkpointsOff, size := f[0], f[1], f[2], f[3], f[4]
+	end := uint64(len(data) - footerSize)
+	if !(headerSize <= entriesOff && entriesOff <= checkpointsOff && checkpointsOff <= end) ||
+		count > (checkpointsOff-entriesOff)/entrySize || checkpointsOff-entriesOff != count*entrySize ||
+		ncheckpoints > (end-checkpointsOff)/checkpointSize || end-checkpointsOff != ncheckpoints*checkpointSize {
+		return nil, ErrCorrupt
+	}
+	x := &Index{
+		data:        data,
+		gzip:        binary.LittleEndian.Uint32(data[len(MAGIC)+4:])&flagGzip != 0,
+		size:        int64(size),
+		fingerprint: f[5],
+		count:       int(count),
+		entries:     data[entriesOff:checkpointsOff],
+	}
+	for i := range int(ncheckpoints) {
+		c := data[checkpointsOff+uint64(i*checkpointSize):]
+		x.checkpoints = append(x.checkpoints, checkpoint{
+			uncompressed: int64(binary.LittleEndian.Uint64(c)),
+			compressed:   int64(binary.LittleEndian.Uint64(c[8:])),
+		})
+	}
+	if x.gzip && (len(x.checkpoints) == 0 || x.checkpoints[0] != checkpoint{}) {
+		return nil, ErrCorrupt
+	}
+	return x, nil
+}
+
+// Close releases the memory mapping of an index opened with
+// [OpenIndex].
+func (x *Index) Close() error {
+	if x.unmap == nil {
+		return nil
+	}
+	err := x.unmap()
+	x.unmap = nil
+	return err
+}
+
+// Len returns the number of entries.
+func (x *Index) Len() int {
+	return x.count
+}
+
+// entry decodes the i-th entry with its key.
+func (x *Index) entry(i int) (Entry, error) {
+	b := x.entries[i*entrySize:]
+	keyOff := binary.LittleEndian.Uint64(b[8:])
+	if keyOff >= uint64(len(x.data)) {
+		return Entry{}, ErrCorrupt
+	}
+	n, size := binary.Uvarint(x.data[keyOff:])
+	if size <= 0 || n > uint64(len(x.data))-keyOff-uint64(size) {
+		return Entry{}, ErrCorrupt
+	}
+	start := keyOff + uint64(size)
+	k := strings.SplitN(string(x.data[start:start+n]), "\x00", 3)
+	if len(k) != 3 {
+		return Entry{}, ErrCorrupt
+	}
+	return Entry{
+		Key:    Key{k[0], k[1], k[2], int(binary.LittleEndian.Uint32(b[20:]))},
+		Offset: int64(binary.LittleEndian.Uint64(b)),
+		Length: int(binary.LittleEndian.Uint32(b[16:])),
+	}, nil
+}
+
+// Find returns the entries matching `k`, whose Word must be set; the
+// other fields match any entry when they are empty or 0.
+func (x *Index) Find(k Key) ([]Entry, error) {
+	// the sort key is a prefix of the keys of all matching entries, as
+	// far as the fields are set
+	prefix := k.Word + "\x00"
+	if k.LangCode != "" {
+		prefix += k.LangCode + "\x00"
+		if k.Pos != "" {
+			prefix += k.Pos
+		}
+	}
+	var err error
+	i := sort.Search(x.count, func(i int) bool {
+		e, eerr := x.entry(i)
+		if eerr != nil {
+			err = eerr
+			return true
+		}
+		return encodeKey(e.Key) >= prefix
+	})
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for ; i < x.count; i++ {
+		e, err := x.entry(i)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(encodeKey(e.Key), prefix) {
+			break
+		}
+		if k.Pos != "" && e.Pos != k.Pos || k.EtymologyNumber != 0 && e.EtymologyNumber != k.EtymologyNumber {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Reader reads entries of a dump at random. Its methods may be called
+// concurrently.
+type Reader struct {
+	f *os.File
+	*Index
+}
+
+// Open opens the dump `name` and its index, checking that the dump has
+// not changed since it was indexed: that it has the same size, first
+// block and last block.
+func Open(name string) (*Reader, error) {
+	x, err := OpenIndex(IndexName(name))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		x.Close()
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err == nil && fi.Size() != x.size {
+		err = fmt.Errorf("%s: %w", name, ErrStale)
+	}
+	if err == nil {
+		var fp uint64
+		fp, err = fileFingerprint(f, x.size)
+		if err == nil && fp != x.fingerprint {
+			err = fmt.Errorf("%s: %w", name, ErrStale)
+		}
+	}
+	if err != nil {
+		f.Close()
+		x.Close()
+		return nil, err
+	}
+	return &Reader{f: f, Index: x}, nil
+}
+
+// fileFingerprint returns the fingerprint of the dump `f` of `size`
+// bytes.
+func fileFingerprint(f *os.File, size int64) (uint64, error) {
+	n := min(size, int64(FINGERPRINT_BLOCK))
+	head, tail := make([]byte, n), make([]byte, n)
+	if _, err := f.ReadAt(head, 0); err != nil {
+		return 0, err
+	}
+	if _, err := f.ReadAt(tail, size-n); err != nil {
+		return 0, err
+	}
+	return fingerprint(head, tail), nil
+}
+
+// Close closes the dump and the index.
+func (r *Reader) Close() error {
+	err := r.f.Close()
+	if xerr := r.Index.Close(); err == nil {
+		err = xerr
+	}
+	return err
+}
+
+// Raw returns the line of `e`, without its line feed. Lines of gzip
+// dumps are decompressed from the last checkpoint before them.
+func (r *Reader) Raw(e Entry) ([]byte, error) {
+	line := make([]byte, e.Length)
+	if !r.gzip {
+		if _, err := r.f.ReadAt(line, e.Offset); err != nil {
+			return nil, err
+		}
+		return line, nil
+	}
+
+	i := sort.Search(len(r.checkpoints), func(i int) bool {
+		return r.checkpoints[i].uncompressed > e.Offset
+	}) - 1
+	c := r.checkpoints[i]
+	sr := io.NewSectionReader(r.f, c.compressed, r.size-c.compressed)
+	z, err := gzip.NewReader(bufio.NewReader(sr))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, z, e.Offset-c.uncompressed); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(z, line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Lookup decodes the entries matching `k`, as [Index.Find] does.
+func (r *Reader) Lookup(k Key) ([]*en.WordData, error) {
+	entries, err := r.Find(k)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]*en.WordData, len(entries))
+	for i, e := range entries {
+		line, err := r.Raw(e)
+		if err != nil {
+			return nil, err
+		}
+		words[i] = new(en.WordData)
+		if err := json.Unmarshal(line, words[i]); err != nil {
+			return nil, fmt.Errorf("offset %d: %w", e.Offset, err)
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,54 @@
+package jsonlindex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// MEMBER_SIZE is the default uncompressed size of the gzip members
+// written by [Recompress].
+const MEMBER_SIZE int = 1 << 20
+
+// Recompress writes the uncompressed dump read from `r` to `w` as a
+// gzip stream of members of at least `size` uncompressed bytes, or
+// [MEMBER_SIZE] if it is 0. Members end at line boundaries, so that
+// every entry is read from the start of its member.
+func Recompress(w io.Writer, r io.Reader, size int) error {
+	if size <= 0 {
+		size = MEMBER_SIZE
+	}
+	br := bufio.NewReaderSize(r, 1<<20)
+	bw := bufio.NewWriterSize(w, 1<<16)
+	z, err := gzip.NewWriterLevel(bw, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for {
+		line, err := readLine(br)
+		if len(line) > 0 {
+			if n >= size {
+				if err := z.Close(); err != nil {
+					return err
+				}
+				z.Reset(bw)
+				n = 0
+			}
+			if _, err := z.Write(line); err != nil {
+				return err
+			}
+			n += len(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
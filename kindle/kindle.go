@@ -0,0 +1,263 @@
+// Package kindle exports [en.WordData] as an EPUB 3 dictionary with
+// Amazon's idx: lookup markup, which Kindle Previewer and kindlegen
+// convert into a Kindle dictionary.
+package kindle
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+const (
+	// namespace of the idx: lookup markup
+	IDX   string = "https://kindlegen.s3.amazonaws.com/AmazonKindlePublishingGuidelines.pdf"
+	XHTML string = "http://www.w3.org/1999/xhtml"
+	EPUB  string = "http://www.idpf.org/2007/ops"
+	OPF   string = "http://www.idpf.org/2007/opf"
+	DC    string = "http://purl.org/dc/elements/1.1/"
+)
+
+// Options configures a [Writer].
+type Options struct {
+	// defaults to "Wiktionary"
+	Title  string
+	Author string
+	// language of the headwords, defaults to the language of the first
+	// word added
+	Language string
+	// language of the definitions, defaults to "en"
+	TargetLanguage string
+	// dc:identifier, defaults to a URN derived from the title
+	Identifier string
+	// dcterms:modified, defaults to now
+	Modified time.Time
+	// number of entries per content document, 1000 by default
+	EntriesPerFile int
+	// do not add idx:infl forms
+	NoForms bool
+}
+
+// part is a content document of the book.
+type part struct {
+	name        string
+	first, last string
+}
+
+// Writer builds the EPUB archive. Content documents are written as
+// entries are added; the package document and navigation are written
+// by [Writer.Close].
+type Writer struct {
+	zw   *zip.Writer
+	opts Options
+
+	doc   io.Writer
+	count int
+	parts []part
+}
+
+// NewWriter starts an EPUB written to `w`. Closing the [Writer] does
+// not close `w`.
+func NewWriter(w io.Writer, opts Options) (*Writer, error) {
+	if opts.Title == "" {
+		opts.Title = "Wiktionary"
+	}
+	if opts.TargetLanguage == "" {
+		opts.TargetLanguage = "en"
+	}
+	if opts.Identifier == "" {
+		sum := sha256.Sum256([]byte(opts.Title))
+		opts.Identifier = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+	if opts.Modified.IsZero() {
+		opts.Modified = time.Now()
+	}
+	if opts.EntriesPerFile <= 0 {
+		opts.EntriesPerFile = 1000
+	}
+	kw := &Writer{zw: zip.NewWriter(w), opts: opts}
+	// the mimetype must come first and be stored uncompressed
+	f, err := kw.zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(f, "application/epub+zip"); err != nil {
+		return nil, err
+	}
+	if err := kw.writeFile("META-INF/container.xml", container); err != nil {
+		return nil, err
+	}
+	return kw, nil
+}
+
+func (w *Writer) writeFile(name, content string) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// Add writes `word` as an idx:entry whose idx:orth lists its inflected
+// forms as idx:iform, so that a lookup of a form finds the entry.
+func (w *Writer) Add(word *en.WordData) error {
+	if w.opts.Language == "" {
+		w.opts.Language = word.LangCode
+	}
+	if w.doc == nil || w.count == w.opts.EntriesPerFile {
+		if err := w.startPart(word.Word); err != nil {
+			return err
+		}
+	}
+	w.count++
+	w.parts[len(w.parts)-1].last = word.Word
+
+	e := article.New(word)
+	var sb strings.Builder
+	sb.WriteString(`<idx:entry name="default" scriptable="yes" spell="yes">` + "\n")
+	sb.WriteString(`<idx:orth value="` + html.EscapeString(e.Word) + `"><b>` + html.EscapeString(e.Word) + `</b>`)
+	if forms := e.Inflections(); len(forms) > 0 && !w.opts.NoForms {
+		sb.WriteString("\n<idx:infl>\n")
+		for _, f := range forms {
+			sb.WriteString(`<idx:iform value="` + html.EscapeString(f) + `"/>` + "\n")
+		}
+		sb.WriteString("</idx:infl>\n")
+	}
+	sb.WriteString("</idx:orth>\n")
+	sb.WriteString(article.HTML(e))
+	sb.WriteString("\n</idx:entry>\n<hr/>\n")
+	_, err := io.WriteString(w.doc, sb.String())
+	return err
+}
+
+// startPart closes the current content document and opens the next.
+func (w *Writer) startPart(first string) error {
+	if err := w.endPart(); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("entries-%04d.xhtml", len(w.parts)+1)
+	f, err := w.zw.Create("OEBPS/" + name)
+	if err != nil {
+		return err
+	}
+	w.doc, w.count = f, 0
+	w.parts = append(w.parts, part{name: name, first: first})
+	_, err = io.WriteString(f, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="`+XHTML+`" xmlns:epub="`+EPUB+`" xmlns:idx="`+IDX+`" lang="`+html.EscapeString(w.opts.TargetLanguage)+`">
+<head><meta charset="UTF-8"/><title>`+html.EscapeString(w.opts.Title)+`</title><link rel="stylesheet" type="text/css" href="style.css"/></head>
+<body>
+<mbp:frameset xmlns:mbp="`+IDX+`">
+`)
+	return err
+}
+
+func (w *Writer) endPart() error {
+	if w.doc == nil {
+		return nil
+	}
+	_, err := io.WriteString(w.doc, "</mbp:frameset>\n</body>\n</html>\n")
+	w.doc = nil
+	return err
+}
+
+// Close ends the last content document, writes the stylesheet, the
+// navigation document and the package document and finishes the
+// archive.
+func (w *Writer) Close() error {
+	if err := w.endPart(); err != nil {
+		return err
+	}
+	if err := w.writeFile("OEBPS/style.css", CSS); err != nil {
+		return err
+	}
+	if err := w.writeFile("OEBPS/nav.xhtml", w.nav()); err != nil {
+		return err
+	}
+	if err := w.writeFile("OEBPS/content.opf", w.opf()); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// CSS styles the rendered articles.
+const CSS string = `.pos { color: #666; margin: 0; }
+.tags { font-style: italic; }
+.examples { list-style: none; }
+.etym { font-size: 90%; }
+`
+
+const container string = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+func (w *Writer) nav() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="` + XHTML + `" xmlns:epub="` + EPUB + `">
+<head><meta charset="UTF-8"/><title>` + html.EscapeString(w.opts.Title) + `</title></head>
+<body>
+<nav epub:type="toc" id="toc">
+<ol>
+`)
+	for _, p := range w.parts {
+		label := p.first
+		if p.last != p.first {
+			label += " – " + p.last
+		}
+		sb.WriteString(`<li><a href="` + p.name + `">` + html.EscapeString(label) + "</a></li>\n")
+	}
+	sb.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+func (w *Writer) opf() string {
+	o := w.opts
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="` + OPF + `" version="3.0" unique-identifier="uid" xml:lang="` + html.EscapeString(o.TargetLanguage) + `">
+<metadata xmlns:dc="` + DC + `">
+<dc:identifier id="uid">` + html.EscapeString(o.Identifier) + `</dc:identifier>
+<dc:title>` + html.EscapeString(o.Title) + `</dc:title>
+<dc:language>` + html.EscapeString(o.Language) + `</dc:language>
+<dc:type>dictionary</dc:type>
+`)
+	if o.Author != "" {
+		sb.WriteString(`<dc:creator>` + html.EscapeString(o.Author) + "</dc:creator>\n")
+	}
+	sb.WriteString(`<meta property="dcterms:modified">` + o.Modified.UTC().Format("2006-01-02T15:04:05Z") + "</meta>\n")
+	sb.WriteString(`<meta property="source-language">` + html.EscapeString(o.Language) + "</meta>\n")
+	sb.WriteString(`<meta property="target-language">` + html.EscapeString(o.TargetLanguage) + "</meta>\n")
+	sb.WriteString(`<x-metadata>
+<DictionaryInLanguage>` + html.EscapeString(o.Language) + `</DictionaryInLanguage>
+<DictionaryOutLanguage>` + html.EscapeString(o.TargetLanguage) + `</DictionaryOutLanguage>
+<DefaultLookupIndex>default</DefaultLookupIndex>
+</x-metadata>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="css" href="style.css" media-type="text/css"/>
+`)
+	for i, p := range w.parts {
+		sb.WriteString(fmt.Sprintf(`<item id="part%d" href="%s" media-type="application/xhtml+xml"/>`+"\n", i+1, p.name))
+	}
+	sb.WriteString("</manifest>\n<spine>\n")
+	for i := range w.parts {
+		sb.WriteString(fmt.Sprintf(`<itemref idref="part%d"/>`+"\n", i+1))
+	}
+	sb.WriteString("</spine>\n</package>\n")
+	return sb.String()
+}
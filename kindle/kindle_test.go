@@ -0,0 +1,155 @@
+package kindle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/kindle"
+)
+
+var words = []*en.WordData{
+	{
+		Word: "mouse", Pos: "noun", LangCode: "en",
+		Forms: []en.FormData{
+			{Form: "mice", Tags: []string{"plural"}},
+			{Form: "mouses", Tags: []string{"plural", "nonstandard"}},
+			{Form: "-", Tags: []string{"table-tags"}},
+		},
+		Senses: []en.SenseData{{Glosses: []string{"A small rodent."}}},
+	},
+	{Word: "Q&A", Pos: "noun", LangCode: "en", Senses: []en.SenseData{{Glosses: []string{"Questions and answers."}}}},
+	{Word: "zebra", Pos: "noun", LangCode: "en", Senses: []en.SenseData{{Glosses: []string{"An equine."}}}},
+}
+
+type meta struct {
+	Property string `xml:"property,attr"`
+	Value    string `xml:",chardata"`
+}
+
+func readFile(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEPUB(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := kindle.NewWriter(&buf, kindle.Options{
+		Title:          "Test Dictionary",
+		EntriesPerFile: 2,
+		Modified:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range words {
+		if err := w.Add(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store || string(readFile(t, zr, "mimetype")) != "application/epub+zip" {
+		t.Errorf("mimetype must be the first, stored entry")
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(readFile(t, zr, "META-INF/container.xml"), &container); err != nil {
+		t.Fatal(err)
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	var opf struct {
+		Identifier string `xml:"metadata>identifier"`
+		Language   string `xml:"metadata>language"`
+		Type       string `xml:"metadata>type"`
+		InLanguage string `xml:"metadata>x-metadata>DictionaryInLanguage"`
+		Lookup     string `xml:"metadata>x-metadata>DefaultLookupIndex"`
+		Spine      []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Meta []meta `xml:"metadata>meta"`
+	}
+	if err := xml.Unmarshal(readFile(t, zr, opfPath), &opf); err != nil {
+		t.Fatal(err)
+	}
+	if opf.Language != "en" || opf.InLanguage != "en" || opf.Type != "dictionary" || opf.Lookup != "default" || opf.Identifier == "" {
+		t.Errorf("opf metadata = %+v", opf)
+	}
+	if !slices.ContainsFunc(opf.Meta, func(m meta) bool {
+		return m.Property == "dcterms:modified" && m.Value == "2024-05-01T12:00:00Z"
+	}) {
+		t.Errorf("missing dcterms:modified in %+v", opf.Meta)
+	}
+
+	// every manifest item exists; parse the content documents
+	orths := map[string][]string{}
+	var parts int
+	for _, item := range opf.Items {
+		b := readFile(t, zr, path.Join(path.Dir(opfPath), item.Href))
+		if path.Ext(item.Href) != ".xhtml" || item.ID == "nav" {
+			continue
+		}
+		parts++
+		d := xml.NewDecoder(bytes.NewReader(b))
+		var orth string
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", item.Href, err)
+			}
+			if se, ok := tok.(xml.StartElement); ok && se.Name.Space == kindle.IDX {
+				value := ""
+				for _, a := range se.Attr {
+					if a.Name.Local == "value" {
+						value = a.Value
+					}
+				}
+				switch se.Name.Local {
+				case "orth":
+					orth = value
+					orths[orth] = []string{}
+				case "iform":
+					orths[orth] = append(orths[orth], value)
+				}
+			}
+		}
+	}
+	if parts != 2 || len(opf.Spine) != 2 || opf.Spine[1].IDRef != "part2" {
+		t.Errorf("got %d content documents and spine %v, want 2", parts, opf.Spine)
+	}
+	if len(orths) != 3 || !slices.Equal(orths["mouse"], []string{"mice", "mouses"}) || len(orths["Q&A"]) != 0 {
+		t.Errorf("orths = %q", orths)
+	}
+}
@@ -0,0 +1,62 @@
+package ontolex
+
+// partsOfSpeech maps wiktextract `pos` values to LexInfo individuals.
+var partsOfSpeech = map[string]string{
+	"noun":        "noun",
+	"name":        "properNoun",
+	"verb":        "verb",
+	"adj":         "adjective",
+	"adv":         "adverb",
+	"pron":        "pronoun",
+	"det":         "determiner",
+	"article":     "article",
+	"prep":        "preposition",
+	"postp":       "postposition",
+	"conj":        "conjunction",
+	"intj":        "interjection",
+	"num":         "numeral",
+	"particle":    "particle",
+	"prefix":      "prefix",
+	"suffix":      "suffix",
+	"infix":       "infix",
+	"abbrev":      "abbreviation",
+	"contraction": "contraction",
+	"symbol":      "symbol",
+	"phrase":      "phraseologicalUnit",
+	"proverb":     "proverb",
+	"punct":       "punctuation",
+}
+
+// morphosyntax maps form tags to a LexInfo property and value.
+var morphosyntax = map[string][2]string{
+	"singular":      {"number", "singular"},
+	"plural":        {"number", "plural"},
+	"dual":          {"number", "dual"},
+	"masculine":     {"gender", "masculine"},
+	"feminine":      {"gender", "feminine"},
+	"neuter":        {"gender", "neuter"},
+	"common":        {"gender", "commonGender"},
+	"nominative":    {"case", "nominativeCase"},
+	"accusative":    {"case", "accusativeCase"},
+	"genitive":      {"case", "genitiveCase"},
+	"dative":        {"case", "dativeCase"},
+	"first-person":  {"person", "firstPerson"},
+	"second-person": {"person", "secondPerson"},
+	"third-person":  {"person", "thirdPerson"},
+	"present":       {"tense", "present"},
+	"past":          {"tense", "past"},
+	"future":        {"tense", "future"},
+	"participle":    {"verbFormMood", "participle"},
+	"infinitive":    {"verbFormMood", "infinitive"},
+	"gerund":        {"verbFormMood", "gerund"},
+	"imperative":    {"verbFormMood", "imperative"},
+	"indicative":    {"verbFormMood", "indicative"},
+	"subjunctive":   {"verbFormMood", "subjunctive"},
+	"comparative":   {"degree", "comparative"},
+	"superlative":   {"degree", "superlative"},
+	"progressive":   {"aspect", "progressive"},
+	"perfective":    {"aspect", "perfective"},
+	"imperfective":  {"aspect", "imperfective"},
+	"active":        {"voice", "activeVoice"},
+	"passive":       {"voice", "passiveVoice"},
+}
@@ -0,0 +1,252 @@
+// Package ontolex exports [en.WordData] as OntoLex-Lemon RDF, written as
+// N-Triples or Turtle.
+//
+// Every WordData becomes an ontolex:LexicalEntry with a canonical
+// ontolex:Form, other forms carrying LexInfo morphosyntactic
+// properties, and ontolex:LexicalSense resources for its senses.
+// Translations are vartrans:Translation resources between senses, and
+// Wikidata QIDs of a sense are linked with owl:sameAs.
+package ontolex
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Namespaces of the vocabularies used.
+const (
+	RDF      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	RDFS     = "http://www.w3.org/2000/01/rdf-schema#"
+	OWL      = "http://www.w3.org/2002/07/owl#"
+	SKOS     = "http://www.w3.org/2004/02/skos/core#"
+	ONTOLEX  = "http://www.w3.org/ns/lemon/ontolex#"
+	LEXINFO  = "http://www.lexinfo.net/ontology/3.0/lexinfo#"
+	VARTRANS = "http://www.w3.org/ns/lemon/vartrans#"
+	LIME     = "http://www.w3.org/ns/lemon/lime#"
+	DCT      = "http://purl.org/dc/terms/"
+	WIKIDATA = "http://www.wikidata.org/entity/"
+)
+
+// Prefixes returns the prefix mappings for a [TurtleWriter].
+func Prefixes() map[string]string {
+	return map[string]string{
+		"rdf":      RDF,
+		"rdfs":     RDFS,
+		"owl":      OWL,
+		"skos":     SKOS,
+		"ontolex":  ONTOLEX,
+		"lexinfo":  LEXINFO,
+		"vartrans": VARTRANS,
+		"lime":     LIME,
+		"dct":      DCT,
+		"wd":       WIKIDATA,
+	}
+}
+
+// Encoder converts WordData to triples.
+type Encoder struct {
+	w TripleWriter
+	// namespace of the minted IRIs, ending with "/" or "#"
+	base string
+}
+
+// NewEncoder returns an encoder minting IRIs under `base`, e.g.
+// "https://example.org/wiktionary/".
+func NewEncoder(w TripleWriter, base string) *Encoder {
+	return &Encoder{w: w, base: base}
+}
+
+// EntryIRI returns the IRI of the lexical entry of a word:
+// {base}{lang_code}/{word}/{pos}[/{etymology_number}], with the word
+// percent-encoded.
+func (e *Encoder) EntryIRI(langCode, word, pos string, etymologyNumber *int) string {
+	iri := e.base + url.PathEscape(langCode) + "/" + url.PathEscape(word) + "/" + url.PathEscape(pos)
+	if etymologyNumber != nil {
+		iri += fmt.Sprintf("/%d", *etymologyNumber)
+	}
+	return iri
+}
+
+// FormIRI returns the IRI of a form of an entry. It is derived from the
+// form and its tags, so that it does not depend on the order of forms.
+func FormIRI(entry, form string, tags []string) string {
+	sum := sha1.Sum([]byte(form + "\x00" + strings.Join(tags, "\x00")))
+	return entry + "#form-" + hex.EncodeToString(sum[:6])
+}
+
+// SenseIRI returns the IRI of a sense from its position in the sense
+// hierarchy, e.g. "1.2".
+func SenseIRI(entry, n string) string {
+	return entry + "#sense-" + n
+}
+
+// Encode writes the triples describing `w`.
+func (e *Encoder) Encode(w *en.WordData) error {
+	lang := w.LangCode
+	entry := NewIRI(e.EntryIRI(lang, w.Word, w.Pos, w.EtymologyNumber))
+	t := &triples{}
+
+	t.add(entry, RDF+"type", NewIRI(ONTOLEX+"LexicalEntry"))
+	switch {
+	case w.Pos == "prefix" || w.Pos == "suffix" || w.Pos == "infix" || w.Pos == "affix":
+		t.add(entry, RDF+"type", NewIRI(ONTOLEX+"Affix"))
+	case strings.ContainsAny(w.Word, " \u00a0"):
+		t.add(entry, RDF+"type", NewIRI(ONTOLEX+"MultiwordExpression"))
+	default:
+		t.add(entry, RDF+"type", NewIRI(ONTOLEX+"Word"))
+	}
+	t.add(entry, RDFS+"label", NewLiteral(w.Word, lang))
+	t.add(entry, LIME+"language", NewLiteral(lang, ""))
+	if pos, ok := partsOfSpeech[w.Pos]; ok {
+		t.add(entry, LEXINFO+"partOfSpeech", NewIRI(LEXINFO+pos))
+	}
+
+	canonical := NewIRI(entry.Value + "#canonical")
+	t.add(entry, ONTOLEX+"canonicalForm", canonical)
+	t.add(canonical, RDF+"type", NewIRI(ONTOLEX+"Form"))
+	t.add(canonical, ONTOLEX+"writtenRep", NewLiteral(w.Word, lang))
+	for _, s := range w.Sounds {
+		if s.Ipa != nil && *s.Ipa != "" {
+			t.add(canonical, ONTOLEX+"phoneticRep", NewLiteral(*s.Ipa, lang+"-fonipa"))
+		}
+	}
+
+	for _, f := range w.Forms {
+		if !article.ShowForm(f) || f.Form == w.Word && len(f.Tags) == 0 {
+			continue
+		}
+		form := NewIRI(FormIRI(entry.Value, f.Form, f.Tags))
+		t.add(entry, ONTOLEX+"otherForm", form)
+		t.add(form, RDF+"type", NewIRI(ONTOLEX+"Form"))
+		t.add(form, ONTOLEX+"writtenRep", NewLiteral(f.Form, lang))
+		if f.Ipa != nil && *f.Ipa != "" {
+			t.add(form, ONTOLEX+"phoneticRep", NewLiteral(*f.Ipa, lang+"-fonipa"))
+		}
+		for _, tag := range f.Tags {
+			if prop, ok := morphosyntax[tag]; ok {
+				t.add(form, LEXINFO+prop[0], NewIRI(LEXINFO+prop[1]))
+			}
+		}
+	}
+
+	// glosses of the senses, to attach translations to them
+	senses := make(map[string]Term)
+	var walk func(ss []*article.Sense, prefix string)
+	walk = func(ss []*article.Sense, prefix string) {
+		for i, s := range ss {
+			n := fmt.Sprintf("%s%d", prefix, i+1)
+			sense := NewIRI(SenseIRI(entry.Value, n))
+			t.add(entry, ONTOLEX+"sense", sense)
+			t.add(sense, RDF+"type", NewIRI(ONTOLEX+"LexicalSense"))
+			t.add(sense, SKOS+"definition", NewLiteral(s.Gloss, "en"))
+			if prefix != "" {
+				t.add(sense, SKOS+"broader", NewIRI(SenseIRI(entry.Value, strings.TrimSuffix(prefix, "."))))
+			}
+			if s.Data != nil {
+				for _, q := range s.Data.Wikidata {
+					if isQID(q) {
+						t.add(sense, OWL+"sameAs", NewIRI(WIKIDATA+q))
+					}
+				}
+			}
+			if _, ok := senses[s.Gloss]; !ok {
+				senses[s.Gloss] = sense
+			}
+			walk(s.Subsenses, n+".")
+		}
+	}
+	walk(article.New(w).Senses, "")
+
+	for _, q := range w.Wikidata {
+		if isQID(q) {
+			t.add(entry, DCT+"subject", NewIRI(WIKIDATA+q))
+		}
+	}
+	for _, tr := range w.Translations {
+		sense, ok := Term{}, false
+		if tr.Sense != nil {
+			sense, ok = senses[*tr.Sense]
+		}
+		if !ok && len(senses) == 1 {
+			for _, s := range senses {
+				sense, ok = s, true
+			}
+		}
+		if ok {
+			e.translation(t, entry, sense, w.Pos, tr)
+		} else {
+			e.translation(t, entry, Term{}, w.Pos, tr)
+		}
+	}
+
+	for _, tr := range t.list {
+		if err := e.w.Write(tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// translation links the source sense (or entry, when the sense is
+// unknown) to a sense of the entry of the translation. Translations are
+// listed by part of speech, so the target is the [Encoder.EntryIRI] of
+// the translation with the part of speech `pos` of the source; the
+// etymology number of the target is unknown.
+func (e *Encoder) translation(t *triples, entry, sense Term, pos string, tr en.TranslationData) {
+	if tr.Word == nil || *tr.Word == "" || tr.LangCode == "" {
+		return
+	}
+	target := NewIRI(e.EntryIRI(tr.LangCode, *tr.Word, pos, nil))
+	t.add(target, RDF+"type", NewIRI(ONTOLEX+"LexicalEntry"))
+	t.add(target, RDFS+"label", NewLiteral(*tr.Word, tr.LangCode))
+	t.add(target, LIME+"language", NewLiteral(tr.LangCode, ""))
+
+	if sense == (Term{}) {
+		t.add(entry, VARTRANS+"translatableAs", target)
+		return
+	}
+	sum := sha1.Sum([]byte(sense.Value))
+	targetSense := NewIRI(target.Value + "#sense-" + hex.EncodeToString(sum[:6]))
+	t.add(target, ONTOLEX+"sense", targetSense)
+	t.add(targetSense, RDF+"type", NewIRI(ONTOLEX+"LexicalSense"))
+
+	translation := NewIRI(sense.Value + "-tr-" + url.PathEscape(tr.LangCode) + "-" + url.PathEscape(*tr.Word))
+	t.add(translation, RDF+"type", NewIRI(VARTRANS+"Translation"))
+	t.add(translation, VARTRANS+"source", sense)
+	t.add(translation, VARTRANS+"target", targetSense)
+}
+
+// triples buffers the statements of an entry, dropping duplicates.
+type triples struct {
+	list []Triple
+	seen map[Triple]bool
+}
+
+func (t *triples) add(s Term, p string, o Term) {
+	tr := Triple{s, NewIRI(p), o}
+	if t.seen == nil {
+		t.seen = make(map[Triple]bool)
+	}
+	if !t.seen[tr] {
+		t.seen[tr] = true
+		t.list = append(t.list, tr)
+	}
+}
+
+func isQID(s string) bool {
+	if len(s) < 2 || s[0] != 'Q' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package ontolex_test
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/ontolex"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+const BASE string = "https://example.org/wiktionary/"
+
+var water = &en.WordData{
+	Word: "water", Pos: "noun", Lang: "English", LangCode: "en",
+	Sounds: []en.SoundData{{Ipa: ptr("/ˈwɔːtə(ɹ)/")}},
+	Forms: []en.FormData{
+		{Form: "waters", Tags: []string{"plural"}},
+		{Form: "-", Tags: []string{"table-tags"}},
+	},
+	Senses: []en.SenseData{
+		{Glosses: []string{"A \"clear\" liquid,\nH₂O."}, Wikidata: []string{"Q283", "not-a-qid"}},
+		{Glosses: []string{"A \"clear\" liquid,\nH₂O.", "Mineral water."}},
+		{Glosses: []string{"A body of water."}},
+	},
+	Translations: []en.TranslationData{
+		{LangCode: "fr", Lang: "French", Word: ptr("eau"), Sense: ptr("A \"clear\" liquid,\nH₂O.")},
+		{LangCode: "de", Lang: "German", Word: ptr("Gewässer"), Sense: ptr("A body of water.")},
+		{LangCode: "es", Lang: "Spanish", Word: ptr("agua"), Sense: ptr("unmatched sense")},
+		{LangCode: "it", Lang: "Italian", Note: ptr("no word")},
+	},
+}
+
+// ntriple matches one N-Triples statement.
+var ntriple = regexp.MustCompile(`^(<[^<>"{}|^` + "`" + `\\ ]*>|_:\w+) (<[^<>"{}|^` + "`" + `\\ ]*>) (<[^<>"{}|^` + "`" + `\\ ]*>|_:\w+|"((?:[^"\\\n\r]|\\.)*)"(@[a-zA-Z]+(?:-[a-zA-Z0-9]+)*|\^\^<[^>]*>)?) \.$`)
+
+func TestNTriples(t *testing.T) {
+	var sb strings.Builder
+	w := ontolex.NewNTriplesWriter(&sb)
+	if err := ontolex.NewEncoder(w, BASE).Encode(water); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	var definitions []string
+	for _, line := range lines {
+		m := ntriple.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("malformed statement: %s", line)
+			continue
+		}
+		if m[2] == "<"+ontolex.SKOS+"definition>" {
+			def, err := strconv.Unquote(`"` + m[4] + `"`)
+			if err != nil {
+				t.Errorf("bad escape in %s: %v", m[4], err)
+			}
+			definitions = append(definitions, def)
+		}
+	}
+	want := []string{"A \"clear\" liquid,\nH₂O.", "Mineral water.", "A body of water."}
+	if !slices.Equal(definitions, want) {
+		t.Errorf("definitions = %q, want %q", definitions, want)
+	}
+
+	entry := "<" + BASE + "en/water/noun>"
+	for _, want := range []string{
+		entry + " <" + ontolex.RDF + "type> <" + ontolex.ONTOLEX + "LexicalEntry> .",
+		entry + " <" + ontolex.RDF + "type> <" + ontolex.ONTOLEX + "Word> .",
+		entry + " <" + ontolex.LEXINFO + "partOfSpeech> <" + ontolex.LEXINFO + "noun> .",
+		"<" + BASE + "en/water/noun#canonical> <" + ontolex.ONTOLEX + "writtenRep> \"water\"@en .",
+		"<" + BASE + "en/water/noun#canonical> <" + ontolex.ONTOLEX + "phoneticRep> \"/ˈwɔːtə(ɹ)/\"@en-fonipa .",
+		"<" + BASE + "en/water/noun#sense-1> <" + ontolex.OWL + "sameAs> <http://www.wikidata.org/entity/Q283> .",
+		"<" + BASE + "en/water/noun#sense-1.1> <" + ontolex.SKOS + "broader> <" + BASE + "en/water/noun#sense-1> .",
+		"<" + BASE + "en/water/noun#sense-1-tr-fr-eau> <" + ontolex.VARTRANS + "source> <" + BASE + "en/water/noun#sense-1> .",
+		"<" + BASE + "en/water/noun#sense-2-tr-de-Gew%C3%A4sser> <" + ontolex.RDF + "type> <" + ontolex.VARTRANS + "Translation> .",
+		"<" + BASE + "de/Gew%C3%A4sser/noun> <" + ontolex.RDFS + "label> \"Gewässer\"@de .",
+		entry + " <" + ontolex.VARTRANS + "translatableAs> <" + BASE + "es/agua/noun> .",
+		// the entry exported for the translation
+		"<" + ontolex.NewEncoder(nil, BASE).EntryIRI("fr", "eau", "noun", nil) + "> <" + ontolex.RDF + "type> <" + ontolex.ONTOLEX + "LexicalEntry> .",
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Contains(sb.String(), "not-a-qid") || strings.Contains(sb.String(), "/it/") {
+		t.Error("invalid QID or translation without a word exported")
+	}
+
+	var plural string
+	for _, line := range lines {
+		if strings.Contains(line, "\"waters\"@en") {
+			plural = strings.Fields(line)[0]
+		}
+	}
+	if plural == "" || !slices.Contains(lines, plural+" <"+ontolex.LEXINFO+"number> <"+ontolex.LEXINFO+"plural> .") {
+		t.Errorf("plural form %s lacks lexinfo:number", plural)
+	}
+}
+
+func TestStableIRIs(t *testing.T) {
+	a := ontolex.FormIRI("e", "waters", []string{"plural"})
+	if a != ontolex.FormIRI("e", "waters", []string{"plural"}) || a == ontolex.FormIRI("e", "waters", nil) {
+		t.Error("form IRIs must depend only on the form and its tags")
+	}
+	enc := ontolex.NewEncoder(nil, BASE)
+	if got := enc.EntryIRI("en", "a priori", "adj", ptr(2)); got != BASE+"en/a%20priori/adj/2" {
+		t.Errorf("EntryIRI = %s", got)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	var sb strings.Builder
+	w := ontolex.NewNTriplesWriter(&sb)
+	w.Write(ontolex.Triple{
+		S: ontolex.NewIRI("http://example.org/a b<c>"),
+		P: ontolex.NewIRI(ontolex.RDFS + "label"),
+		O: ontolex.NewLiteral("tab\there \\ \x01 end", ""),
+	})
+	w.Write(ontolex.Triple{
+		S: ontolex.Term{Kind: ontolex.Blank, Value: "b0"},
+		P: ontolex.NewIRI(ontolex.RDFS + "label"),
+		O: ontolex.Term{Kind: ontolex.Literal, Value: "3", Datatype: "http://www.w3.org/2001/XMLSchema#integer"},
+	})
+	w.Close()
+	want := `<http://example.org/a%20b%3Cc%3E> <http://www.w3.org/2000/01/rdf-schema#label> "tab\there \\ \u0001 end" .` + "\n" +
+		`_:b0 <http://www.w3.org/2000/01/rdf-schema#label> "3"^^<http://www.w3.org/2001/XMLSchema#integer> .` + "\n"
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestTurtle(t *testing.T) {
+	var sb strings.Builder
+	w := ontolex.NewTurtleWriter(&sb, ontolex.Prefixes())
+	if err := ontolex.NewEncoder(w, BASE).Encode(&en.WordData{
+		Word: "ice cream", Pos: "noun", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"A frozen dessert."}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	doc := sb.String()
+	for _, want := range []string{
+		"@prefix ontolex: <http://www.w3.org/ns/lemon/ontolex#> .\n",
+		"<https://example.org/wiktionary/en/ice%20cream/noun> a ontolex:LexicalEntry ;\n    a ontolex:MultiwordExpression ;\n    rdfs:label \"ice cream\"@en ;\n",
+		"    lexinfo:partOfSpeech lexinfo:noun ;\n",
+		"<https://example.org/wiktionary/en/ice%20cream/noun#sense-1> a ontolex:LexicalSense ;\n    skos:definition \"A frozen dessert.\"@en .\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("missing %q in\n%s", want, doc)
+		}
+	}
+	if !strings.HasSuffix(doc, " .\n") || strings.Count(doc, "@prefix") != len(ontolex.Prefixes()) {
+		t.Errorf("malformed document:\n%s", doc)
+	}
+}
@@ -0,0 +1,234 @@
+package ontolex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// TermKind is the kind of an RDF [Term].
+type TermKind int
+
+const (
+	IRI TermKind = iota
+	Literal
+	Blank
+)
+
+// Term is an RDF term: an IRI, a literal with an optional language tag
+// or datatype IRI, or a blank node label.
+type Term struct {
+	Kind     TermKind
+	Value    string
+	Lang     string
+	Datatype string
+}
+
+// NewIRI returns an IRI term.
+func NewIRI(iri string) Term { return Term{Kind: IRI, Value: iri} }
+
+// NewLiteral returns a language-tagged literal, or a plain string
+// literal if `lang` is empty.
+func NewLiteral(value, lang string) Term { return Term{Kind: Literal, Value: value, Lang: lang} }
+
+// Triple is an RDF statement.
+type Triple struct {
+	S, P, O Term
+}
+
+// TripleWriter is implemented by the N-Triples and Turtle writers.
+type TripleWriter interface {
+	Write(t Triple) error
+	// Close flushes buffered output; it does not close the underlying
+	// writer.
+	Close() error
+}
+
+// escapeString escapes a literal's lexical form for N-Triples and
+// Turtle, keeping non-ASCII characters as UTF-8.
+func escapeString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case utf8.RuneError:
+			sb.WriteString(`�`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// escapeIRI percent-encodes the characters that may not appear in an
+// IRI: a \u escape would be decoded back to them.
+func escapeIRI(iri string) string {
+	var sb strings.Builder
+	for _, r := range iri {
+		if r <= 0x20 || r == 0x7f || strings.ContainsRune("<>\"{}|^`\\", r) {
+			fmt.Fprintf(&sb, "%%%02X", r)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// formatTerm formats a term in N-Triples syntax; `iri` formats IRIs.
+func formatTerm(t Term, iri func(string) string) string {
+	switch t.Kind {
+	case Literal:
+		s := `"` + escapeString(t.Value) + `"`
+		if t.Lang != "" {
+			return s + "@" + t.Lang
+		}
+		if t.Datatype != "" {
+			return s + "^^" + iri(t.Datatype)
+		}
+		return s
+	case Blank:
+		return "_:" + t.Value
+	}
+	return iri(t.Value)
+}
+
+func fullIRI(iri string) string { return "<" + escapeIRI(iri) + ">" }
+
+// NTriplesWriter writes one triple per line.
+type NTriplesWriter struct {
+	w *bufio.Writer
+}
+
+func NewNTriplesWriter(w io.Writer) *NTriplesWriter {
+	return &NTriplesWriter{w: bufio.NewWriter(w)}
+}
+
+func (n *NTriplesWriter) Write(t Triple) error {
+	_, err := n.w.WriteString(formatTerm(t.S, fullIRI) + " " + formatTerm(t.P, fullIRI) + " " + formatTerm(t.O, fullIRI) + " .\n")
+	return err
+}
+
+func (n *NTriplesWriter) Close() error {
+	return n.w.Flush()
+}
+
+// TurtleWriter writes Turtle, abbreviating IRIs with prefixes and
+// grouping consecutive triples about the same subject.
+type TurtleWriter struct {
+	w        *bufio.Writer
+	prefixes [][2]string // prefix, namespace; longest namespaces first
+	subject  *Term
+	begun    bool
+}
+
+// NewTurtleWriter returns a writer using the given prefix to namespace
+// mappings, which are declared at the top of the document.
+func NewTurtleWriter(w io.Writer, prefixes map[string]string) *TurtleWriter {
+	t := &TurtleWriter{w: bufio.NewWriter(w)}
+	for p, ns := range prefixes {
+		t.prefixes = append(t.prefixes, [2]string{p, ns})
+	}
+	slices.SortFunc(t.prefixes, func(a, b [2]string) int {
+		if c := len(b[1]) - len(a[1]); c != 0 {
+			return c
+		}
+		return strings.Compare(a[0], b[0])
+	})
+	return t
+}
+
+func (t *TurtleWriter) begin() {
+	if t.begun {
+		return
+	}
+	t.begun = true
+	sorted := slices.Clone(t.prefixes)
+	slices.SortFunc(sorted, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+	for _, p := range sorted {
+		t.w.WriteString("@prefix " + p[0] + ": " + fullIRI(p[1]) + " .\n")
+	}
+	if len(sorted) > 0 {
+		t.w.WriteString("\n")
+	}
+}
+
+// iri abbreviates an IRI to a prefixed name when its local part is a
+// simple name.
+func (t *TurtleWriter) iri(iri string) string {
+	for _, p := range t.prefixes {
+		if local, ok := strings.CutPrefix(iri, p[1]); ok && isLocalName(local) {
+			return p[0] + ":" + local
+		}
+	}
+	return fullIRI(iri)
+}
+
+// isLocalName reports whether `s` can be written unescaped after a
+// prefix. This is stricter than Turtle's PN_LOCAL.
+func isLocalName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9', r == '-':
+			if i == 0 && r == '-' {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (t *TurtleWriter) Write(tr Triple) error {
+	t.begin()
+	p := t.iri(tr.P.Value)
+	if tr.P.Value == RDF+"type" {
+		p = "a"
+	}
+	o := formatTerm(tr.O, t.iri)
+	var err error
+	if t.subject != nil && *t.subject == tr.S {
+		_, err = t.w.WriteString(" ;\n    " + p + " " + o)
+	} else {
+		if t.subject != nil {
+			t.w.WriteString(" .\n")
+		}
+		s := tr.S
+		t.subject = &s
+		_, err = t.w.WriteString(formatTerm(tr.S, t.iri) + " " + p + " " + o)
+	}
+	return err
+}
+
+func (t *TurtleWriter) Close() error {
+	t.begin()
+	if t.subject != nil {
+		t.w.WriteString(" .\n")
+		t.subject = nil
+	}
+	return t.w.Flush()
+}
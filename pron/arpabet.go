@@ -0,0 +1,159 @@
+package pron
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultArpabet maps English IPA segments to space separated ARPAbet
+// phones. It covers both General American and Received Pronunciation
+// transcriptions as found on Wiktionary; non-rhotic vowels are mapped to
+// their rhotic CMUdict equivalents.
+var defaultArpabet = map[string]string{
+	// monophthongs
+	"ɑ": "AA", "ɑː": "AA", "ɒ": "AA", "ɒː": "AA", "a": "AA", "aː": "AA",
+	"æ": "AE",
+	"ʌ": "AH", "ə": "AH", "ɐ": "AH",
+	"ɔ": "AO", "ɔː": "AO",
+	"ɛ": "EH", "e": "EH", "ɛː": "EH",
+	"ɜ": "ER", "ɜː": "ER", "ɝ": "ER", "ɚ": "ER", "ɜɹ": "ER", "əɹ": "ER",
+	"ɪ": "IH", "ᵻ": "IH", "ɨ": "IH",
+	"i": "IY", "iː": "IY",
+	"ʊ": "UH", "ɵ": "UH",
+	"u": "UW", "uː": "UW", "ʉ": "UW", "ʉː": "UW",
+	"o": "OW", "oː": "OW",
+	// diphthongs
+	"aʊ": "AW", "æʊ": "AW",
+	"aɪ": "AY", "ɑɪ": "AY", "ʌɪ": "AY",
+	"eɪ": "EY", "ɛɪ": "EY",
+	"oʊ": "OW", "əʊ": "OW", "ɘʊ": "OW",
+	"ɔɪ": "OY", "oɪ": "OY",
+	"ɪə": "IH R", "ɪɚ": "IH R", "ɛə": "EH R", "ɛɚ": "EH R", "eə": "EH R",
+	"ʊə": "UH R", "ʊɚ": "UH R",
+	// consonants
+	"b": "B", "d": "D", "ð": "DH", "f": "F", "ɡ": "G", "g": "G",
+	"h": "HH", "k": "K", "l": "L", "ɫ": "L", "m": "M", "n": "N",
+	"ŋ": "NG", "p": "P", "ɹ": "R", "r": "R", "ɾ": "T", "s": "S",
+	"ʃ": "SH", "t": "T", "θ": "TH", "v": "V", "w": "W", "ʍ": "W",
+	"j": "Y", "z": "Z", "ʒ": "ZH", "x": "HH", "ʔ": "T",
+	"tʃ": "CH", "dʒ": "JH",
+	// syllabic consonants
+	"l̩": "AH L", "n̩": "AH N", "m̩": "AH M", "ɫ̩": "AH L",
+}
+
+// arpabetVowels are the ARPAbet phones that carry a stress digit.
+var arpabetVowels = map[string]bool{
+	"AA": true, "AE": true, "AH": true, "AO": true, "AW": true,
+	"AY": true, "EH": true, "ER": true, "EY": true, "IH": true,
+	"IY": true, "OW": true, "OY": true, "UH": true, "UW": true,
+}
+
+// Arpabet converts IPA transcriptions to ARPAbet phones using a
+// configurable segment table. Segments are matched greedily, longest
+// first.
+type Arpabet struct {
+	table  map[string][]string
+	maxLen int
+}
+
+// DefaultArpabetTable returns a copy of the built-in English IPA to
+// ARPAbet table, which callers may extend before passing it to
+// [NewArpabet].
+func DefaultArpabetTable() map[string]string {
+	return maps.Clone(defaultArpabet)
+}
+
+// NewArpabet builds a converter from `table`, which maps IPA segments
+// to space separated ARPAbet phones (without stress digits). A nil
+// table selects the built-in English table.
+func NewArpabet(table map[string]string) *Arpabet {
+	if table == nil {
+		table = defaultArpabet
+	}
+	a := &Arpabet{table: make(map[string][]string, len(table))}
+	for seg, phones := range table {
+		seg = normalizeIpa(seg)
+		a.table[seg] = strings.Fields(phones)
+		a.maxLen = max(a.maxLen, utf8.RuneCountInString(seg))
+	}
+	return a
+}
+
+// UnmappedError is returned by [Arpabet.Convert] when a transcription
+// contains a segment that is not present in the table.
+type UnmappedError struct {
+	Ipa     string
+	Segment string
+}
+
+func (e *UnmappedError) Error() string {
+	return fmt.Sprintf("pron: no ARPAbet mapping for %q in %q", e.Segment, e.Ipa)
+}
+
+// Convert transcribes `ipa` into ARPAbet phones. Vowels are suffixed
+// with a stress digit: 1 after a primary stress mark, 2 after a
+// secondary one and 0 otherwise. Delimiters and syllable breaks are
+// ignored, and optional segments such as the "(ɹ)" in "/ˈwɔːtə(ɹ)/" are
+// kept.
+func (a *Arpabet) Convert(ipa string) ([]string, error) {
+	ipa, _ = StripDelimiters(ipa)
+	runes := []rune(normalizeIpa(ipa))
+
+	var phones []string
+	stress := "0"
+	for i := 0; i < len(runes); {
+		switch runes[i] {
+		case 'ˈ', '\'':
+			stress = "1"
+			i++
+			continue
+		case 'ˌ':
+			stress = "2"
+			i++
+			continue
+		case '.', '‿', ' ', '-', 'ː', 'ˑ':
+			i++
+			continue
+		}
+
+		n := min(a.maxLen, len(runes)-i)
+		for ; n > 0; n-- {
+			if _, ok := a.table[string(runes[i:i+n])]; ok {
+				break
+			}
+		}
+		if n == 0 {
+			return nil, &UnmappedError{Ipa: ipa, Segment: string(runes[i])}
+		}
+		for _, p := range a.table[string(runes[i:i+n])] {
+			if arpabetVowels[p] {
+				p += stress
+				stress = "0"
+			}
+			phones = append(phones, p)
+		}
+		i += n
+	}
+	return phones, nil
+}
+
+// normalizeIpa drops parentheses around optional segments, tie bars,
+// secondary articulations (e.g. aspiration) and every combining
+// diacritic except the syllabic mark, which changes the ARPAbet output.
+func normalizeIpa(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '̩', '̍':
+			return '̩'
+		case '(', ')', '͡', '͜', 'ʰ', 'ʷ', 'ʲ', 'ˠ', 'ˤ', 'ⁿ', 'ˡ':
+			return -1
+		}
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,95 @@
+package pron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// CMUDictOptions configures a [CMUDictWriter].
+type CMUDictOptions struct {
+	// only export pronunciations tagged with one of these accents
+	// (e.g., "US", "General-American"). Untagged pronunciations are
+	// always exported. Empty means all accents.
+	Accents []string
+	// IPA to ARPAbet converter. Defaults to `NewArpabet(nil)`.
+	Arpabet *Arpabet
+	// keep the headword case instead of upper-casing it.
+	KeepCase bool
+}
+
+// CMUDictWriter writes a CMUdict-style pronunciation lexicon:
+//
+//	TOMATO  T AH0 M EY1 T OW2
+//	TOMATO(2)  T AH0 M AA1 T OW2
+//
+// Alternative pronunciations of the same word are numbered in the order
+// they are first seen, across all the [en.WordData] written. Duplicate
+// pronunciations are written once.
+type CMUDictWriter struct {
+	w    *bufio.Writer
+	opts CMUDictOptions
+
+	seen    map[string][]string // word -> written phone strings
+	skipped int
+}
+
+// NewCMUDictWriter returns a writer that writes the lexicon to `w`.
+func NewCMUDictWriter(w io.Writer, opts CMUDictOptions) *CMUDictWriter {
+	if opts.Arpabet == nil {
+		opts.Arpabet = NewArpabet(nil)
+	}
+	return &CMUDictWriter{
+		w:    bufio.NewWriter(w),
+		opts: opts,
+		seen: make(map[string][]string),
+	}
+}
+
+// Write appends the pronunciations of `word` to the lexicon.
+// Transcriptions that cannot be converted to ARPAbet are skipped and
+// counted in [CMUDictWriter.Skipped].
+func (c *CMUDictWriter) Write(word *en.WordData) error {
+	for _, p := range Collect(word) {
+		if !hasAccent(p.Accents, c.opts.Accents) {
+			continue
+		}
+		phones, err := c.opts.Arpabet.Convert(p.Ipa)
+		if err != nil || len(phones) == 0 {
+			c.skipped++
+			continue
+		}
+
+		head := strings.Join(strings.Fields(p.Word), "_")
+		if !c.opts.KeepCase {
+			head = strings.ToUpper(head)
+		}
+		line := strings.Join(phones, " ")
+		if slices.Contains(c.seen[head], line) {
+			continue
+		}
+		c.seen[head] = append(c.seen[head], line)
+		if n := len(c.seen[head]); n > 1 {
+			head = fmt.Sprintf("%s(%d)", head, n)
+		}
+		if _, err := fmt.Fprintf(c.w, "%s  %s\n", head, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Skipped returns the number of transcriptions skipped because they
+// contained segments unknown to the ARPAbet table.
+func (c *CMUDictWriter) Skipped() int {
+	return c.skipped
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (c *CMUDictWriter) Flush() error {
+	return c.w.Flush()
+}
@@ -0,0 +1,110 @@
+// Package pron exports pronunciations found in [en.SoundData] as
+// lexicon files, e.g. a CMUdict-style ARPAbet dictionary or a plain
+// word/accent/IPA TSV.
+package pron
+
+import (
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// Pronunciation is a single IPA transcription of a word, with the
+// surrounding `/.../` or `[...]` delimiters removed.
+type Pronunciation struct {
+	Word string
+	// accent or dialect tags copied from `SoundData.Tags`
+	// (e.g., "US", "UK", "General-American")
+	Accents []string
+	Ipa     string
+	// true for phonetic `[...]` transcriptions, false for phonemic
+	// `/.../` ones (or undelimited strings).
+	Phonetic bool
+}
+
+// Collect returns every IPA transcription of `w`, in the order they
+// appear in `w.Sounds`. A single `SoundData.Ipa` holding several
+// transcriptions (e.g. "/a/, /b/") yields one Pronunciation for each.
+func Collect(w *en.WordData) []Pronunciation {
+	var prons []Pronunciation
+	for _, sound := range w.Sounds {
+		if sound.Ipa == nil {
+			continue
+		}
+		for _, t := range SplitTranscriptions(*sound.Ipa) {
+			ipa, phonetic := StripDelimiters(t)
+			if ipa == "" {
+				continue
+			}
+			prons = append(prons, Pronunciation{
+				Word:     w.Word,
+				Accents:  sound.Tags,
+				Ipa:      ipa,
+				Phonetic: phonetic,
+			})
+		}
+	}
+	return prons
+}
+
+// StripDelimiters removes the surrounding `/.../` or `[...]` from an IPA
+// transcription. `phonetic` reports whether square brackets were used.
+func StripDelimiters(s string) (ipa string, phonetic bool) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '/' && s[len(s)-1] == '/':
+			return strings.TrimSpace(s[1 : len(s)-1]), false
+		case s[0] == '[' && s[len(s)-1] == ']':
+			return strings.TrimSpace(s[1 : len(s)-1]), true
+		}
+	}
+	return s, false
+}
+
+// SplitTranscriptions splits a string containing several delimited
+// transcriptions, such as "/ˈtɒm.ə.təʊ/, /təˈmeɪ.toʊ/", into its parts
+// (delimiters kept). Strings without any delimiter are returned as is.
+func SplitTranscriptions(s string) []string {
+	var parts []string
+	for i := 0; i < len(s); i++ {
+		var closing byte
+		switch s[i] {
+		case '/':
+			closing = '/'
+		case '[':
+			closing = ']'
+		default:
+			continue
+		}
+		end := strings.IndexByte(s[i+1:], closing)
+		if end < 0 {
+			break
+		}
+		parts = append(parts, s[i:i+end+2])
+		i += end + 1
+	}
+	if len(parts) == 0 {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return parts
+}
+
+// hasAccent reports whether a pronunciation tagged with `tags` should be
+// kept when only `accents` are wanted. Untagged pronunciations are
+// considered accent-neutral and always kept.
+func hasAccent(tags, accents []string) bool {
+	if len(accents) == 0 || len(tags) == 0 {
+		return true
+	}
+	for _, t := range tags {
+		for _, a := range accents {
+			if strings.EqualFold(t, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
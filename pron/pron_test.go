@@ -0,0 +1,174 @@
+package pron_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/pron"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func tomato() *en.WordData {
+	return &en.WordData{
+		Word: "tomato",
+		Pos:  "noun",
+		Sounds: []en.SoundData{
+			{Ipa: ptr("/təˈmɑːtəʊ/"), Tags: []string{"Received-Pronunciation"}},
+			{Ipa: ptr("/təˈmeɪtoʊ/, /təˈmeɪɾoʊ/"), Tags: []string{"General-American"}},
+			{Ipa: ptr("[tʰəˈmeɪɾoʊ]"), Tags: []string{"General-American"}},
+			{Audio: ptr("En-us-tomato.ogg"), Tags: []string{"US"}},
+			{Rhymes: ptr("-ɑːtəʊ")},
+		},
+	}
+}
+
+func TestStripDelimiters(t *testing.T) {
+	tests := []struct {
+		in       string
+		ipa      string
+		phonetic bool
+	}{
+		{"/ˈwɔːtə(ɹ)/", "ˈwɔːtə(ɹ)", false},
+		{"[ˈwɔɾɚ]", "ˈwɔɾɚ", true},
+		{" /kæt/ ", "kæt", false},
+		{"kæt", "kæt", false},
+		{"/", "/", false},
+	}
+	for _, tt := range tests {
+		ipa, phonetic := pron.StripDelimiters(tt.in)
+		if ipa != tt.ipa || phonetic != tt.phonetic {
+			t.Errorf("StripDelimiters(%q) = %q, %v; want %q, %v", tt.in, ipa, phonetic, tt.ipa, tt.phonetic)
+		}
+	}
+}
+
+func TestSplitTranscriptions(t *testing.T) {
+	got := pron.SplitTranscriptions("/a/, [b] or /c/")
+	if want := []string{"/a/", "[b]", "/c/"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got = pron.SplitTranscriptions("kæt")
+	if want := []string{"kæt"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	prons := pron.Collect(tomato())
+	if len(prons) != 4 {
+		t.Fatalf("got %d pronunciations, want 4: %+v", len(prons), prons)
+	}
+	if prons[1].Ipa != "təˈmeɪtoʊ" || prons[2].Ipa != "təˈmeɪɾoʊ" {
+		t.Errorf("multiple transcriptions not split: %+v", prons[1:3])
+	}
+	if !prons[3].Phonetic || prons[0].Phonetic {
+		t.Errorf("phonetic flag wrong: %+v", prons)
+	}
+}
+
+func TestArpabetConvert(t *testing.T) {
+	arpabet := pron.NewArpabet(nil)
+	tests := []struct {
+		ipa  string
+		want string
+	}{
+		{"/kæt/", "K AE0 T"},
+		{"/ˈwɔːtə(ɹ)/", "W AO1 T ER0"},
+		{"/ˈwɔːtɚ/", "W AO1 T ER0"},
+		{"/təˈmeɪtoʊ/", "T AH0 M EY1 T OW0"},
+		{"/ˈt͡ʃɜːt͡ʃ/", "CH ER1 CH"},
+		{"/ˈdʒʌŋ.ɡəl/", "JH AH1 NG G AH0 L"},
+		{"/ˈbʌt.n̩/", "B AH1 T AH0 N"},
+		{"/ˌɪn.fɔːˈmeɪ.ʃən/", "IH2 N F AO0 M EY1 SH AH0 N"},
+		{"/ðɪə/", "DH IH0 R"},
+	}
+	for _, tt := range tests {
+		phones, err := arpabet.Convert(tt.ipa)
+		if err != nil {
+			t.Errorf("Convert(%q): %v", tt.ipa, err)
+			continue
+		}
+		if got := strings.Join(phones, " "); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.ipa, got, tt.want)
+		}
+	}
+
+	if _, err := arpabet.Convert("/ʘa/"); err == nil {
+		t.Error("expected an error for a click consonant")
+	}
+}
+
+func TestArpabetCustomTable(t *testing.T) {
+	table := pron.DefaultArpabetTable()
+	table["ʘ"] = "P"
+	phones, err := pron.NewArpabet(table).Convert("/ʘa/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(phones, " "); got != "P AA0" {
+		t.Errorf("got %q", got)
+	}
+	if _, err := pron.NewArpabet(nil).Convert("/ʘa/"); err == nil {
+		t.Error("DefaultArpabetTable must return a copy")
+	}
+}
+
+func TestCMUDictWriter(t *testing.T) {
+	var sb strings.Builder
+	w := pron.NewCMUDictWriter(&sb, pron.CMUDictOptions{})
+	if err := w.Write(tomato()); err != nil {
+		t.Fatal(err)
+	}
+	// the same word from another part of speech continues numbering
+	if err := w.Write(&en.WordData{Word: "tomato", Pos: "adj", Sounds: []en.SoundData{
+		{Ipa: ptr("/təˈmeɪtoʊ/")},
+		{Ipa: ptr("/ʘ/")},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the flapped and aspirated variants map to the same phones as
+	// /təˈmeɪtoʊ/ and are written once
+	want := "TOMATO  T AH0 M AA1 T OW0\n" +
+		"TOMATO(2)  T AH0 M EY1 T OW0\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if w.Skipped() != 1 {
+		t.Errorf("Skipped() = %d, want 1", w.Skipped())
+	}
+}
+
+func TestCMUDictWriterAccents(t *testing.T) {
+	var sb strings.Builder
+	w := pron.NewCMUDictWriter(&sb, pron.CMUDictOptions{Accents: []string{"General-American"}, KeepCase: true})
+	if err := w.Write(tomato()); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	if got, want := sb.String(), "tomato  T AH0 M EY1 T OW0\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTSVWriter(t *testing.T) {
+	var sb strings.Builder
+	w := pron.NewTSVWriter(&sb, pron.TSVOptions{Header: true, Accents: []string{"general-american"}})
+	if err := w.Write(tomato()); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	want := "word\taccent\tipa\n" +
+		"tomato\tGeneral-American\ttəˈmeɪtoʊ\n" +
+		"tomato\tGeneral-American\ttəˈmeɪɾoʊ\n" +
+		"tomato\tGeneral-American\ttʰəˈmeɪɾoʊ\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package pron
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// TSVOptions configures a [TSVWriter].
+type TSVOptions struct {
+	// only export pronunciations tagged with one of these accents.
+	// Untagged pronunciations are always exported. Empty means all.
+	Accents []string
+	// write a `word	accent	ipa` header line first.
+	Header bool
+}
+
+// TSVWriter writes one `word<TAB>accent<TAB>ipa` line per
+// pronunciation. Accent tags are joined with commas and the IPA is
+// written without its delimiters.
+type TSVWriter struct {
+	w    *bufio.Writer
+	opts TSVOptions
+
+	wroteHeader bool
+}
+
+// NewTSVWriter returns a writer that writes TSV lines to `w`.
+func NewTSVWriter(w io.Writer, opts TSVOptions) *TSVWriter {
+	return &TSVWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+// Write appends the pronunciations of `word`.
+func (t *TSVWriter) Write(word *en.WordData) error {
+	if t.opts.Header && !t.wroteHeader {
+		if _, err := t.w.WriteString("word\taccent\tipa\n"); err != nil {
+			return err
+		}
+		t.wroteHeader = true
+	}
+	for _, p := range Collect(word) {
+		if !hasAccent(p.Accents, t.opts.Accents) {
+			continue
+		}
+		fields := []string{p.Word, strings.Join(p.Accents, ","), p.Ipa}
+		for i, f := range fields {
+			fields[i] = tsvEscaper.Replace(f)
+		}
+		if _, err := t.w.WriteString(strings.Join(fields, "\t") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (t *TSVWriter) Flush() error {
+	return t.w.Flush()
+}
+
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
@@ -0,0 +1,176 @@
+// Package render renders [en.WordData] entries as HTML or Markdown.
+//
+// Rendering goes through templates: the defaults can be replaced by
+// any template using [HTMLFuncs] or [MarkdownFuncs], executed with a
+// [View] of the entry.
+package render
+
+import (
+	"embed"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+type (
+	// Entry is the displayable content of an [en.WordData], with
+	// nested senses and resolved bold example spans.
+	Entry         = article.Entry
+	Sense         = article.Sense
+	Example       = article.Example
+	Span          = article.Span
+	Pronunciation = article.Pronunciation
+	Form          = article.Form
+)
+
+// Layout selects how much of an entry is shown.
+type Layout int
+
+const (
+	// headword, part of speech and senses
+	Compact Layout = iota
+	// also pronunciations, forms, examples, linkages, translations and
+	// etymology
+	Full
+)
+
+// Renderer renders entries to a writer.
+type Renderer interface {
+	Render(w io.Writer, word *en.WordData) error
+}
+
+//go:embed templates
+var templates embed.FS
+
+// HTMLRenderer renders entries with an [html/template].
+type HTMLRenderer struct {
+	Template *htmltemplate.Template
+	Layout   Layout
+}
+
+// NewHTML returns a renderer using the default HTML template.
+func NewHTML(layout Layout) *HTMLRenderer {
+	t := htmltemplate.Must(htmltemplate.New("entry.html.tmpl").Funcs(HTMLFuncs).ParseFS(templates, "templates/entry.html.tmpl"))
+	return &HTMLRenderer{Template: t, Layout: layout}
+}
+
+func (r *HTMLRenderer) Render(w io.Writer, word *en.WordData) error {
+	return r.Template.Execute(w, NewView(word, r.Layout))
+}
+
+// MarkdownRenderer renders entries with a [text/template] whose
+// output is CommonMark.
+type MarkdownRenderer struct {
+	Template *texttemplate.Template
+	Layout   Layout
+}
+
+// NewMarkdown returns a renderer using the default Markdown template.
+func NewMarkdown(layout Layout) *MarkdownRenderer {
+	t := texttemplate.Must(texttemplate.New("entry.md.tmpl").Funcs(MarkdownFuncs).ParseFS(templates, "templates/entry.md.tmpl"))
+	return &MarkdownRenderer{Template: t, Layout: layout}
+}
+
+func (r *MarkdownRenderer) Render(w io.Writer, word *en.WordData) error {
+	return r.Template.Execute(w, NewView(word, r.Layout))
+}
+
+// HTMLFuncs are the functions available to HTML templates.
+var HTMLFuncs = htmltemplate.FuncMap{
+	// bold spans as <b>, the rest escaped
+	"spans": func(spans []Span) htmltemplate.HTML { return htmltemplate.HTML(article.HTMLSpans(spans)) },
+	"label": article.Label,
+	"words": words,
+	"inc":   func(i int) int { return i + 1 },
+	"level": func(senses []*Sense, depth int, v *View) senseLevel { return senseLevel{senses, depth, v} },
+}
+
+// MarkdownFuncs are the functions available to Markdown templates.
+var MarkdownFuncs = texttemplate.FuncMap{
+	"md":     EscapeMarkdown,
+	"spans":  markdownSpans,
+	"label":  article.Label,
+	"words":  words,
+	"inc":    func(i int) int { return i + 1 },
+	"indent": func(depth int) string { return strings.Repeat("    ", depth) },
+	"level":  func(senses []*Sense, depth int, v *View) senseLevel { return senseLevel{senses, depth, v} },
+}
+
+// senseLevel is the argument of the recursive senses templates.
+type senseLevel struct {
+	Senses []*Sense
+	Depth  int
+	View   *View
+}
+
+// markdownEscaper escapes the characters that start inline markup.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `|`, `\|`, `~`, `\~`, `&`, `\&`,
+)
+
+// EscapeMarkdown escapes `s` for use as inline Markdown text. Line
+// breaks are replaced by spaces so that the text stays in its block,
+// and a block marker at its start is escaped so that the text does not
+// start a heading or a list item where it begins a line.
+func EscapeMarkdown(s string) string {
+	return escapeBlockStart(escapeInline(s))
+}
+
+func escapeInline(s string) string {
+	return markdownEscaper.Replace(strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+// escapeBlockStart escapes the marker of an ATX heading ("#"), a bullet
+// list item ("-", "+"), an ordered list item ("1." or "1)") or a setext
+// heading underline ("=") at the start of `s`.
+func escapeBlockStart(s string) string {
+	trimmed := strings.TrimLeft(s, " ")
+	i := len(s) - len(trimmed)
+	digits := len(trimmed) - len(strings.TrimLeft(trimmed, "0123456789"))
+	switch {
+	case trimmed == "":
+		return s
+	case digits == 0 && strings.ContainsRune("#-+=", rune(trimmed[0])):
+	// ordered list items have at most 9 digits
+	case digits > 0 && digits <= 9 && digits < len(trimmed) && (trimmed[digits] == '.' || trimmed[digits] == ')'):
+		i += digits
+	default:
+		return s
+	}
+	return s[:i] + `\` + s[i:]
+}
+
+func markdownSpans(spans []Span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		text := escapeInline(s.Text)
+		// emphasis may not start or end with whitespace
+		if trimmed := strings.TrimSpace(text); s.Bold && trimmed != "" {
+			start := strings.Index(text, trimmed)
+			text = text[:start] + "**" + trimmed + "**" + text[start+len(trimmed):]
+		}
+		sb.WriteString(text)
+	}
+	return escapeBlockStart(sb.String())
+}
+
+// words joins the words of linkages.
+func words(linkages []en.LinkageData) string {
+	ws := make([]string, 0, len(linkages))
+	for _, l := range linkages {
+		if l.Word != "" {
+			ws = append(ws, l.Word)
+		}
+	}
+	return strings.Join(ws, ", ")
+}
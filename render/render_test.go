@@ -0,0 +1,196 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"unicode"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/render"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func loadWords(t *testing.T) []en.WordData {
+	t.Helper()
+	b, err := os.ReadFile("testdata/words.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var words []en.WordData
+	if err := json.Unmarshal(b, &words); err != nil {
+		t.Fatal(err)
+	}
+	return words
+}
+
+// golden compares `got` with a file of testdata, or rewrites it with
+// -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n%s", name, got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	words := loadWords(t)
+	slugs := []string{"water", "tag", "markers"}
+	layouts := map[render.Layout]string{render.Compact: "compact", render.Full: "full"}
+	for layout, layoutName := range layouts {
+		renderers := map[string]render.Renderer{
+			"html": render.NewHTML(layout),
+			"md":   render.NewMarkdown(layout),
+			"txt":  render.NewTerminal(layout, 50, false),
+		}
+		for ext, r := range renderers {
+			for i := range words {
+				name := fmt.Sprintf("%s.%s.%s", slugs[i], layoutName, ext)
+				t.Run(name, func(t *testing.T) {
+					var buf bytes.Buffer
+					if err := r.Render(&buf, &words[i]); err != nil {
+						t.Fatal(err)
+					}
+					golden(t, name, buf.Bytes())
+				})
+			}
+		}
+	}
+}
+
+func TestCustomTemplate(t *testing.T) {
+	words := loadWords(t)
+	tmpl := template.Must(template.New("short").Funcs(render.MarkdownFuncs).Parse(
+		`{{md .Word}}: {{range $i, $s := .Senses}}{{if $i}}; {{end}}{{md $s.Gloss}}{{end}}`))
+	var sb strings.Builder
+	r := &render.MarkdownRenderer{Template: tmpl}
+	if err := r.Render(&sb, &words[1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\\<b\\>: A bold tag \\| not a pipe \\`code\\`."; sb.String() != want {
+		t.Errorf("got %s, want %s", sb.String(), want)
+	}
+}
+
+func TestTerminalWrap(t *testing.T) {
+	word := &en.WordData{
+		Word: "日本語", Pos: "noun",
+		Senses: []en.SenseData{
+			// e + combining acute accent must not be split
+			{Glosses: []string{"The Japanese language, spoken in Japan; cafe\u0301 cafe\u0301 cafe\u0301."}},
+			{
+				Glosses:  []string{"A wide example."},
+				Examples: []en.ExampleData{{Text: "日本語を話せますか。はい、少し話せます。", BoldTextOffsets: [][2]int{{0, 3}}}},
+			},
+			{Glosses: []string{"Supercalifragilisticexpialidocious"}},
+		},
+	}
+	var sb strings.Builder
+	if err := render.NewTerminal(render.Full, 20, false).Render(&sb, word); err != nil {
+		t.Fatal(err)
+	}
+	want := "日本語  noun\n" +
+		"  1. The Japanese\n" +
+		"     language,\n" +
+		"     spoken in\n" +
+		"     Japan; cafe\u0301\n" +
+		"     cafe\u0301 cafe\u0301.\n" +
+		"  2. A wide example.\n" +
+		"     » 日本語を話せ\n" +
+		"       ますか。は\n" +
+		"       い、少し話せ\n" +
+		"       ます。\n" +
+		"  3. Supercalifragil\n" +
+		"     isticexpialidoc\n" +
+		"     ious\n"
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+		if w := render.StringWidth(line); w > 20 {
+			t.Errorf("%q is %d columns wide", line, w)
+		}
+	}
+
+	sb.Reset()
+	if err := render.NewTerminal(render.Full, 80, true).Render(&sb, word); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"\x1b[1m日本語\x1b[0m  \x1b[2mnoun\x1b[0m\n", "» \x1b[1;33m日本語\x1b[0mを話せますか。"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("missing %q in %q", want, sb.String())
+		}
+	}
+}
+
+func TestTerminalControls(t *testing.T) {
+	word := &en.WordData{
+		Word: "clear\x1b[2J", Pos: "noun\u009b31m",
+		Senses: []en.SenseData{{Glosses: []string{"\x1b]8;;https://example.com\x07link\x1b]8;;\x07 and\ttab\x00"}}},
+	}
+	for _, color := range []bool{false, true} {
+		var sb strings.Builder
+		if err := render.NewTerminal(render.Full, 80, color).Render(&sb, word); err != nil {
+			t.Fatal(err)
+		}
+		// only the escape sequences of the styles remain
+		got := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(sb.String(), "")
+		if strings.ContainsFunc(strings.ReplaceAll(got, "\n", ""), unicode.IsControl) {
+			t.Errorf("control characters in %q", sb.String())
+		}
+		if !strings.Contains(got, "clear[2J  noun31m") || !strings.Contains(got, "]8;;https://example.com") || !strings.Contains(got, "and tab") {
+			t.Errorf("text lost in %q", got)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	for s, want := range map[string]int{
+		"abc":        3,
+		"café":       4,
+		"cafe\u0301": 4,
+		"日本":         4,
+		"ｱ":          1,
+		"Ａ":          2,
+		"한국어":        6,
+		"🙂":          2,
+		"a\u200db":   2,
+	} {
+		if got := render.StringWidth(s); got != want {
+			t.Errorf("StringWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	words := loadWords(t)
+	if v := render.NewView(&words[0], render.Compact); v.Linkages != nil || v.Translations != nil {
+		t.Error("compact views should not collect linkages and translations")
+	}
+	v := render.NewView(&words[0], render.Full)
+	if len(v.Linkages) != 2 || v.Linkages[0].Kind != "Synonyms" || v.Linkages[1].Kind != "Derived terms" {
+		t.Errorf("linkages = %+v", v.Linkages)
+	}
+	if len(v.Translations) != 2 || len(v.Translations[0].Translations) != 2 || v.Translations[1].Sense != "body of water" {
+		t.Errorf("translations = %+v", v.Translations)
+	}
+}
@@ -0,0 +1,311 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// style is an ANSI SGR parameter list, "" for unstyled text.
+type style string
+
+const (
+	plain     style = ""
+	bold      style = "1"
+	dim       style = "2"
+	italic    style = "3"
+	highlight style = "1;33"
+)
+
+// run is a piece of text in one style.
+type run struct {
+	text  string
+	style style
+}
+
+// TerminalRenderer renders entries as text for terminals, wrapped to
+// a number of columns. Wide East Asian characters count as two
+// columns, and combining marks stay with the character they modify.
+type TerminalRenderer struct {
+	// defaults to 80
+	Width int
+	// style the text with ANSI escape sequences
+	Color  bool
+	Layout Layout
+}
+
+// NewTerminal returns a terminal renderer.
+func NewTerminal(layout Layout, width int, color bool) *TerminalRenderer {
+	return &TerminalRenderer{Width: width, Color: color, Layout: layout}
+}
+
+// Render writes the headword in bold with its part of speech, then the
+// numbered senses with dimmed tags; the full layout adds
+// pronunciations, forms, examples with their bold spans highlighted,
+// linkages, translations and the etymology.
+func (r *TerminalRenderer) Render(w io.Writer, word *en.WordData) error {
+	v := NewView(word, r.Layout)
+	p := &printer{width: r.Width, color: r.Color}
+	if p.width <= 0 {
+		p.width = 80
+	}
+
+	head := []run{{v.Word, bold}}
+	if v.Pos != "" {
+		head = append(head, run{"  ", plain}, run{v.Pos, dim})
+	}
+	p.para("", "", head...)
+	if v.Full() {
+		for _, pr := range v.Pronunciations {
+			runs := []run{{pr.System + ": ", dim}, {pr.Text, plain}}
+			if l := article.Label(pr.Accents, ""); l != "" {
+				runs = append(runs, run{" " + l, dim})
+			}
+			p.para("  ", "    ", runs...)
+		}
+		if len(v.Forms) > 0 {
+			runs := []run{{"Forms: ", dim}}
+			for i, f := range v.Forms {
+				if i > 0 {
+					runs = append(runs, run{", ", plain})
+				}
+				runs = append(runs, run{f.Form, plain})
+				if l := article.Label(f.Tags, ""); l != "" {
+					runs = append(runs, run{" " + l, dim})
+				}
+			}
+			p.para("  ", "    ", runs...)
+		}
+	}
+	p.senses(v, v.Senses, "", 1)
+	if !v.Full() {
+		_, err := io.WriteString(w, p.sb.String())
+		return err
+	}
+
+	for _, l := range v.Linkages {
+		runs := []run{{l.Kind + ": ", dim}}
+		for i, lw := range l.Words {
+			if i > 0 {
+				runs = append(runs, run{", ", plain})
+			}
+			runs = append(runs, run{lw.Word, plain})
+			if lw.Roman != "" {
+				runs = append(runs, run{" (" + lw.Roman + ")", italic})
+			}
+			if lw.Label != "" {
+				runs = append(runs, run{" " + lw.Label, dim})
+			}
+		}
+		p.para("  ", "    ", runs...)
+	}
+	if len(v.Translations) > 0 {
+		p.para("  ", "  ", run{"Translations:", dim})
+		for _, g := range v.Translations {
+			var runs []run
+			if g.Sense != "" {
+				runs = append(runs, run{g.Sense + ": ", italic})
+			}
+			for i, t := range g.Translations {
+				if i > 0 {
+					runs = append(runs, run{"; ", plain})
+				}
+				runs = append(runs, run{t.Lang + ": ", dim}, run{t.Word, plain})
+				if t.Roman != "" {
+					runs = append(runs, run{" (" + t.Roman + ")", italic})
+				}
+				if t.Label != "" {
+					runs = append(runs, run{" " + t.Label, dim})
+				}
+			}
+			p.para("    ", "      ", runs...)
+		}
+	}
+	if v.Etymology != "" {
+		p.para("  ", "    ", run{"Etymology: ", dim}, run{v.Etymology, plain})
+	}
+	_, err := io.WriteString(w, p.sb.String())
+	return err
+}
+
+// senses prints numbered senses, indented by depth; wrapped lines
+// hang under the gloss.
+func (p *printer) senses(v *View, senses []*Sense, prefix string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for i, s := range senses {
+		number := fmt.Sprintf("%s%d.", prefix, i+1)
+		first := indent + number + " "
+		hang := strings.Repeat(" ", StringWidth(first))
+		var runs []run
+		if l := article.Label(s.Tags, s.Qualifier); l != "" {
+			runs = append(runs, run{l + " ", dim})
+		}
+		runs = append(runs, run{s.Gloss, plain})
+		p.para(first, hang, runs...)
+		if v.Full() {
+			for _, ex := range s.Examples {
+				runs := spanRuns(ex.Text)
+				if ex.Roman != "" {
+					runs = append(runs, run{" (" + ex.Roman + ")", italic})
+				}
+				if len(ex.Translation) > 0 {
+					runs = append(runs, run{" — ", plain})
+					runs = append(runs, spanRuns(ex.Translation)...)
+				}
+				p.para(hang+"» ", hang+"  ", runs...)
+			}
+		}
+		p.senses(v, s.Subsenses, number, depth+1)
+	}
+}
+
+func spanRuns(spans []Span) []run {
+	runs := make([]run, len(spans))
+	for i, s := range spans {
+		runs[i] = run{s.Text, plain}
+		if s.Bold {
+			runs[i].style = highlight
+		}
+	}
+	return runs
+}
+
+// printer wraps styled paragraphs.
+type printer struct {
+	sb    strings.Builder
+	width int
+	color bool
+}
+
+// cluster is a character with its combining marks.
+type cluster struct {
+	text  string
+	style style
+	width int
+	space bool
+	// wide characters can be broken around, as in CJK text
+	wide bool
+	// closing punctuation may not start a line
+	closing bool
+}
+
+// closingPunctuation may not start a line in CJK text.
+const closingPunctuation = "、。，．：；？！）」』】〕〉》ー"
+
+// para writes the runs as a paragraph whose first line starts with
+// `first` and the other lines with `rest`. Lines are broken at spaces
+// and around wide characters; words longer than a line are broken
+// between clusters.
+func (p *printer) para(first, rest string, runs ...run) {
+	var cs []cluster
+	for _, r := range runs {
+		// control characters from the data, such as escape sequences,
+		// must not reach the terminal
+		text := strings.Map(func(c rune) rune {
+			switch {
+			case c == '\n' || c == '\r' || c == '\t':
+				return ' '
+			case unicode.IsControl(c):
+				return -1
+			}
+			return c
+		}, r.text)
+		for text != "" {
+			n := nextCluster(text)
+			c, _ := utf8.DecodeRuneInString(text)
+			cs = append(cs, cluster{
+				text:  text[:n],
+				style: r.style,
+				width: StringWidth(text[:n]),
+				space: c == ' ',
+				wide:  RuneWidth(c) == 2,
+
+				closing: strings.ContainsRune(closingPunctuation, c),
+			})
+			text = text[n:]
+		}
+	}
+
+	prefix := first
+	var line []cluster
+	lineWidth := 0
+	written := false
+	avail := func() int { return max(p.width-StringWidth(prefix), 1) }
+	flush := func() {
+		for len(line) > 0 && line[len(line)-1].space {
+			line = line[:len(line)-1]
+		}
+		p.writeLine(prefix, line)
+		prefix, line, lineWidth = rest, nil, 0
+		written = true
+	}
+
+	for i := 0; i < len(cs); {
+		if cs[i].space {
+			// spaces at the start of a line are dropped
+			if lineWidth > 0 {
+				line = append(line, cs[i])
+				lineWidth += cs[i].width
+			}
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(cs) && !cs[j].space && (!cs[i].wide && !cs[j].wide || cs[j].closing) {
+			j++
+		}
+		word := cs[i:j]
+		i = j
+		for len(word) > 0 {
+			ww := 0
+			for _, c := range word {
+				ww += c.width
+			}
+			if lineWidth+ww <= avail() {
+				line = append(line, word...)
+				lineWidth += ww
+				break
+			}
+			if lineWidth > 0 {
+				flush()
+				continue
+			}
+			// longer than a line: take as many clusters as fit
+			k, w := 0, 0
+			for k < len(word) && (k == 0 || w+word[k].width <= avail()) {
+				w += word[k].width
+				k++
+			}
+			line = append(line, word[:k]...)
+			word = word[k:]
+			flush()
+		}
+	}
+	if len(line) > 0 || !written {
+		flush()
+	}
+}
+
+func (p *printer) writeLine(prefix string, line []cluster) {
+	p.sb.WriteString(prefix)
+	for i := 0; i < len(line); {
+		j := i
+		var text strings.Builder
+		for j < len(line) && line[j].style == line[i].style {
+			text.WriteString(line[j].text)
+			j++
+		}
+		if p.color && line[i].style != plain {
+			p.sb.WriteString("\x1b[" + string(line[i].style) + "m" + text.String() + "\x1b[0m")
+		} else {
+			p.sb.WriteString(text.String())
+		}
+		i = j
+	}
+	p.sb.WriteString("\n")
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// View is the data templates are executed with.
+type View struct {
+	*Entry
+	Layout Layout
+	// word-level linkages, in a fixed order of kinds
+	Linkages []Linkage
+	// translations grouped by the sense they translate
+	Translations []TranslationGroup
+}
+
+// Full reports whether the full layout is selected.
+func (v *View) Full() bool {
+	return v.Layout == Full
+}
+
+// Linkage lists the words of one kind of relation.
+type Linkage struct {
+	// e.g. "Synonyms"
+	Kind  string
+	Words []LinkedWord
+}
+
+type LinkedWord struct {
+	Word  string
+	Roman string
+	// tags and qualifier, e.g. "(archaic)"
+	Label string
+	// the sense the link applies to
+	Sense string
+}
+
+type TranslationGroup struct {
+	// the gloss of the translated sense, empty if not given
+	Sense        string
+	Translations []Translation
+}
+
+type Translation struct {
+	Lang string
+	Code string
+	Word string
+	// romanization
+	Roman string
+	// tags such as gender, e.g. "(masculine)"
+	Label string
+}
+
+// linkageKinds are the word-level linkages shown, in order.
+var linkageKinds = []struct {
+	kind string
+	get  func(*en.WordData) []en.LinkageData
+}{
+	{"Synonyms", func(w *en.WordData) []en.LinkageData { return w.Synonyms }},
+	{"Antonyms", func(w *en.WordData) []en.LinkageData { return w.Antonyms }},
+	{"Hypernyms", func(w *en.WordData) []en.LinkageData { return w.Hypernyms }},
+	{"Hyponyms", func(w *en.WordData) []en.LinkageData { return w.Hyponyms }},
+	{"Holonyms", func(w *en.WordData) []en.LinkageData { return w.Holonyms }},
+	{"Meronyms", func(w *en.WordData) []en.LinkageData { return w.Meronyms }},
+	{"Troponyms", func(w *en.WordData) []en.LinkageData { return w.Troponyms }},
+	{"Coordinate terms", func(w *en.WordData) []en.LinkageData { return w.CoordinateTerms }},
+	{"Abbreviations", func(w *en.WordData) []en.LinkageData { return w.Abbreviations }},
+	{"Derived terms", func(w *en.WordData) []en.LinkageData { return w.Derived }},
+	{"Related terms", func(w *en.WordData) []en.LinkageData { return w.Related }},
+}
+
+// NewView builds the view of `w`. Linkages and translations are only
+// collected for the full layout.
+func NewView(w *en.WordData, layout Layout) *View {
+	v := &View{Entry: article.New(w), Layout: layout}
+	if layout != Full {
+		return v
+	}
+	for _, k := range linkageKinds {
+		var ws []LinkedWord
+		for _, l := range k.get(w) {
+			if l.Word == "" {
+				continue
+			}
+			lw := LinkedWord{Word: l.Word, Sense: l.Sense}
+			if l.Roman != nil {
+				lw.Roman = *l.Roman
+			}
+			qualifier := ""
+			if l.Qualifier != nil {
+				qualifier = *l.Qualifier
+			}
+			lw.Label = article.Label(l.Tags, qualifier)
+			ws = append(ws, lw)
+		}
+		if len(ws) > 0 {
+			v.Linkages = append(v.Linkages, Linkage{Kind: k.kind, Words: ws})
+		}
+	}
+
+	groups := make(map[string]int)
+	for _, tr := range w.Translations {
+		if tr.Word == nil || *tr.Word == "" {
+			continue
+		}
+		sense := ""
+		if tr.Sense != nil {
+			sense = *tr.Sense
+		}
+		i, ok := groups[sense]
+		if !ok {
+			i = len(v.Translations)
+			groups[sense] = i
+			v.Translations = append(v.Translations, TranslationGroup{Sense: sense})
+		}
+		t := Translation{Lang: tr.Lang, Code: tr.LangCode, Word: *tr.Word, Label: article.Label(tr.Tags, "")}
+		if tr.Roman != nil {
+			t.Roman = *tr.Roman
+		}
+		v.Translations[i].Translations = append(v.Translations[i].Translations, t)
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wide are the East Asian Wide and Fullwidth ranges, which take two
+// terminal columns.
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1}, // Hangul Jamo initials
+		{0x231a, 0x231b, 1}, // watch, hourglass
+		{0x2329, 0x232a, 1}, // angle brackets
+		{0x2e80, 0x303e, 1}, // CJK radicals, punctuation
+		{0x3041, 0x33ff, 1}, // kana, CJK symbols
+		{0x3400, 0x4dbf, 1}, // CJK extension A
+		{0x4e00, 0x9fff, 1}, // CJK unified ideographs
+		{0xa000, 0xa4cf, 1}, // Yi
+		{0xa960, 0xa97f, 1}, // Hangul Jamo extended A
+		{0xac00, 0xd7a3, 1}, // Hangul syllables
+		{0xf900, 0xfaff, 1}, // CJK compatibility ideographs
+		{0xfe10, 0xfe19, 1}, // vertical forms
+		{0xfe30, 0xfe6f, 1}, // CJK compatibility forms
+		{0xff00, 0xff60, 1}, // fullwidth forms
+		{0xffe0, 0xffe6, 1}, // fullwidth signs
+	},
+	R32: []unicode.Range32{
+		{0x16fe0, 0x18cff, 1}, // Tangut, Khitan
+		{0x1b000, 0x1b2ff, 1}, // kana supplement, Nüshu
+		{0x1f300, 0x1f64f, 1}, // pictographs, emoticons
+		{0x1f680, 0x1f6ff, 1}, // transport symbols
+		{0x1f900, 0x1f9ff, 1}, // supplemental pictographs
+		{0x20000, 0x3fffd, 1}, // CJK extensions B and later
+	},
+}
+
+// RuneWidth returns the number of terminal columns taken by `r`: 0 for
+// combining marks, format and control characters, 2 for wide East
+// Asian characters and 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc) ||
+		r >= 0x1160 && r <= 0x11ff: // Hangul medial vowels and finals
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal columns taken by `s`,
+// which must not contain escape sequences.
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// nextCluster returns the length in bytes of the first cluster of `s`:
+// a rune followed by the zero-width runes attached to it.
+func nextCluster(s string) int {
+	_, n := utf8.DecodeRuneInString(s)
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if RuneWidth(r) != 0 || r == '\n' || r == '\t' {
+			break
+		}
+		n += size
+	}
+	return n
+}
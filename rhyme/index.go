@@ -0,0 +1,204 @@
+package rhyme
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// entry is a word pronounced a given way in some accents.
+type entry struct {
+	word    string
+	accents []string // canonical accents; empty means any accent
+}
+
+// Index groups the words of a language by rhyme and links homophones.
+// The zero value is not usable; create one with [NewIndex].
+type Index struct {
+	langCode string
+	rhymes   map[string][]entry // rhyme -> words
+	keys     map[string][]entry // word -> rhymes (entry.word is the rhyme)
+	homo     map[string][]entry // word -> homophones
+}
+
+// NewIndex returns an empty index of the words of the language
+// `langCode`, e.g. "en". Rhymes and homophones are only meaningful within
+// a language, so [Index.Add] skips the words of the others.
+func NewIndex(langCode string) *Index {
+	return &Index{
+		langCode: langCode,
+		rhymes:   make(map[string][]entry),
+		keys:     make(map[string][]entry),
+		homo:     make(map[string][]entry),
+	}
+}
+
+// Add indexes the pronunciations of `w`. Declared rhymes are used when
+// the entry has any; otherwise rhymes are derived from its phonemic
+// transcriptions with [Derive]. Declared homophones are linked in both
+// directions. Words of another language are skipped.
+func (ix *Index) Add(w *en.WordData) {
+	if w.LangCode != ix.langCode {
+		return
+	}
+	declared := false
+	for _, s := range w.Sounds {
+		if s.Rhymes == nil {
+			continue
+		}
+		for _, r := range strings.Split(*s.Rhymes, ",") {
+			if key, ok := Normalize(r); ok {
+				ix.addRhyme(w.Word, key, accents(s.Tags))
+				declared = true
+			}
+		}
+	}
+	if !declared {
+		for _, s := range w.Sounds {
+			if s.Ipa == nil || strings.HasPrefix(strings.TrimSpace(*s.Ipa), "[") {
+				continue
+			}
+			if key, ok := Derive(*s.Ipa); ok {
+				ix.addRhyme(w.Word, key, accents(s.Tags))
+			}
+		}
+	}
+
+	for _, s := range w.Sounds {
+		if s.Homophone == nil || *s.Homophone == w.Word {
+			continue
+		}
+		as := accents(s.Tags)
+		add(ix.homo, w.Word, entry{*s.Homophone, as})
+		add(ix.homo, *s.Homophone, entry{w.Word, as})
+	}
+}
+
+func (ix *Index) addRhyme(word, key string, as []string) {
+	add(ix.rhymes, key, entry{word, as})
+	add(ix.keys, word, entry{key, as})
+}
+
+// add appends `e` to m[k] unless an entry for the same word already
+// covers its accents. Accent restrictions of duplicate entries are
+// merged, and an unrestricted entry replaces restricted ones.
+func add(m map[string][]entry, k string, e entry) {
+	for i, old := range m[k] {
+		if old.word != e.word {
+			continue
+		}
+		switch {
+		case len(old.accents) == 0:
+		case len(e.accents) == 0:
+			m[k][i].accents = nil
+		default:
+			for _, a := range e.accents {
+				if !slices.Contains(old.accents, a) {
+					m[k][i].accents = append(m[k][i].accents, a)
+				}
+			}
+		}
+		return
+	}
+	m[k] = append(m[k], e)
+}
+
+// collect returns the sorted words of `entries` valid in `accent`,
+// except `exclude`.
+func collect(entries []entry, accent, exclude string) []string {
+	var words []string
+	for _, e := range entries {
+		if e.word != exclude && matches(e.accents, accent) && !slices.Contains(words, e.word) {
+			words = append(words, e.word)
+		}
+	}
+	slices.Sort(words)
+	return words
+}
+
+// RhymesOf returns the rhymes of `word` in `accent` (e.g. "US" or
+// "General-American"), or in any accent if `accent` is empty.
+func (ix *Index) RhymesOf(word, accent string) []string {
+	return collect(ix.keys[word], accent, "")
+}
+
+// Words returns the words having `rhyme` in `accent`. The rhyme may be
+// given as it appears on Wiktionary, e.g. "-ɔːtə(ɹ)".
+func (ix *Index) Words(rhyme, accent string) []string {
+	key, ok := Normalize(rhyme)
+	if !ok {
+		return nil
+	}
+	return collect(ix.rhymes[key], accent, "")
+}
+
+// Rhymes returns the words that rhyme with `word` in `accent`, sorted
+// and without `word` itself.
+func (ix *Index) Rhymes(word, accent string) []string {
+	var words []string
+	for _, key := range ix.RhymesOf(word, accent) {
+		for _, w := range collect(ix.rhymes[key], accent, word) {
+			if !slices.Contains(words, w) {
+				words = append(words, w)
+			}
+		}
+	}
+	slices.Sort(words)
+	return words
+}
+
+// Homophones returns the homophones of `word` in `accent`, or in any
+// accent if `accent` is empty, sorted. Homophony is transitive within
+// an accent, so the whole group is returned even if only some pairs are
+// declared, but pairs declared for different accents are not chained.
+func (ix *Index) Homophones(word, accent string) []string {
+	var seen map[string]bool
+	if accent != "" {
+		seen = ix.homophones(word, func(as []string) bool { return matches(as, accent) })
+	} else {
+		// the union of the groups of every accent of the pairs reachable
+		// from `word`
+		seen = ix.homophones(word, func(as []string) bool { return len(as) == 0 })
+		var accents []string
+		for w := range ix.homophones(word, func([]string) bool { return true }) {
+			for _, e := range ix.homo[w] {
+				for _, a := range e.accents {
+					if !slices.Contains(accents, a) {
+						accents = append(accents, a)
+					}
+				}
+			}
+		}
+		for _, a := range accents {
+			for w := range ix.homophones(word, func(as []string) bool { return matches(as, a) }) {
+				seen[w] = true
+			}
+		}
+	}
+	delete(seen, word)
+	words := make([]string, 0, len(seen))
+	for w := range seen {
+		words = append(words, w)
+	}
+	slices.Sort(words)
+	return words
+}
+
+// homophones returns `word` and the words linked to it by pairs whose
+// accents satisfy `follow`.
+func (ix *Index) homophones(word string, follow func(accents []string) bool) map[string]bool {
+	seen := map[string]bool{word: true}
+	queue := []string{word}
+	for len(queue) > 0 {
+		w := queue[0]
+		queue = queue[1:]
+		for _, e := range ix.homo[w] {
+			if !seen[e.word] && follow(e.accents) {
+				seen[e.word] = true
+				queue = append(queue, e.word)
+			}
+		}
+	}
+	return seen
+}
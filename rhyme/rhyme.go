@@ -0,0 +1,123 @@
+// Package rhyme indexes words by rhyme and homophony using the
+// `rhymes`, `homophone` and `ipa` fields of [en.SoundData].
+package rhyme
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/ipa"
+)
+
+// accentAliases maps accent tags to a canonical name, so that a query
+// for "US" also matches pronunciations tagged "General-American".
+var accentAliases = map[string]string{
+	"us":                     "US",
+	"general-american":       "US",
+	"genam":                  "US",
+	"ga":                     "US",
+	"american":               "US",
+	"uk":                     "UK",
+	"received-pronunciation": "UK",
+	"rp":                     "UK",
+	"british":                "UK",
+	"australia":              "Australia",
+	"general-australian":     "Australia",
+	"canada":                 "Canada",
+	"canadian":               "Canada",
+	"new-zealand":            "New-Zealand",
+	"ireland":                "Ireland",
+	"irish":                  "Ireland",
+	"scotland":               "Scotland",
+	"scottish":               "Scotland",
+	"wales":                  "Wales",
+	"welsh":                  "Wales",
+	"northern-england":       "Northern-England",
+	"southern-england":       "Southern-England",
+	"india":                  "India",
+	"indian":                 "India",
+	"south-africa":           "South-Africa",
+}
+
+// Accent returns the canonical name of an accent tag, and false for
+// tags that do not name an accent (e.g. "noun" on a pronunciation that
+// depends on the part of speech).
+func Accent(tag string) (string, bool) {
+	canonical, ok := accentAliases[strings.ToLower(tag)]
+	return canonical, ok
+}
+
+// accents returns the canonical accents among `tags`. An empty result
+// means the pronunciation is not restricted to any accent.
+func accents(tags []string) []string {
+	var as []string
+	for _, tag := range tags {
+		if a, ok := Accent(tag); ok && !slices.Contains(as, a) {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
+// matches reports whether a pronunciation restricted to `as` is valid
+// in `accent`. An empty `accent` matches every pronunciation.
+func matches(as []string, accent string) bool {
+	if accent == "" || len(as) == 0 {
+		return true
+	}
+	if canonical, ok := Accent(accent); ok {
+		accent = canonical
+	}
+	return slices.Contains(as, accent)
+}
+
+// Derive computes the rhyme of an IPA transcription: the phonemes from
+// the nucleus of the last stressed syllable onward, e.g. "ɔːtəɹ" for
+// "/ˈwɔːtə(ɹ)/". Optional segments are kept. A transcription without
+// stress marks only has a rhyme if it is a single syllable.
+func Derive(transcription string) (string, bool) {
+	t, err := ipa.Parse(transcription)
+	if err != nil {
+		return "", false
+	}
+	syllables := t.Variants()[0].Syllables()
+	stressed := -1
+	for i, s := range syllables {
+		if s.Stress == ipa.Primary {
+			stressed = i
+		}
+	}
+	if stressed < 0 && len(syllables) == 1 {
+		stressed = 0
+	}
+	if stressed < 0 {
+		return "", false
+	}
+	nucleus := syllables[stressed].Nucleus()
+	if nucleus < 0 {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for i, s := range syllables[stressed:] {
+		phonemes := s.Phonemes
+		if i == 0 {
+			phonemes = phonemes[nucleus:]
+		}
+		for _, p := range phonemes {
+			sb.WriteString(p.Text)
+		}
+	}
+	return sb.String(), true
+}
+
+// Normalize turns a declared rhyme such as "-ɔːtə(ɹ)" into the key used
+// by the index, in the same form as [Derive] returns.
+func Normalize(rhyme string) (string, bool) {
+	t, err := ipa.Parse(strings.TrimSpace(rhyme))
+	if err != nil {
+		return "", false
+	}
+	key := strings.Join(t.Variants()[0].Phonemes(), "")
+	return key, key != ""
+}
@@ -0,0 +1,153 @@
+package rhyme_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/rhyme"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func word(w string, sounds ...en.SoundData) *en.WordData {
+	return &en.WordData{Word: w, Lang: "English", LangCode: "en", Pos: "noun", Sounds: sounds}
+}
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		ipa  string
+		want string
+		ok   bool
+	}{
+		{"/ˈwɔːtə(ɹ)/", "ɔːtəɹ", true},
+		{"/ˌɪn.fəˈmeɪ.ʃən/", "eɪʃən", true},
+		{"/kæt/", "æt", true},
+		{"/ˈkæt/", "æt", true},
+		{"/ˈstɹɛŋkθ/", "ɛŋkθ", true},
+		{"/təˈmeɪtoʊ/", "eɪtoʊ", true},
+		{"/ˈbʌt.n̩/", "ʌtn̩", true},
+		{"/bʌtn̩/", "", false},
+		{"/a(b/", "", false},
+		{"/ˈ" + strings.Repeat("a(b)", 64) + "/", strings.Repeat("ab", 64), true},
+	}
+	for _, tt := range tests {
+		got, ok := rhyme.Derive(tt.ipa)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Derive(%q) = %q, %v; want %q, %v", tt.ipa, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, r := range []string{"-ɔːtə(ɹ)", "-ɔːtəɹ", "ɔː.təɹ"} {
+		if got, _ := rhyme.Normalize(r); got != "ɔːtəɹ" {
+			t.Errorf("Normalize(%q) = %q", r, got)
+		}
+	}
+}
+
+func newIndex() *rhyme.Index {
+	ix := rhyme.NewIndex("en")
+	// declared rhymes
+	ix.Add(word("tomato",
+		en.SoundData{Ipa: ptr("/təˈmɑːtəʊ/"), Tags: []string{"Received-Pronunciation"}},
+		en.SoundData{Ipa: ptr("/təˈmeɪtoʊ/"), Tags: []string{"General-American"}},
+		en.SoundData{Rhymes: ptr("-ɑːtəʊ"), Tags: []string{"UK"}},
+		en.SoundData{Rhymes: ptr("-eɪtoʊ"), Tags: []string{"US"}},
+	))
+	ix.Add(word("potato",
+		en.SoundData{Rhymes: ptr("-eɪtəʊ")},
+		en.SoundData{Rhymes: ptr("-eɪtoʊ"), Tags: []string{"US"}},
+	))
+	// derived from IPA only
+	ix.Add(word("staccato", en.SoundData{Ipa: ptr("/stəˈkɑːtəʊ/"), Tags: []string{"UK"}}))
+	ix.Add(word("Plato", en.SoundData{Ipa: ptr("/ˈpleɪtoʊ/"), Tags: []string{"US"}}))
+	// declared rhymes win over the IPA
+	ix.Add(word("cat",
+		en.SoundData{Ipa: ptr("/kæt/")},
+		en.SoundData{Rhymes: ptr("-æt")},
+	))
+	ix.Add(word("hat", en.SoundData{Ipa: ptr("[hæt]")}, en.SoundData{Ipa: ptr("/hæt/")}))
+
+	// homophones
+	ix.Add(word("pair", en.SoundData{Homophone: ptr("pear")}, en.SoundData{Homophone: ptr("pare")}))
+	ix.Add(word("caught", en.SoundData{Homophone: ptr("cot"), Tags: []string{"cot-caught-merger", "US"}}))
+	ix.Add(word("court", en.SoundData{Homophone: ptr("caught"), Tags: []string{"Received-Pronunciation"}}))
+
+	// other languages are skipped
+	bass := word("bass", en.SoundData{Ipa: ptr("/bas/")}, en.SoundData{Homophone: ptr("basse")})
+	bass.Lang, bass.LangCode = "French", "fr"
+	ix.Add(bass)
+	ix.Add(word("bass", en.SoundData{Ipa: ptr("/beɪs/")}, en.SoundData{Homophone: ptr("base")}))
+	ix.Add(word("face", en.SoundData{Ipa: ptr("/feɪs/")}))
+	ix.Add(&en.WordData{Word: "glace", Lang: "French", LangCode: "fr", Sounds: []en.SoundData{{Ipa: ptr("/ɡlas/")}}})
+	return ix
+}
+
+func TestRhymes(t *testing.T) {
+	ix := newIndex()
+	tests := []struct {
+		word, accent string
+		want         []string
+	}{
+		{"tomato", "US", []string{"Plato", "potato"}},
+		{"tomato", "General-American", []string{"Plato", "potato"}},
+		{"tomato", "UK", []string{"staccato"}},
+		{"tomato", "", []string{"Plato", "potato", "staccato"}},
+		{"potato", "UK", nil},
+		{"cat", "", []string{"hat"}},
+		{"hat", "US", []string{"cat"}},
+		{"bass", "", []string{"face"}},
+		{"glace", "", nil},
+		{"unknown", "", nil},
+	}
+	for _, tt := range tests {
+		if got := ix.Rhymes(tt.word, tt.accent); !slices.Equal(got, tt.want) {
+			t.Errorf("Rhymes(%q, %q) = %q, want %q", tt.word, tt.accent, got, tt.want)
+		}
+	}
+
+	if got := ix.RhymesOf("potato", ""); !slices.Equal(got, []string{"eɪtoʊ", "eɪtəʊ"}) {
+		t.Errorf("RhymesOf(potato) = %q", got)
+	}
+	if got := ix.Words("-eɪtoʊ", "US"); !slices.Equal(got, []string{"Plato", "potato", "tomato"}) {
+		t.Errorf("Words(-eɪtoʊ) = %q", got)
+	}
+}
+
+func TestHomophones(t *testing.T) {
+	ix := newIndex()
+	tests := []struct {
+		word, accent string
+		want         []string
+	}{
+		{"pear", "", []string{"pair", "pare"}},
+		{"pare", "US", []string{"pair", "pear"}},
+		{"cot", "US", []string{"caught"}},
+		{"cot", "UK", nil},
+		{"caught", "UK", []string{"court"}},
+		// court~caught in the UK and caught~cot in the US do not make
+		// court a homophone of cot
+		{"court", "", []string{"caught"}},
+		{"cot", "", []string{"caught"}},
+		{"caught", "", []string{"cot", "court"}},
+		{"bass", "", []string{"base"}},
+		{"basse", "", nil},
+	}
+	for _, tt := range tests {
+		if got := ix.Homophones(tt.word, tt.accent); !slices.Equal(got, tt.want) {
+			t.Errorf("Homophones(%q, %q) = %q, want %q", tt.word, tt.accent, got, tt.want)
+		}
+	}
+}
+
+func TestAccent(t *testing.T) {
+	if a, ok := rhyme.Accent("General-American"); !ok || a != "US" {
+		t.Errorf("Accent(General-American) = %q, %v", a, ok)
+	}
+	if _, ok := rhyme.Accent("noun"); ok {
+		t.Error("noun is not an accent")
+	}
+}
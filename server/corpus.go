@@ -0,0 +1,391 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/mmap"
+)
+
+// record locates an entry in the corpus data, with the fields needed to
+// refer to it without decoding it.
+type record struct {
+	off      int64
+	n        int32
+	word     string
+	lang     string
+	langCode string
+	pos      string
+}
+
+// completion is a headword of the autocomplete list, sorted by key.
+type completion struct {
+	// lower-cased word
+	key  string
+	word string
+}
+
+// formRef is an entry having a form, with the tags of the form.
+type formRef struct {
+	entry int32
+	tags  []string
+}
+
+// Corpus is an indexed collection of [en.WordData] entries. Entries
+// are kept as their JSON lines and decoded when they are served.
+type Corpus struct {
+	data  []byte
+	unmap func() error
+	// hash of the data, used in ETags
+	version string
+
+	records []record
+	// by lang_code + "\x00" + word
+	entries map[string][]int32
+	// by lang_code, and all languages under ""
+	completions map[string][]completion
+	// by form
+	forms map[string][]formRef
+	// by lower-cased gloss token
+	tokens map[string][]int32
+	// by lang_code + "\x00" + pos, and "" + "\x00" + pos for all
+	// languages
+	pos map[string][]int32
+	// by category
+	categories map[string][]int32
+	// by lang_code
+	langs map[string][]int32
+}
+
+// indexFields are the fields decoded to index an entry.
+type indexFields struct {
+	Word       string   `json:"word"`
+	Lang       string   `json:"lang"`
+	LangCode   string   `json:"lang_code"`
+	Pos        string   `json:"pos"`
+	Categories []string `json:"categories"`
+	Forms      []struct {
+		Form string   `json:"form"`
+		Tags []string `json:"tags"`
+	} `json:"forms"`
+	Senses []struct {
+		Glosses    []string `json:"glosses"`
+		Categories []string `json:"categories"`
+	} `json:"senses"`
+}
+
+// Open indexes the JSONL file `name`. Uncompressed files are memory
+// mapped where the platform allows it; gzip and bzip2 files are
+// decompressed into memory.
+func Open(name string) (*Corpus, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var magic [3]byte
+	n, _ := io.ReadFull(f, magic[:])
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if n >= 2 && magic[0] == 0x1f && magic[1] == 0x8b || n == 3 && string(magic[:]) == "BZh" {
+		return Load(f)
+	}
+	data, unmap, err := mmap.Map(f)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCorpus(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	c.unmap = unmap
+	return c, nil
+}
+
+// Load reads and indexes JSONL data, decompressing gzip and bzip2
+// streams.
+func Load(r io.Reader) (*Corpus, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(data, []byte("BZh")):
+		if data, err = io.ReadAll(bzip2.NewReader(bytes.NewReader(data))); err != nil {
+			return nil, err
+		}
+	}
+	return newCorpus(data)
+}
+
+func newCorpus(data []byte) (*Corpus, error) {
+	c := &Corpus{
+		data:        data,
+		entries:     make(map[string][]int32),
+		completions: make(map[string][]completion),
+		forms:       make(map[string][]formRef),
+		tokens:      make(map[string][]int32),
+		pos:         make(map[string][]int32),
+		categories:  make(map[string][]int32),
+		langs:       make(map[string][]int32),
+	}
+	sum := sha256.Sum256(data)
+	c.version = fmt.Sprintf("%x", sum[:8])
+
+	// interned language names and parts of speech
+	strs := make(map[string]string)
+	intern := func(s string) string {
+		if v, ok := strs[s]; ok {
+			return v
+		}
+		strs[s] = s
+		return s
+	}
+	words := make(map[string]map[string]bool)
+
+	lineNo := 0
+	for off := 0; off < len(data); {
+		end := bytes.IndexByte(data[off:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += off
+		}
+		line := data[off:end]
+		start := off
+		off = end + 1
+		lineNo++
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var f indexFields
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		id := int32(len(c.records))
+		c.records = append(c.records, record{
+			off:      int64(start),
+			n:        int32(len(line)),
+			word:     f.Word,
+			lang:     intern(f.Lang),
+			langCode: intern(f.LangCode),
+			pos:      intern(f.Pos),
+		})
+		c.entries[f.LangCode+"\x00"+f.Word] = append(c.entries[f.LangCode+"\x00"+f.Word], id)
+		c.langs[f.LangCode] = append(c.langs[f.LangCode], id)
+		c.pos[f.LangCode+"\x00"+f.Pos] = append(c.pos[f.LangCode+"\x00"+f.Pos], id)
+		c.pos["\x00"+f.Pos] = append(c.pos["\x00"+f.Pos], id)
+		if words[f.LangCode] == nil {
+			words[f.LangCode] = make(map[string]bool)
+		}
+		words[f.LangCode][f.Word] = true
+
+		cats := make(map[string]bool)
+		for _, cat := range f.Categories {
+			cats[cat] = true
+		}
+		forms := make(map[string]bool)
+		for _, fm := range f.Forms {
+			if fm.Form == f.Word || forms[fm.Form] || !article.ShowForm(en.FormData{Form: fm.Form, Tags: fm.Tags}) {
+				continue
+			}
+			forms[fm.Form] = true
+			c.forms[fm.Form] = append(c.forms[fm.Form], formRef{id, fm.Tags})
+		}
+		tokens := make(map[string]bool)
+		for _, s := range f.Senses {
+			for _, cat := range s.Categories {
+				cats[cat] = true
+			}
+			for _, g := range s.Glosses {
+				for _, t := range tokenize(g) {
+					tokens[t] = true
+				}
+			}
+		}
+		for cat := range cats {
+			c.categories[cat] = append(c.categories[cat], id)
+		}
+		for t := range tokens {
+			c.tokens[t] = append(c.tokens[t], id)
+		}
+	}
+
+	all := make(map[string]bool)
+	for code, ws := range words {
+		for w := range ws {
+			all[w] = true
+		}
+		c.completions[code] = sortedCompletions(ws)
+	}
+	c.completions[""] = sortedCompletions(all)
+	return c, nil
+}
+
+func sortedCompletions(words map[string]bool) []completion {
+	cs := make([]completion, 0, len(words))
+	for w := range words {
+		cs = append(cs, completion{strings.ToLower(w), w})
+	}
+	slices.SortFunc(cs, func(a, b completion) int {
+		return cmp.Or(cmp.Compare(a.key, b.key), cmp.Compare(a.word, b.word))
+	})
+	return cs
+}
+
+// tokenize splits text into lower-cased words.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Close releases the memory mapping, if any.
+func (c *Corpus) Close() error {
+	if c.unmap == nil {
+		return nil
+	}
+	err := c.unmap()
+	c.unmap, c.data = nil, nil
+	return err
+}
+
+// Len returns the number of entries.
+func (c *Corpus) Len() int {
+	return len(c.records)
+}
+
+// Entry decodes the entry `id`.
+func (c *Corpus) Entry(id int) (*en.WordData, error) {
+	r := c.records[id]
+	w := new(en.WordData)
+	if err := json.Unmarshal(c.data[r.off:r.off+int64(r.n)], w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Lookup returns the entries of `word` in the language `langCode`.
+func (c *Corpus) Lookup(langCode, word string) []int {
+	return ints(c.entries[langCode+"\x00"+word])
+}
+
+// FormMatch is an entry having a searched form.
+type FormMatch struct {
+	ID int
+	// tags of the form, e.g. ["plural"]
+	Tags []string
+}
+
+// Forms returns the entries listing `form` as one of their forms, in
+// the language `langCode` or all languages if it is empty.
+func (c *Corpus) Forms(langCode, form string) []FormMatch {
+	var ms []FormMatch
+	for _, f := range c.forms[form] {
+		if langCode == "" || c.records[f.entry].langCode == langCode {
+			ms = append(ms, FormMatch{int(f.entry), f.tags})
+		}
+	}
+	return ms
+}
+
+// Complete returns up to `limit` headwords starting with `prefix`,
+// ignoring case, in the language `langCode` or all languages if it is
+// empty.
+func (c *Corpus) Complete(langCode, prefix string, limit int) []string {
+	cs := c.completions[langCode]
+	key := strings.ToLower(prefix)
+	i, _ := slices.BinarySearchFunc(cs, key, func(c completion, key string) int {
+		return cmp.Compare(c.key, key)
+	})
+	var words []string
+	for ; i < len(cs) && len(words) < limit && strings.HasPrefix(cs[i].key, key); i++ {
+		words = append(words, cs[i].word)
+	}
+	return words
+}
+
+// Search returns the entries whose glosses contain every word of
+// `query`, ignoring case, in corpus order.
+func (c *Corpus) Search(query string) []int {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	lists := make([][]int32, len(terms))
+	for i, t := range terms {
+		lists[i] = c.tokens[t]
+	}
+	return ints(intersect(lists...))
+}
+
+// List returns the entries matching all the non-empty arguments, in
+// corpus order.
+func (c *Corpus) List(langCode, pos, category string) []int {
+	var lists [][]int32
+	switch {
+	case pos != "":
+		lists = append(lists, c.pos[langCode+"\x00"+pos])
+	case langCode != "":
+		lists = append(lists, c.langs[langCode])
+	}
+	if category != "" {
+		lists = append(lists, c.categories[category])
+	}
+	if len(lists) == 0 {
+		all := make([]int, len(c.records))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	return ints(intersect(lists...))
+}
+
+// intersect returns the ids in all the sorted lists.
+func intersect(lists ...[]int32) []int32 {
+	slices.SortFunc(lists, func(a, b []int32) int { return cmp.Compare(len(a), len(b)) })
+	result := lists[0]
+	for _, l := range lists[1:] {
+		var next []int32
+		for _, id := range result {
+			if _, ok := slices.BinarySearch(l, id); ok {
+				next = append(next, id)
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+func ints(ids []int32) []int {
+	r := make([]int, len(ids))
+	for i, id := range ids {
+		r[i] = int(id)
+	}
+	return r
+}
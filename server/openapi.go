@@ -0,0 +1,196 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// OpenAPI returns the OpenAPI 3.1 document of the API. The schemas of
+// the responses are generated from their Go types, following the rules
+// of encoding/json: fields without omitempty are required, and may be
+// null if they are pointers, or slices or maps without a MarshalJSON
+// method. Named struct types are shared components.
+func OpenAPI() map[string]any {
+	g := &schemaGenerator{schemas: make(map[string]any)}
+	lang := param("lang", "query", "language code, e.g. \"en\"; all languages if omitted", false, "string")
+	offset := param("offset", "query", "index of the first result", false, "integer")
+	limit := param("limit", "query", "number of results, at most 200", false, "integer")
+
+	paths := map[string]any{
+		"/entries/{lang}/{word}": get("Entries of a word in a language",
+			g.ref(reflect.TypeFor[EntriesResponse]()),
+			param("lang", "path", "language code, e.g. \"en\"", true, "string"),
+			param("word", "path", "headword", true, "string")),
+		"/complete": get("Headwords starting with a prefix, ignoring case",
+			g.ref(reflect.TypeFor[CompleteResponse]()),
+			param("prefix", "query", "start of the headwords", true, "string"), lang, limit),
+		"/forms/{form}": get("Entries listing an inflected or alternative form",
+			g.ref(reflect.TypeFor[FormsResponse]()),
+			param("form", "path", "the form, e.g. \"waters\"", true, "string"), lang),
+		"/search": get("Entries whose glosses contain all the words of a query",
+			g.ref(reflect.TypeFor[SearchResponse]()),
+			param("q", "query", "words to search", true, "string"), lang, offset, limit),
+		"/list": get("Entries by part of speech and category, in corpus order",
+			g.ref(reflect.TypeFor[ListResponse]()),
+			lang,
+			param("pos", "query", "part of speech, e.g. \"noun\"", false, "string"),
+			param("category", "query", "category of the entry or of one of its senses", false, "string"),
+			offset, limit),
+		"/openapi.json": get("This document", map[string]any{"type": "object"}),
+	}
+	errorRef := g.ref(reflect.TypeFor[Error]())
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "Wiktionary lookup API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "error",
+					"content":     map[string]any{"application/json": map[string]any{"schema": errorRef}},
+				},
+			},
+		},
+	}
+}
+
+func param(name, in, description string, required bool, typ string) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"required":    required,
+		"schema":      map[string]any{"type": typ},
+	}
+}
+
+func get(summary string, schema map[string]any, params ...map[string]any) map[string]any {
+	errorRef := map[string]any{"$ref": "#/components/responses/Error"}
+	op := map[string]any{
+		"summary": summary,
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "OK",
+				"headers": map[string]any{
+					"ETag": map[string]any{"schema": map[string]any{"type": "string"}},
+				},
+				"content": map[string]any{"application/json": map[string]any{"schema": schema}},
+			},
+			"304": map[string]any{"description": "Not Modified: the If-None-Match ETag is current"},
+			"400": errorRef,
+			"404": errorRef,
+		},
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return map[string]any{"get": op}
+}
+
+// schemaGenerator generates JSON schemas of Go types.
+type schemaGenerator struct {
+	// components by type name
+	schemas map[string]any
+}
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// ref returns the schema of `t`, referring to the components for named
+// struct types.
+func (g *schemaGenerator) ref(t reflect.Type) map[string]any {
+	if t == rawMessageType {
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.ref(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// set first for recursive types
+			g.schemas[t.Name()] = nil
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.ref(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": g.ref(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.ref(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+// object returns the schema of a struct type.
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	required := []string{}
+	g.fields(t, props, &required)
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// fields adds the properties of the fields of `t`, inlining embedded
+// structs without a JSON name as encoding/json does.
+func (g *schemaGenerator) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema := g.ref(ft)
+		optional := strings.Contains(","+opts+",", ",omitempty,") || strings.Contains(","+opts+",", ",omitzero,")
+		// encoding/json writes nil pointers, slices and maps as null,
+		// except for slices and maps encoding themselves, as the en types
+		// that never write null do
+		k := ft.Kind()
+		if !optional && (k == reflect.Pointer || (k == reflect.Slice || k == reflect.Map) && !ft.Implements(marshalerType)) {
+			schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+		}
+		props[name] = schema
+		if !optional {
+			*required = append(*required, name)
+		}
+	}
+}
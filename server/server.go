@@ -0,0 +1,358 @@
+// Package server serves a [Corpus] of [en.WordData] entries over a JSON
+// HTTP API:
+//
+//	GET /entries/{lang}/{word}  entries of a word in a language
+//	GET /complete?prefix=       headwords starting with a prefix
+//	GET /forms/{form}           entries listing an inflected form
+//	GET /search?q=              entries whose glosses contain words
+//	GET /list?pos=&category=    entries by part of speech or category
+//	GET /openapi.json           OpenAPI document of the API
+//
+// {lang} and the lang query parameters are language codes, e.g. "en".
+// The corpus does not change while it is served, so responses carry an
+// ETag derived from the corpus and the request, and conditional
+// requests for a resource that exists are answered with 304 Not
+// Modified.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+const (
+	// default and maximum numbers of results per page
+	DEFAULT_LIMIT int = 20
+	MAX_LIMIT     int = 200
+)
+
+// Ref refers to an entry without its content.
+type Ref struct {
+	Word     string `json:"word"`
+	Lang     string `json:"lang"`
+	LangCode string `json:"lang_code"`
+	Pos      string `json:"pos"`
+	// path of the entries of the word, e.g. "/entries/en/water"
+	Href string `json:"href"`
+}
+
+// Page describes a page of results.
+type Page struct {
+	// number of results on all pages
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	// path of the next page, empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+type EntriesResponse struct {
+	Word     string         `json:"word"`
+	LangCode string         `json:"lang_code"`
+	Entries  []*en.WordData `json:"entries"`
+}
+
+type CompleteResponse struct {
+	Prefix string   `json:"prefix"`
+	Words  []string `json:"words"`
+}
+
+// FormEntry is an entry listing a searched form.
+type FormEntry struct {
+	Ref
+	// tags of the form, e.g. ["plural"]
+	Tags []string `json:"tags,omitempty"`
+}
+
+type FormsResponse struct {
+	Form    string      `json:"form"`
+	Entries []FormEntry `json:"entries"`
+}
+
+// SearchResult is an entry whose glosses match a search.
+type SearchResult struct {
+	Ref
+	// the matching glosses
+	Glosses []string `json:"glosses"`
+}
+
+type SearchResponse struct {
+	Query string `json:"query"`
+	Page
+	Results []SearchResult `json:"results"`
+}
+
+type ListResponse struct {
+	Page
+	Entries []Ref `json:"entries"`
+}
+
+// Error is the body of error responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Server is an [http.Handler] serving a corpus.
+type Server struct {
+	corpus *Corpus
+	mux    *http.ServeMux
+}
+
+// New returns a server of `c`.
+func New(c *Corpus) *Server {
+	s := &Server{corpus: c, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /entries/{lang}/{word}", s.entries)
+	s.mux.HandleFunc("GET /complete", s.complete)
+	s.mux.HandleFunc("GET /forms/{form}", s.forms)
+	s.mux.HandleFunc("GET /search", s.search)
+	s.mux.HandleFunc("GET /list", s.list)
+	s.mux.HandleFunc("GET /openapi.json", s.openAPI)
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	})
+	return s
+}
+
+// ServeHTTP dispatches requests, answering conditional GET requests
+// whose ETag matches with 304 Not Modified once the handler has found
+// the resource.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		w.Header().Set("ETag", s.etag(r))
+		w.Header().Set("Cache-Control", "no-cache")
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			w = &conditionalWriter{ResponseWriter: w, ifNoneMatch: inm}
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// conditionalWriter replaces a 200 OK response whose ETag matches
+// If-None-Match with 304 Not Modified and drops its body. Error
+// responses have no ETag and are written as they are.
+type conditionalWriter struct {
+	http.ResponseWriter
+	ifNoneMatch string
+	notModified bool
+}
+
+func (w *conditionalWriter) WriteHeader(status int) {
+	if status == http.StatusOK && matchETag(w.ifNoneMatch, w.Header().Get("ETag")) {
+		w.notModified = true
+		w.Header().Del("Content-Type")
+		status = http.StatusNotModified
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *conditionalWriter) Write(p []byte) (int, error) {
+	if w.notModified {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// etag identifies the response to `r`, which only depends on the
+// corpus and the request URI.
+func (s *Server) etag(r *http.Request) string {
+	sum := sha256.Sum256([]byte(s.corpus.version + "\x00" + r.URL.RequestURI()))
+	return fmt.Sprintf(`"%x"`, sum[:12])
+}
+
+// matchETag reports whether an If-None-Match header matches `etag`, the
+// ETag of a current representation: "*" matches any.
+func matchETag(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for t := range strings.SplitSeq(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	// errors are not cached
+	w.Header().Del("ETag")
+	writeJSON(w, status, Error{msg})
+}
+
+func (s *Server) ref(id int) Ref {
+	r := s.corpus.records[id]
+	return Ref{
+		Word:     r.word,
+		Lang:     r.lang,
+		LangCode: r.langCode,
+		Pos:      r.pos,
+		Href:     "/entries/" + url.PathEscape(r.langCode) + "/" + url.PathEscape(r.word),
+	}
+}
+
+func (s *Server) entries(w http.ResponseWriter, r *http.Request) {
+	lang, word := r.PathValue("lang"), r.PathValue("word")
+	ids := s.corpus.Lookup(lang, word)
+	if len(ids) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no entries for %q in %q", word, lang))
+		return
+	}
+	resp := EntriesResponse{Word: word, LangCode: lang}
+	for _, id := range ids {
+		e, err := s.corpus.Entry(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.Entries = append(resp.Entries, e)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) complete(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prefix := q.Get("prefix")
+	if prefix == "" {
+		writeError(w, http.StatusBadRequest, "missing prefix")
+		return
+	}
+	limit, err := intParam(q, "limit", 10, 1, MAX_LIMIT)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	words := s.corpus.Complete(q.Get("lang"), prefix, limit)
+	if words == nil {
+		words = []string{}
+	}
+	writeJSON(w, http.StatusOK, CompleteResponse{Prefix: prefix, Words: words})
+}
+
+func (s *Server) forms(w http.ResponseWriter, r *http.Request) {
+	form := r.PathValue("form")
+	resp := FormsResponse{Form: form, Entries: []FormEntry{}}
+	for _, m := range s.corpus.Forms(r.URL.Query().Get("lang"), form) {
+		resp.Entries = append(resp.Entries, FormEntry{s.ref(m.ID), m.Tags})
+	}
+	if len(resp.Entries) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no entries with the form %q", form))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := q.Get("q")
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		writeError(w, http.StatusBadRequest, "missing q")
+		return
+	}
+	ids := s.corpus.Search(query)
+	if lang := q.Get("lang"); lang != "" {
+		ids = slices.DeleteFunc(ids, func(id int) bool { return s.corpus.records[id].langCode != lang })
+	}
+	page, ids, err := paginate(r, ids)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := SearchResponse{Query: query, Page: page, Results: []SearchResult{}}
+	for _, id := range ids {
+		e, err := s.corpus.Entry(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		res := SearchResult{Ref: s.ref(id), Glosses: []string{}}
+		for _, sense := range e.Senses {
+			for _, g := range sense.Glosses {
+				if containsAll(tokenize(g), terms) {
+					res.Glosses = append(res.Glosses, g)
+				}
+			}
+		}
+		resp.Results = append(resp.Results, res)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func containsAll(tokens, terms []string) bool {
+	for _, t := range terms {
+		if !slices.Contains(tokens, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, ids, err := paginate(r, s.corpus.List(q.Get("lang"), q.Get("pos"), q.Get("category")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := ListResponse{Page: page, Entries: make([]Ref, len(ids))}
+	for i, id := range ids {
+		resp.Entries[i] = s.ref(id)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPI())
+}
+
+// paginate returns the page of `ids` selected by the offset and limit
+// query parameters.
+func paginate(r *http.Request, ids []int) (Page, []int, error) {
+	q := r.URL.Query()
+	offset, err := intParam(q, "offset", 0, 0, len(ids))
+	if err != nil {
+		return Page{}, nil, err
+	}
+	limit, err := intParam(q, "limit", DEFAULT_LIMIT, 1, MAX_LIMIT)
+	if err != nil {
+		return Page{}, nil, err
+	}
+	p := Page{Total: len(ids), Offset: offset, Limit: limit}
+	end := min(offset+limit, len(ids))
+	if end < len(ids) {
+		q.Set("offset", strconv.Itoa(end))
+		p.Next = r.URL.Path + "?" + q.Encode()
+	}
+	return p, ids[offset:end], nil
+}
+
+// intParam parses the query parameter `name`, which must be between
+// `lo` and `hi` if set.
+func intParam(q url.Values, name string, def, lo, hi int) (int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d", name, lo, hi)
+	}
+	return n, nil
+}
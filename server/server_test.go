@@ -0,0 +1,321 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/server"
+)
+
+const corpus = `{"word":"water","lang":"English","lang_code":"en","pos":"noun","categories":["en:Liquids"],"forms":[{"form":"waters","tags":["plural"]},{"form":"en-noun","tags":["inflection-template"]}],"senses":[{"glosses":["A clear liquid, essential to life."]}]}
+{"word":"water","lang":"English","lang_code":"en","pos":"verb","forms":[{"form":"watered","tags":["past"]}],"senses":[{"glosses":["To pour water on a plant."]}]}
+{"word":"watch","lang":"English","lang_code":"en","pos":"noun","senses":[{"glosses":["A portable clock."]}]}
+{"word":"Wasser","lang":"German","lang_code":"de","pos":"noun","forms":[{"form":"Wassers","tags":["genitive"]}],"senses":[{"glosses":["water, a clear liquid"],"categories":["de:Liquids"]}]}
+
+{"word":"eau","lang":"French","lang_code":"fr","pos":"noun","senses":[{"glosses":["water"],"tags":["feminine"]}]}
+`
+
+func newServer(t *testing.T) *server.Server {
+	t.Helper()
+	c, err := server.Load(strings.NewReader(corpus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server.New(c)
+}
+
+// get serves a GET request and decodes the JSON response into `v`.
+func get(t *testing.T, s http.Handler, target string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if v != nil && rec.Code == http.StatusOK {
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("%s: Content-Type = %q", target, ct)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+	}
+	return rec
+}
+
+func words(refs []server.Ref) []string {
+	ws := make([]string, len(refs))
+	for i, r := range refs {
+		ws[i] = r.Word + "/" + r.LangCode + "/" + r.Pos
+	}
+	return ws
+}
+
+func TestEntries(t *testing.T) {
+	s := newServer(t)
+	var resp server.EntriesResponse
+	rec := get(t, s, "/entries/en/water", &resp)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if len(resp.Entries) != 2 || resp.Entries[0].Pos != "noun" || resp.Entries[1].Pos != "verb" {
+		t.Errorf("entries = %+v", resp.Entries)
+	}
+	if resp.Entries[0].Senses[0].Glosses[0] != "A clear liquid, essential to life." {
+		t.Errorf("senses = %+v", resp.Entries[0].Senses)
+	}
+
+	for _, target := range []string{"/entries/fr/water", "/entries/en/fire", "/nope"} {
+		var e server.Error
+		rec := get(t, s, target, nil)
+		if rec.Code != http.StatusNotFound || json.Unmarshal(rec.Body.Bytes(), &e) != nil || e.Error == "" {
+			t.Errorf("%s: status %d: %s", target, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := newServer(t)
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/complete?prefix=wat", []string{"watch", "water"}},
+		{"/complete?prefix=WA", []string{"Wasser", "watch", "water"}},
+		{"/complete?prefix=wa&lang=de", []string{"Wasser"}},
+		{"/complete?prefix=wa&limit=1", []string{"Wasser"}},
+		{"/complete?prefix=z", []string{}},
+	}
+	for _, tt := range tests {
+		var resp server.CompleteResponse
+		get(t, s, tt.target, &resp)
+		if !slices.Equal(resp.Words, tt.want) || resp.Words == nil {
+			t.Errorf("%s = %q, want %q", tt.target, resp.Words, tt.want)
+		}
+	}
+	for _, target := range []string{"/complete", "/complete?prefix=w&limit=0", "/complete?prefix=w&limit=x"} {
+		if rec := get(t, s, target, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d", target, rec.Code)
+		}
+	}
+}
+
+func TestForms(t *testing.T) {
+	s := newServer(t)
+	var resp server.FormsResponse
+	get(t, s, "/forms/waters", &resp)
+	if len(resp.Entries) != 1 || resp.Entries[0].Word != "water" || resp.Entries[0].Href != "/entries/en/water" ||
+		!slices.Equal(resp.Entries[0].Tags, []string{"plural"}) {
+		t.Errorf("entries = %+v", resp.Entries)
+	}
+	if rec := get(t, s, "/forms/en-noun", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("inflection template: status %d", rec.Code)
+	}
+	if rec := get(t, s, "/forms/Wassers?lang=en", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("other language: status %d", rec.Code)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newServer(t)
+	var resp server.SearchResponse
+	get(t, s, "/search?q=Clear+LIQUID", &resp)
+	if resp.Total != 2 || len(resp.Results) != 2 {
+		t.Fatalf("results = %+v", resp)
+	}
+	if r := resp.Results[1]; r.Word != "Wasser" || !slices.Equal(r.Glosses, []string{"water, a clear liquid"}) {
+		t.Errorf("result = %+v", r)
+	}
+
+	get(t, s, "/search?q=water&lang=en", &resp)
+	if resp.Total != 1 || resp.Results[0].Pos != "verb" {
+		t.Errorf("results = %+v", resp)
+	}
+	if rec := get(t, s, "/search?q=+,", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty query: status %d", rec.Code)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := newServer(t)
+	tests := []struct {
+		target string
+		want   []string
+		total  int
+		next   string
+	}{
+		{"/list", []string{"water/en/noun", "water/en/verb", "watch/en/noun", "Wasser/de/noun", "eau/fr/noun"}, 5, ""},
+		{"/list?pos=noun&limit=2", []string{"water/en/noun", "watch/en/noun"}, 4, "/list?limit=2&offset=2&pos=noun"},
+		{"/list?pos=noun&limit=2&offset=2", []string{"Wasser/de/noun", "eau/fr/noun"}, 4, ""},
+		{"/list?lang=en&pos=noun", []string{"water/en/noun", "watch/en/noun"}, 2, ""},
+		{"/list?lang=en", []string{"water/en/noun", "water/en/verb", "watch/en/noun"}, 3, ""},
+		{"/list?category=de:Liquids", []string{"Wasser/de/noun"}, 1, ""},
+		{"/list?category=en:Liquids&pos=verb", []string{}, 0, ""},
+	}
+	for _, tt := range tests {
+		var resp server.ListResponse
+		get(t, s, tt.target, &resp)
+		if got := words(resp.Entries); !slices.Equal(got, tt.want) || resp.Total != tt.total || resp.Next != tt.next {
+			t.Errorf("%s = %q, total %d, next %q; want %q, %d, %q", tt.target, got, resp.Total, resp.Next, tt.want, tt.total, tt.next)
+		}
+	}
+	if rec := get(t, s, "/list?offset=6", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("offset past the end: status %d", rec.Code)
+	}
+}
+
+func TestETag(t *testing.T) {
+	s := newServer(t)
+	rec := get(t, s, "/entries/en/water", nil)
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if other := get(t, s, "/entries/en/watch", nil).Header().Get("ETag"); other == etag {
+		t.Errorf("same ETag for different requests")
+	}
+	if again := get(t, newServer(t), "/entries/en/water", nil).Header().Get("ETag"); again != etag {
+		t.Errorf("ETag changed for the same corpus: %s, %s", again, etag)
+	}
+
+	for _, inm := range []string{etag, `"x", W/` + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/entries/en/water", nil)
+		req.Header.Set("If-None-Match", inm)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status %d", inm, rec.Code)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/entries/en/water", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("stale ETag: status %d", rec.Code)
+	}
+	if rec := get(t, s, "/entries/en/fire", nil); rec.Header().Get("ETag") != "" {
+		t.Errorf("ETag on error")
+	}
+
+	// * only matches resources that exist
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/entries/en/fire", http.StatusNotFound},
+		{"/forms/fires", http.StatusNotFound},
+		{"/complete", http.StatusBadRequest},
+		{"/nowhere", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("If-None-Match", "*")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tt.want || rec.Header().Get("ETag") != "" {
+			t.Errorf("%s: status %d, ETag %q", tt.path, rec.Code, rec.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	var doc struct {
+		OpenAPI    string                    `json:"openapi"`
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	get(t, newServer(t), "/openapi.json", &doc)
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	for _, p := range []string{"/entries/{lang}/{word}", "/complete", "/forms/{form}", "/search", "/list"} {
+		if doc.Paths[p]["get"] == nil {
+			t.Errorf("no GET %s", p)
+		}
+	}
+
+	schemas := doc.Components.Schemas
+	word, ok := schemas["WordData"]
+	if !ok {
+		t.Fatalf("no WordData schema in %v", schemas)
+	}
+	if !slices.Contains(word.Required, "word") || slices.Contains(word.Required, "senses") {
+		t.Errorf("WordData required = %v", word.Required)
+	}
+	if got := string(word.Properties["senses"]); got != `{"items":{"$ref":"#/components/schemas/SenseData"},"type":"array"}` {
+		t.Errorf("senses = %s", got)
+	}
+	if _, ok := schemas["SenseData"]; !ok {
+		t.Errorf("no SenseData schema")
+	}
+	// en.TemplateArgs encodes nil arguments as {}
+	if got := string(schemas["TemplateData"].Properties["args"]); got != `{"additionalProperties":{"type":"string"},"type":"object"}` {
+		t.Errorf("args = %s", got)
+	}
+	if !slices.Contains(schemas["TemplateData"].Required, "args") {
+		t.Errorf("TemplateData required = %v", schemas["TemplateData"].Required)
+	}
+	// embedded structs are inlined
+	search := schemas["SearchResponse"]
+	for _, p := range []string{"query", "total", "next", "results"} {
+		if _, ok := search.Properties[p]; !ok {
+			t.Errorf("SearchResponse has no %s", p)
+		}
+	}
+	if slices.Contains(search.Required, "next") {
+		t.Errorf("SearchResponse required = %v", search.Required)
+	}
+	if _, ok := schemas["Page"]; ok {
+		t.Errorf("embedded Page has its own schema")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "words.jsonl")
+	if err := os.WriteFile(plain, []byte(corpus), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(corpus))
+	zw.Close()
+	compressed := filepath.Join(dir, "words.jsonl.gz")
+	if err := os.WriteFile(compressed, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{plain, compressed} {
+		c, err := server.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Len() != 5 {
+			t.Errorf("%s: %d entries", name, c.Len())
+		}
+		ids := c.Lookup("fr", "eau")
+		if len(ids) != 1 {
+			t.Fatalf("%s: lookup = %v", name, ids)
+		}
+		if w, err := c.Entry(ids[0]); err != nil || w.Senses[0].Tags[0] != "feminine" {
+			t.Errorf("%s: entry = %+v, %v", name, w, err)
+		}
+		if err := c.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := server.Load(strings.NewReader("{\"word\":\"a\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("invalid line: %v", err)
+	}
+}
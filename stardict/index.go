@@ -0,0 +1,105 @@
+package stardict
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// IndexEntry locates the article of a word in the .dict file.
+type IndexEntry struct {
+	Word   string
+	Offset uint64
+	Size   uint32
+}
+
+// Synonym points to the index entry of the word it stands for.
+type Synonym struct {
+	Word  string
+	Index uint32
+}
+
+func writeIndex(w io.Writer, entries []IndexEntry, offsetBits int) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		bw.WriteString(e.Word)
+		bw.WriteByte(0)
+		if offsetBits == 64 {
+			bw.Write(binary.BigEndian.AppendUint64(nil, e.Offset))
+		} else {
+			bw.Write(binary.BigEndian.AppendUint32(nil, uint32(e.Offset)))
+		}
+		if _, err := bw.Write(binary.BigEndian.AppendUint32(nil, e.Size)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writeSynonyms(w io.Writer, syns []Synonym) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range syns {
+		bw.WriteString(s.Word)
+		bw.WriteByte(0)
+		if _, err := bw.Write(binary.BigEndian.AppendUint32(nil, s.Index)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readWord reads a zero-terminated word followed by `n` bytes.
+func readWord(br *bufio.Reader, n int) (string, []byte, error) {
+	word, err := br.ReadBytes(0)
+	if err != nil {
+		if err == io.EOF && len(word) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", nil, err
+	}
+	rest := make([]byte, n)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	return string(bytes.TrimSuffix(word, []byte{0})), rest, nil
+}
+
+// ReadIndex parses an .idx file whose offsets are `offsetBits` (32 or
+// 64) wide.
+func ReadIndex(r io.Reader, offsetBits int) ([]IndexEntry, error) {
+	br := bufio.NewReader(r)
+	var entries []IndexEntry
+	for {
+		word, rest, err := readWord(br, offsetBits/8+4)
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("stardict: index entry %d: %w", len(entries), err)
+		}
+		e := IndexEntry{Word: word}
+		if offsetBits == 64 {
+			e.Offset = binary.BigEndian.Uint64(rest)
+		} else {
+			e.Offset = uint64(binary.BigEndian.Uint32(rest))
+		}
+		e.Size = binary.BigEndian.Uint32(rest[offsetBits/8:])
+		entries = append(entries, e)
+	}
+}
+
+// ReadSynonyms parses a .syn file.
+func ReadSynonyms(r io.Reader) ([]Synonym, error) {
+	br := bufio.NewReader(r)
+	var syns []Synonym
+	for {
+		word, rest, err := readWord(br, 4)
+		if err == io.EOF {
+			return syns, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("stardict: synonym %d: %w", len(syns), err)
+		}
+		syns = append(syns, Synonym{Word: word, Index: binary.BigEndian.Uint32(rest)})
+	}
+}
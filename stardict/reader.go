@@ -0,0 +1,119 @@
+package stardict
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dictzip"
+)
+
+// Dictionary is a StarDict dictionary opened for lookups.
+type Dictionary struct {
+	Info     *Info
+	Index    []IndexEntry
+	Synonyms []Synonym
+
+	data   io.ReaderAt
+	closer io.Closer
+}
+
+// Open opens the dictionary described by the .ifo file at `ifoPath`.
+// The articles are read from the .dict file, or from the .dict.dz file
+// if there is no uncompressed one.
+func Open(ifoPath string) (*Dictionary, error) {
+	base := strings.TrimSuffix(ifoPath, ".ifo")
+	f, err := os.Open(ifoPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ReadInfo(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	d := &Dictionary{Info: info}
+
+	if d.Index, err = readFile(base+".idx", func(r io.Reader) ([]IndexEntry, error) {
+		return ReadIndex(r, info.IdxOffsetBits)
+	}); err != nil {
+		return nil, err
+	}
+	if len(d.Index) != info.WordCount {
+		return nil, fmt.Errorf("stardict: index has %d words, .ifo says %d", len(d.Index), info.WordCount)
+	}
+	if info.SynWordCount > 0 {
+		if d.Synonyms, err = readFile(base+".syn", ReadSynonyms); err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err := os.Open(base + ".dict"); err == nil {
+		d.data, d.closer = data, data
+	} else if errors.Is(err, os.ErrNotExist) {
+		dz, err := os.Open(base + ".dict.dz")
+		if err != nil {
+			return nil, err
+		}
+		if d.data, err = dictzip.NewReader(dz); err != nil {
+			dz.Close()
+			return nil, err
+		}
+		d.closer = dz
+	} else {
+		return nil, err
+	}
+	return d, nil
+}
+
+func readFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+// Close closes the article file.
+func (d *Dictionary) Close() error {
+	return d.closer.Close()
+}
+
+// Article reads the article of an index entry.
+func (d *Dictionary) Article(e IndexEntry) (string, error) {
+	buf := make([]byte, e.Size)
+	// ReadAt may return io.EOF along with the last article
+	if n, err := d.data.ReadAt(buf, int64(e.Offset)); n < len(buf) {
+		return "", fmt.Errorf("stardict: reading article of %q: %w", e.Word, err)
+	}
+	return string(buf), nil
+}
+
+// Lookup returns the index entries of `word`: those where it is the
+// headword first, then those it is a synonym of.
+func (d *Dictionary) Lookup(word string) []IndexEntry {
+	var found []uint32
+	i := sort.Search(len(d.Index), func(i int) bool { return Compare(d.Index[i].Word, word) >= 0 })
+	for ; i < len(d.Index) && d.Index[i].Word == word; i++ {
+		found = append(found, uint32(i))
+	}
+	j := sort.Search(len(d.Synonyms), func(i int) bool { return Compare(d.Synonyms[i].Word, word) >= 0 })
+	for ; j < len(d.Synonyms) && d.Synonyms[j].Word == word; j++ {
+		found = append(found, d.Synonyms[j].Index)
+	}
+
+	var entries []IndexEntry
+	seen := make(map[uint32]bool)
+	for _, k := range found {
+		if !seen[k] && int(k) < len(d.Index) {
+			seen[k] = true
+			entries = append(entries, d.Index[k])
+		}
+	}
+	return entries
+}
@@ -0,0 +1,136 @@
+// Package stardict exports [en.WordData] as a StarDict dictionary: an
+// .ifo description, an .idx index, the .dict (or dictzip-compressed
+// .dict.dz) articles and a .syn file of synonyms.
+package stardict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Compare orders words the way StarDict expects its index to be sorted:
+// by ASCII case-insensitive byte comparison first, then by plain byte
+// comparison (`stardict_strcmp`).
+func Compare(a, b string) int {
+	if c := asciiCaseCompare(a, b); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// asciiCaseCompare is glib's g_ascii_strcasecmp.
+func asciiCaseCompare(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := asciiLower(a[i]), asciiLower(b[i])
+		if ca != cb {
+			return int(ca) - int(cb)
+		}
+	}
+	return len(a) - len(b)
+}
+
+func asciiLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// Info is the content of an .ifo file.
+type Info struct {
+	Version          string
+	BookName         string
+	WordCount        int
+	SynWordCount     int
+	IdxFileSize      int64
+	IdxOffsetBits    int
+	Author           string
+	Email            string
+	Website          string
+	Description      string
+	Date             string
+	SameTypeSequence string
+}
+
+const ifoMagic = "StarDict's dict ifo file"
+
+// WriteTo writes the .ifo file.
+func (info *Info) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	sb.WriteString(ifoMagic + "\n")
+	sb.WriteString("version=" + info.Version + "\n")
+	field := func(key, value string) {
+		// values are single lines; descriptions use <br> for newlines
+		value = strings.ReplaceAll(value, "\r\n", "<br>")
+		value = strings.ReplaceAll(value, "\n", "<br>")
+		if value != "" {
+			sb.WriteString(key + "=" + value + "\n")
+		}
+	}
+	field("bookname", info.BookName)
+	field("wordcount", strconv.Itoa(info.WordCount))
+	if info.SynWordCount > 0 {
+		field("synwordcount", strconv.Itoa(info.SynWordCount))
+	}
+	field("idxfilesize", strconv.FormatInt(info.IdxFileSize, 10))
+	if info.IdxOffsetBits == 64 {
+		field("idxoffsetbits", "64")
+	}
+	field("author", info.Author)
+	field("email", info.Email)
+	field("website", info.Website)
+	field("description", info.Description)
+	field("date", info.Date)
+	field("sametypesequence", info.SameTypeSequence)
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// ReadInfo parses an .ifo file.
+func ReadInfo(r io.Reader) (*Info, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimPrefix(scanner.Text(), "\ufeff") != ifoMagic {
+		return nil, fmt.Errorf("stardict: missing %q header", ifoMagic)
+	}
+	info := &Info{IdxOffsetBits: 32}
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		var err error
+		switch key {
+		case "version":
+			info.Version = value
+		case "bookname":
+			info.BookName = value
+		case "wordcount":
+			info.WordCount, err = strconv.Atoi(value)
+		case "synwordcount":
+			info.SynWordCount, err = strconv.Atoi(value)
+		case "idxfilesize":
+			info.IdxFileSize, err = strconv.ParseInt(value, 10, 64)
+		case "idxoffsetbits":
+			info.IdxOffsetBits, err = strconv.Atoi(value)
+		case "author":
+			info.Author = value
+		case "email":
+			info.Email = value
+		case "website":
+			info.Website = value
+		case "description":
+			info.Description = value
+		case "date":
+			info.Date = value
+		case "sametypesequence":
+			info.SameTypeSequence = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stardict: invalid %s: %w", key, err)
+		}
+	}
+	return info, scanner.Err()
+}
@@ -0,0 +1,150 @@
+package stardict_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/stardict"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var words = []*en.WordData{
+	{
+		Word: "run", Pos: "verb", Lang: "English", LangCode: "en",
+		Sounds: []en.SoundData{{Ipa: ptr("/ɹʌn/")}},
+		Forms: []en.FormData{
+			{Form: "runs", Tags: []string{"present", "singular", "third-person"}},
+			{Form: "ran", Tags: []string{"past"}},
+			{Form: "run", Tags: []string{"participle", "past"}},
+			{Form: "-", Tags: []string{"table-tags"}},
+		},
+		Senses: []en.SenseData{
+			{Glosses: []string{"To move swiftly."}, Examples: []en.ExampleData{{Text: "I ran home.", BoldTextOffsets: [][2]int{{2, 5}}}}},
+			{Glosses: []string{"To move swiftly.", "To jog."}},
+		},
+	},
+	{
+		Word: "run", Pos: "noun", Lang: "English", LangCode: "en",
+		Forms:  []en.FormData{{Form: "runs", Tags: []string{"plural"}}},
+		Senses: []en.SenseData{{Glosses: []string{"An act of running."}}},
+	},
+	{
+		Word: "Run", Pos: "name", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"A river."}}},
+	},
+	{
+		Word: "apple", Pos: "noun", Lang: "English", LangCode: "en",
+		Redirects: []string{"Apple fruit"},
+		Senses:    []en.SenseData{{Glosses: []string{"A fruit & a tree."}}},
+	},
+	{
+		Word: "ábaco", Pos: "noun", Lang: "English", LangCode: "en",
+		Senses: []en.SenseData{{Glosses: []string{"Abacus."}}},
+	},
+}
+
+func TestCompare(t *testing.T) {
+	got := []string{"b", "é", "ab", "B", "a", "A"}
+	slices.SortFunc(got, stardict.Compare)
+	if want := []string{"A", "a", "ab", "B", "b", "é"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func build(t *testing.T, opts stardict.Options) *stardict.Dictionary {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := stardict.Create(dir, "wiktionary-en", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range words {
+		if err := w.Add(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, errDict := os.Stat(filepath.Join(dir, "wiktionary-en.dict"))
+	_, errDz := os.Stat(filepath.Join(dir, "wiktionary-en.dict.dz"))
+	if opts.Compress != (errDict != nil) || opts.Compress != (errDz == nil) {
+		t.Errorf("compress = %v, but .dict: %v, .dict.dz: %v", opts.Compress, errDict, errDz)
+	}
+
+	d, err := stardict.Open(filepath.Join(dir, "wiktionary-en.ifo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		d := build(t, stardict.Options{BookName: "Wiktionary (en)", Date: "2026.01.02", Compress: compress})
+
+		if d.Info.BookName != "Wiktionary (en)" || d.Info.WordCount != 5 || d.Info.SynWordCount != 4 ||
+			d.Info.SameTypeSequence != "h" || d.Info.Date != "2026.01.02" {
+			t.Errorf("info = %+v", d.Info)
+		}
+		var headwords []string
+		for _, e := range d.Index {
+			headwords = append(headwords, e.Word)
+		}
+		if want := []string{"apple", "Run", "run", "run", "ábaco"}; !slices.Equal(headwords, want) {
+			t.Errorf("index = %q, want %q", headwords, want)
+		}
+		if !slices.IsSortedFunc(d.Synonyms, func(a, b stardict.Synonym) int { return stardict.Compare(a.Word, b.Word) }) {
+			t.Errorf("synonyms not sorted: %+v", d.Synonyms)
+		}
+
+		// "runs" is a form of both the verb and the noun
+		entries := d.Lookup("runs")
+		if len(entries) != 2 {
+			t.Fatalf("Lookup(runs) = %+v", entries)
+		}
+		verb, err := d.Article(entries[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(verb, "<i>verb</i>") || !strings.Contains(verb, "I <b>ran</b> home.") ||
+			!strings.Contains(verb, "<ol><li>To jog.</li></ol>") {
+			t.Errorf("verb article: %s", verb)
+		}
+
+		if entries := d.Lookup("Apple fruit"); len(entries) != 1 || entries[0].Word != "apple" {
+			t.Errorf("redirect lookup: %+v", entries)
+		}
+		apple, _ := d.Article(d.Lookup("apple")[0])
+		if !strings.Contains(apple, "A fruit &amp; a tree.") {
+			t.Errorf("apple article: %s", apple)
+		}
+		if entries := d.Lookup("run"); len(entries) != 2 {
+			t.Errorf("Lookup(run) = %+v", entries)
+		}
+		last, err := d.Article(d.Lookup("ábaco")[0])
+		if err != nil || !strings.Contains(last, "Abacus.") {
+			t.Errorf("last article: %q, %v", last, err)
+		}
+	}
+}
+
+func TestPlainFormat(t *testing.T) {
+	d := build(t, stardict.Options{Format: stardict.Plain, NoSynonyms: true})
+	if d.Info.SameTypeSequence != "m" || d.Info.SynWordCount != 0 || d.Info.BookName != "wiktionary-en" {
+		t.Errorf("info = %+v", d.Info)
+	}
+	text, err := d.Article(d.Lookup("apple")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "noun\n1. A fruit & a tree."; text != want {
+		t.Errorf("article = %q, want %q", text, want)
+	}
+}
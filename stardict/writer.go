@@ -0,0 +1,242 @@
+package stardict
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/dictzip"
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Format selects how articles are rendered.
+type Format int
+
+const (
+	// HTML articles (sametypesequence=h)
+	HTML Format = iota
+	// plain text articles (sametypesequence=m)
+	Plain
+)
+
+// Options configures a [Writer].
+type Options struct {
+	BookName    string
+	Author      string
+	Email       string
+	Website     string
+	Description string
+	// defaults to today, formatted as YYYY.MM.DD
+	Date   string
+	Format Format
+	// compress the articles into a .dict.dz file
+	Compress bool
+	// do not write synonyms for Forms and Redirects
+	NoSynonyms bool
+}
+
+// Writer builds a StarDict dictionary. Articles are written to the
+// .dict file as they are added; the index, synonyms and .ifo are
+// written by [Writer.Close].
+type Writer struct {
+	dir, name string
+	opts      Options
+
+	dict   *os.File
+	buf    *bufio.Writer
+	offset uint64
+
+	entries []IndexEntry
+	syns    []Synonym // Index refers to entries before sorting
+	seen    map[Synonym]bool
+}
+
+// Create starts a dictionary named `name` (the file names without
+// extension) in `dir`.
+func Create(dir, name string, opts Options) (*Writer, error) {
+	f, err := os.Create(filepath.Join(dir, name+".dict"))
+	if err != nil {
+		return nil, err
+	}
+	if opts.BookName == "" {
+		opts.BookName = name
+	}
+	if opts.Date == "" {
+		opts.Date = time.Now().Format("2006.01.02")
+	}
+	return &Writer{
+		dir:  dir,
+		name: name,
+		opts: opts,
+		dict: f,
+		buf:  bufio.NewWriter(f),
+		seen: make(map[Synonym]bool),
+	}, nil
+}
+
+// Add renders `word` as an article. Every [en.WordData] gets its own
+// article, so a headword with several parts of speech has several index
+// entries, which StarDict readers display together.
+func (w *Writer) Add(word *en.WordData) error {
+	e := article.New(word)
+	var text string
+	if w.opts.Format == Plain {
+		text = article.Plain(e)
+	} else {
+		text = article.HTML(e)
+	}
+	if len(text) > math.MaxUint32 {
+		return errors.New("stardict: article too large")
+	}
+	if _, err := w.buf.WriteString(text); err != nil {
+		return err
+	}
+	index := uint32(len(w.entries))
+	w.entries = append(w.entries, IndexEntry{Word: word.Word, Offset: w.offset, Size: uint32(len(text))})
+	w.offset += uint64(len(text))
+
+	if w.opts.NoSynonyms {
+		return nil
+	}
+	for _, f := range word.Forms {
+		if article.ShowForm(f) {
+			w.addSynonym(f.Form, index, word.Word)
+		}
+	}
+	for _, r := range word.Redirects {
+		w.addSynonym(r, index, word.Word)
+	}
+	return nil
+}
+
+func (w *Writer) addSynonym(syn string, index uint32, headword string) {
+	s := Synonym{Word: syn, Index: index}
+	if syn == "" || syn == headword || w.seen[s] {
+		return
+	}
+	w.seen[s] = true
+	w.syns = append(w.syns, s)
+}
+
+// Close sorts and writes the index and synonyms, compresses the
+// articles if requested, and writes the .ifo file.
+func (w *Writer) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		w.dict.Close()
+		return err
+	}
+	if err := w.dict.Close(); err != nil {
+		return err
+	}
+	base := filepath.Join(w.dir, w.name)
+
+	order := make([]int, len(w.entries))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return Compare(w.entries[a].Word, w.entries[b].Word)
+	})
+	sorted := make([]IndexEntry, len(order))
+	position := make([]uint32, len(order))
+	for i, j := range order {
+		sorted[i] = w.entries[j]
+		position[j] = uint32(i)
+	}
+	for i := range w.syns {
+		w.syns[i].Index = position[w.syns[i].Index]
+	}
+	slices.SortStableFunc(w.syns, func(a, b Synonym) int {
+		return cmp.Or(Compare(a.Word, b.Word), cmp.Compare(a.Index, b.Index))
+	})
+
+	info := &Info{
+		Version:          "2.4.2",
+		BookName:         w.opts.BookName,
+		WordCount:        len(sorted),
+		SynWordCount:     len(w.syns),
+		IdxOffsetBits:    32,
+		Author:           w.opts.Author,
+		Email:            w.opts.Email,
+		Website:          w.opts.Website,
+		Description:      w.opts.Description,
+		Date:             w.opts.Date,
+		SameTypeSequence: "h",
+	}
+	if w.opts.Format == Plain {
+		info.SameTypeSequence = "m"
+	}
+	if w.offset > math.MaxUint32 {
+		info.Version, info.IdxOffsetBits = "3.0.0", 64
+	}
+
+	idxSize, err := writeFile(base+".idx", func(f *os.File) error {
+		return writeIndex(f, sorted, info.IdxOffsetBits)
+	})
+	if err != nil {
+		return err
+	}
+	info.IdxFileSize = idxSize
+	if len(w.syns) > 0 {
+		if _, err := writeFile(base+".syn", func(f *os.File) error {
+			return writeSynonyms(f, w.syns)
+		}); err != nil {
+			return err
+		}
+	}
+	if w.opts.Compress {
+		if err := compress(base + ".dict"); err != nil {
+			return err
+		}
+	}
+	_, err = writeFile(base+".ifo", func(f *os.File) error {
+		_, err := info.WriteTo(f)
+		return err
+	})
+	return err
+}
+
+// writeFile creates `path`, fills it with `write` and returns its size.
+func writeFile(path string, write func(*os.File) error) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	return size, f.Close()
+}
+
+// compress replaces `path` with `path`.dz.
+func compress(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	stat, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := writeFile(path+".dz", func(out *os.File) error {
+		return dictzip.Compress(out, in, stat.Size())
+	}); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,171 @@
+package tei
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+type entry struct {
+	XMLName xml.Name `xml:"entry"`
+	ID      string   `xml:"http://www.w3.org/XML/1998/namespace id,attr"`
+	Lang    string   `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Forms   []form   `xml:"form"`
+	GramGrp *gramGrp `xml:"gramGrp"`
+	Etym    *etym    `xml:"etym"`
+	Senses  []sense  `xml:"sense"`
+}
+
+type form struct {
+	Type    string   `xml:"type,attr"`
+	Orth    string   `xml:"orth"`
+	Prons   []pron   `xml:"pron"`
+	GramGrp *gramGrp `xml:"gramGrp"`
+}
+
+type pron struct {
+	Notation string `xml:"notation,attr"`
+	Lang     string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type gramGrp struct {
+	Grams []gram `xml:"gram"`
+}
+
+type gram struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type etym struct {
+	Text string `xml:",chardata"`
+}
+
+type sense struct {
+	ID      string   `xml:"http://www.w3.org/XML/1998/namespace id,attr"`
+	N       string   `xml:"n,attr"`
+	Usgs    []usg    `xml:"usg"`
+	GramGrp *gramGrp `xml:"gramGrp"`
+	Def     string   `xml:"def"`
+	Cits    []cit    `xml:"cit"`
+	Senses  []sense  `xml:"sense"`
+}
+
+type usg struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type cit struct {
+	Type  string `xml:"type,attr"`
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Quote quote  `xml:"quote"`
+	Bibl  string `xml:"bibl,omitempty"`
+	Cits  []cit  `xml:"cit"`
+}
+
+// quote holds pre-escaped mixed content, so that bold spans can be
+// written as <hi rend="bold">.
+type quote struct {
+	Inner string `xml:",innerxml"`
+}
+
+func newQuote(spans []article.Span) quote {
+	var sb strings.Builder
+	for _, s := range spans {
+		if s.Bold {
+			sb.WriteString(`<hi rend="bold">`)
+		}
+		xml.EscapeText(&sb, []byte(s.Text))
+		if s.Bold {
+			sb.WriteString(`</hi>`)
+		}
+	}
+	return quote{Inner: sb.String()}
+}
+
+func newEntry(w *en.WordData, id string) *entry {
+	a := article.New(w)
+	e := &entry{ID: id, Lang: w.LangCode}
+
+	lemma := form{Type: "lemma", Orth: w.Word}
+	for _, s := range w.Sounds {
+		if s.Ipa != nil && *s.Ipa != "" {
+			lemma.Prons = append(lemma.Prons, pron{Notation: "ipa", Lang: accentLang(s.Tags), Text: *s.Ipa})
+		}
+	}
+	e.Forms = append(e.Forms, lemma)
+	for _, f := range a.Forms {
+		e.Forms = append(e.Forms, form{Type: formType(f.Tags), Orth: f.Form, GramGrp: newGramGrp(f.Tags)})
+	}
+
+	if w.Pos != "" {
+		e.GramGrp = &gramGrp{Grams: []gram{{Type: "pos", Text: w.Pos}}}
+	}
+	if a.Etymology != "" {
+		e.Etym = &etym{Text: a.Etymology}
+	}
+	e.Senses = newSenses(a.Senses, id+"-s", "")
+	return e
+}
+
+// newGramGrp returns the grammatical tags among `tags`, or nil.
+func newGramGrp(tags []string) *gramGrp {
+	var g gramGrp
+	for _, t := range tags {
+		if typ, ok := gramTypes[t]; ok {
+			g.Grams = append(g.Grams, gram{Type: typ, Text: t})
+		}
+	}
+	if len(g.Grams) == 0 {
+		return nil
+	}
+	return &g
+}
+
+func newSenses(senses []*article.Sense, idPrefix, nPrefix string) []sense {
+	var out []sense
+	for i, s := range senses {
+		n := fmt.Sprintf("%s%d", nPrefix, i+1)
+		ts := sense{ID: idPrefix + n, N: n, Def: s.Gloss, GramGrp: newGramGrp(s.Tags)}
+		for _, t := range s.Tags {
+			if _, ok := gramTypes[t]; ok {
+				continue
+			}
+			typ, ok := usageTypes[t]
+			if !ok {
+				if a := accentLang([]string{t}); a != "" {
+					typ = "geographic"
+				} else {
+					typ = "hint"
+				}
+			}
+			ts.Usgs = append(ts.Usgs, usg{Type: typ, Text: t})
+		}
+		if s.Data != nil {
+			for _, t := range s.Data.Topics {
+				ts.Usgs = append(ts.Usgs, usg{Type: "domain", Text: t})
+			}
+		}
+		if s.Qualifier != "" {
+			ts.Usgs = append(ts.Usgs, usg{Type: "hint", Text: s.Qualifier})
+		}
+		for _, ex := range s.Examples {
+			c := cit{Type: "example", Quote: newQuote(ex.Text), Bibl: ex.Ref}
+			if ex.Roman != "" {
+				c.Cits = append(c.Cits, cit{Type: "transliteration", Quote: newQuote([]article.Span{{Text: ex.Roman}})})
+			}
+			if len(ex.Translation) > 0 {
+				c.Cits = append(c.Cits, cit{Type: "translation", Lang: "en", Quote: newQuote(ex.Translation)})
+			}
+			ts.Cits = append(ts.Cits, c)
+		}
+		ts.Senses = newSenses(s.Subsenses, idPrefix, n+".")
+		out = append(out, ts)
+	}
+	return out
+}
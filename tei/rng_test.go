@@ -0,0 +1,293 @@
+package tei_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// A minimal RELAX NG validator, supporting the patterns used by
+// testdata/lex0-subset.rng, so that the tests need no external tools.
+
+type pattern struct {
+	kind     string // element, attribute, group, choice, ...
+	name     xml.Name
+	value    string
+	children []*pattern
+}
+
+type validator struct {
+	start   *pattern
+	defines map[string]*pattern
+	lastErr error
+}
+
+func loadSchema(path string) (*validator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	v := &validator{defines: make(map[string]*pattern)}
+	dec := xml.NewDecoder(f)
+	var stack []*pattern
+	ns := ""
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			p := &pattern{kind: t.Name.Local}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Local == "ns" && p.kind == "grammar":
+					ns = a.Value
+				case a.Name.Local == "name" && p.kind == "attribute":
+					if prefix, local, ok := strings.Cut(a.Value, ":"); ok && prefix == "xml" {
+						p.name = xml.Name{Space: "http://www.w3.org/XML/1998/namespace", Local: local}
+					} else {
+						p.name = xml.Name{Local: a.Value}
+					}
+				case a.Name.Local == "name" && p.kind == "element":
+					p.name = xml.Name{Space: ns, Local: a.Value}
+				case a.Name.Local == "name":
+					p.name = xml.Name{Local: a.Value}
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, p)
+			}
+			stack = append(stack, p)
+		case xml.CharData:
+			if len(stack) > 0 && stack[len(stack)-1].kind == "value" {
+				stack[len(stack)-1].value += string(t)
+			}
+		case xml.EndElement:
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			switch p.kind {
+			case "start":
+				v.start = p
+			case "define":
+				v.defines[p.name.Local] = p
+			}
+		}
+	}
+	return v, nil
+}
+
+type elem struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	text     string
+	children []*elem
+}
+
+func parseInstance(r io.Reader) (*elem, error) {
+	dec := xml.NewDecoder(r)
+	var root *elem
+	var stack []*elem
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return root, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &elem{name: t.Name}
+			for _, a := range t.Attr {
+				if a.Name.Space != "xmlns" && !(a.Name.Space == "" && a.Name.Local == "xmlns") {
+					e.attrs = append(e.attrs, a)
+				}
+			}
+			if len(stack) == 0 {
+				root = e
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			}
+			stack = append(stack, e)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// Validate checks a document against the start pattern.
+func (v *validator) Validate(r io.Reader) error {
+	root, err := parseInstance(r)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return fmt.Errorf("empty document")
+	}
+	positions := v.match(&pattern{kind: "group", children: v.start.children}, []*elem{root}, 0)
+	if !slices.Contains(positions, 1) {
+		if v.lastErr != nil {
+			return v.lastErr
+		}
+		return fmt.Errorf("root element <%s> not allowed", root.name.Local)
+	}
+	return nil
+}
+
+// match returns the positions in `elems` that can follow a match of `p`
+// starting at `pos`.
+func (v *validator) match(p *pattern, elems []*elem, pos int) []int {
+	switch p.kind {
+	case "element":
+		if pos < len(elems) && elems[pos].name == p.name {
+			if err := v.validate(p, elems[pos]); err != nil {
+				v.lastErr = err
+				return nil
+			}
+			return []int{pos + 1}
+		}
+		return nil
+	case "choice":
+		var out []int
+		for _, c := range p.children {
+			out = union(out, v.match(c, elems, pos))
+		}
+		if len(p.children) > 0 && p.children[0].kind == "value" {
+			out = union(out, []int{pos})
+		}
+		return out
+	case "optional":
+		return union([]int{pos}, v.seq(p.children, elems, pos))
+	case "zeroOrMore":
+		return v.closure(p.children, elems, []int{pos})
+	case "oneOrMore":
+		return v.closure(p.children, elems, v.seq(p.children, elems, pos))
+	case "ref":
+		return v.seq(v.defines[p.name.Local].children, elems, pos)
+	case "attribute", "text", "value", "data", "empty":
+		return []int{pos}
+	}
+	return v.seq(p.children, elems, pos)
+}
+
+func (v *validator) seq(ps []*pattern, elems []*elem, pos int) []int {
+	positions := []int{pos}
+	for _, p := range ps {
+		var next []int
+		for _, q := range positions {
+			next = union(next, v.match(p, elems, q))
+		}
+		positions = next
+	}
+	return positions
+}
+
+func (v *validator) closure(ps []*pattern, elems []*elem, positions []int) []int {
+	frontier := positions
+	for len(frontier) > 0 {
+		var next []int
+		for _, q := range frontier {
+			for _, r := range v.seq(ps, elems, q) {
+				if !slices.Contains(positions, r) {
+					positions = append(positions, r)
+					next = append(next, r)
+				}
+			}
+		}
+		frontier = next
+	}
+	return positions
+}
+
+func union(a, b []int) []int {
+	for _, x := range b {
+		if !slices.Contains(a, x) {
+			a = append(a, x)
+		}
+	}
+	return a
+}
+
+type attrDecl struct {
+	required bool
+	values   []string
+}
+
+// declarations collects the attributes and whether text is allowed in
+// the content of an element pattern.
+func (v *validator) declarations(ps []*pattern, required bool, attrs map[xml.Name]*attrDecl) (text bool) {
+	for _, p := range ps {
+		switch p.kind {
+		case "element":
+		case "attribute":
+			d := &attrDecl{required: required}
+			for _, c := range p.children {
+				if c.kind == "value" {
+					d.values = append(d.values, c.value)
+				}
+				for _, cc := range c.children {
+					if cc.kind == "value" {
+						d.values = append(d.values, cc.value)
+					}
+				}
+			}
+			attrs[p.name] = d
+		case "text":
+			text = true
+		case "ref":
+			text = v.declarations(v.defines[p.name.Local].children, required, attrs) || text
+		case "optional", "zeroOrMore", "choice":
+			text = v.declarations(p.children, false, attrs) || text
+		default:
+			text = v.declarations(p.children, required, attrs) || text
+		}
+	}
+	return text
+}
+
+func (v *validator) validate(p *pattern, e *elem) error {
+	attrs := make(map[xml.Name]*attrDecl)
+	text := v.declarations(p.children, true, attrs)
+	for _, a := range e.attrs {
+		d, ok := attrs[a.Name]
+		if !ok {
+			return fmt.Errorf("<%s>: attribute %s not allowed", e.name.Local, a.Name.Local)
+		}
+		if len(d.values) > 0 && !slices.Contains(d.values, a.Value) {
+			return fmt.Errorf("<%s>: invalid %s=%q", e.name.Local, a.Name.Local, a.Value)
+		}
+	}
+	for name, d := range attrs {
+		if d.required && !slices.ContainsFunc(e.attrs, func(a xml.Attr) bool { return a.Name == name }) {
+			return fmt.Errorf("<%s>: missing attribute %s", e.name.Local, name.Local)
+		}
+	}
+	if !text && strings.TrimSpace(e.text) != "" {
+		return fmt.Errorf("<%s>: text not allowed", e.name.Local)
+	}
+	v.lastErr = nil
+	if !slices.Contains(v.seq(p.children, e.children, 0), len(e.children)) {
+		if v.lastErr != nil {
+			return fmt.Errorf("<%s>: %w", e.name.Local, v.lastErr)
+		}
+		var names []string
+		for _, c := range e.children {
+			names = append(names, c.name.Local)
+		}
+		return fmt.Errorf("<%s>: invalid content %v", e.name.Local, names)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package tei
+
+import "github.com/FreeDictionary/wiktionary-schema-go/rhyme"
+
+// usageTypes assigns wiktextract sense tags to TEI Lex-0 <usg> types.
+// Tags that are neither usage labels nor grammatical features are
+// written as type "hint".
+var usageTypes = map[string]string{
+	"archaic":      "time",
+	"obsolete":     "time",
+	"dated":        "time",
+	"historical":   "time",
+	"rare":         "frequency",
+	"uncommon":     "frequency",
+	"nonstandard":  "normativity",
+	"proscribed":   "normativity",
+	"misspelling":  "normativity",
+	"informal":     "socioCultural",
+	"colloquial":   "socioCultural",
+	"slang":        "socioCultural",
+	"formal":       "socioCultural",
+	"vulgar":       "socioCultural",
+	"literary":     "textType",
+	"poetic":       "textType",
+	"derogatory":   "attitude",
+	"pejorative":   "attitude",
+	"offensive":    "attitude",
+	"humorous":     "attitude",
+	"figuratively": "meaning",
+	"figurative":   "meaning",
+	"metaphoric":   "meaning",
+}
+
+// gramTypes assigns grammatical tags to TEI <gram> types.
+var gramTypes = map[string]string{
+	"singular":       "number",
+	"plural":         "number",
+	"dual":           "number",
+	"plural-only":    "number",
+	"singular-only":  "number",
+	"masculine":      "gender",
+	"feminine":       "gender",
+	"neuter":         "gender",
+	"common":         "gender",
+	"nominative":     "case",
+	"accusative":     "case",
+	"genitive":       "case",
+	"dative":         "case",
+	"possessive":     "case",
+	"first-person":   "person",
+	"second-person":  "person",
+	"third-person":   "person",
+	"present":        "tense",
+	"past":           "tense",
+	"future":         "tense",
+	"participle":     "mood",
+	"infinitive":     "mood",
+	"gerund":         "mood",
+	"imperative":     "mood",
+	"subjunctive":    "mood",
+	"indicative":     "mood",
+	"comparative":    "degree",
+	"superlative":    "degree",
+	"progressive":    "aspect",
+	"perfect":        "aspect",
+	"passive":        "voice",
+	"active":         "voice",
+	"transitive":     "subcategorization",
+	"intransitive":   "subcategorization",
+	"ditransitive":   "subcategorization",
+	"ambitransitive": "subcategorization",
+	"countable":      "countability",
+	"uncountable":    "countability",
+}
+
+// regions gives the BCP 47 tag used as xml:lang for the pronunciations
+// of an accent.
+var regions = map[string]string{
+	"US":           "en-US",
+	"UK":           "en-GB",
+	"Australia":    "en-AU",
+	"Canada":       "en-CA",
+	"New-Zealand":  "en-NZ",
+	"Ireland":      "en-IE",
+	"India":        "en-IN",
+	"South-Africa": "en-ZA",
+}
+
+// accentLang returns the xml:lang of a pronunciation tagged with
+// `tags`, or "" if none of them is a known accent.
+func accentLang(tags []string) string {
+	for _, t := range tags {
+		if a, ok := rhyme.Accent(t); ok {
+			if lang, ok := regions[a]; ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// formType returns the TEI type of a form from its tags.
+func formType(tags []string) string {
+	for _, t := range tags {
+		switch t {
+		case "abbreviation", "acronym", "initialism":
+			return "abbreviation"
+		case "alternative", "romanization", "alt-of", "archaic":
+			return "variant"
+		}
+	}
+	return "inflected"
+}
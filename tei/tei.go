@@ -0,0 +1,139 @@
+// Package tei serializes [en.WordData] as TEI Lex-0 dictionary entries.
+//
+// Each WordData becomes an <entry>: the headword and its pronunciations
+// in a lemma <form>, the inflected forms in further <form>s, the part of
+// speech in a <gramGrp>, the etymology in <etym> and the nested senses
+// in <sense> elements holding <usg> labels, a <def> and example <cit>s.
+package tei
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// NAMESPACE is the TEI namespace.
+const NAMESPACE string = "http://www.tei-c.org/ns/1.0"
+
+// Header describes the corpus in the <teiHeader>.
+type Header struct {
+	Title     string
+	Publisher string
+	// description of the source in <sourceDesc>
+	Source string
+	// language of the dictionary, written as xml:lang on <body>
+	Lang string
+}
+
+// Encoder writes a TEI document made of a header and a stream of
+// entries.
+type Encoder struct {
+	w      io.Writer
+	header Header
+	begun  bool
+	// entry identifiers written
+	ids map[string]bool
+}
+
+// NewEncoder returns an encoder writing to `w`. The <TEI> root and the
+// <teiHeader> are written with the first entry (or by
+// [Encoder.Close] for an empty document).
+func NewEncoder(w io.Writer, header Header) *Encoder {
+	if header.Title == "" {
+		header.Title = "Wiktionary"
+	}
+	if header.Publisher == "" {
+		header.Publisher = "Wiktionary contributors"
+	}
+	if header.Source == "" {
+		header.Source = "Extracted from Wiktionary by wiktextract."
+	}
+	return &Encoder{w: w, header: header, ids: make(map[string]bool)}
+}
+
+func (e *Encoder) begin() error {
+	if e.begun {
+		return nil
+	}
+	e.begun = true
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<TEI xmlns="` + NAMESPACE + `"><teiHeader><fileDesc><titleStmt><title>`)
+	xml.EscapeText(&sb, []byte(e.header.Title))
+	sb.WriteString(`</title></titleStmt><publicationStmt><publisher>`)
+	xml.EscapeText(&sb, []byte(e.header.Publisher))
+	sb.WriteString(`</publisher></publicationStmt><sourceDesc><p>`)
+	xml.EscapeText(&sb, []byte(e.header.Source))
+	sb.WriteString(`</p></sourceDesc></fileDesc></teiHeader><text><body`)
+	if e.header.Lang != "" {
+		sb.WriteString(` xml:lang="`)
+		xml.EscapeText(&sb, []byte(e.header.Lang))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString(">\n")
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+// Encode writes the entry of `w`. Entry identifiers are made unique
+// within the document.
+func (e *Encoder) Encode(w *en.WordData) error {
+	if err := e.begin(); err != nil {
+		return err
+	}
+	id := EntryID(w)
+	for n := 2; e.ids[id]; n++ {
+		id = fmt.Sprintf("%s-%d", EntryID(w), n)
+	}
+	e.ids[id] = true
+	data, err := xml.Marshal(newEntry(w, id))
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close ends the document. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if err := e.begin(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "</body></text></TEI>\n")
+	return err
+}
+
+// MarshalEntry returns the <entry> element of `w` on its own.
+func MarshalEntry(w *en.WordData) ([]byte, error) {
+	return xml.Marshal(newEntry(w, EntryID(w)))
+}
+
+// EntryID returns the xml:id of the entry of `w`, made of its language
+// code, headword, part of speech and etymology number, e.g.
+// "en-water-noun" or "en-bass-noun-2".
+func EntryID(w *en.WordData) string {
+	id := ncName(w.LangCode + "-" + w.Word + "-" + w.Pos)
+	if w.EtymologyNumber != nil {
+		id += fmt.Sprintf("-%d", *w.EtymologyNumber)
+	}
+	return id
+}
+
+// ncName replaces the characters that are not allowed in an xml:id.
+func ncName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, s)
+	if r, _ := utf8.DecodeRuneInString(s); !(unicode.IsLetter(r) || r == '_') {
+		s = "_" + s
+	}
+	return s
+}
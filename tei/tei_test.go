@@ -0,0 +1,170 @@
+package tei_test
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/tei"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var words = []*en.WordData{
+	{
+		Word: "bass", Pos: "noun", Lang: "English", LangCode: "en", EtymologyNumber: ptr(1),
+		EtymologyText: ptr("From Middle English bace, alteration of base."),
+		Sounds: []en.SoundData{
+			{Ipa: ptr("/beɪs/"), Tags: []string{"General-American"}},
+			{Audio: ptr("En-us-bass-low.ogg")},
+		},
+		Forms: []en.FormData{
+			{Form: "basses", Tags: []string{"plural"}},
+			{Form: "en-noun", Tags: []string{"inflection-template"}},
+		},
+		Senses: []en.SenseData{
+			{
+				Glosses: []string{"A low spectrum of sound tones."},
+				Tags:    []string{"uncountable"},
+				Topics:  []string{"music"},
+				Examples: []en.ExampleData{{
+					Text:            "Turn up the bass & treble.",
+					BoldTextOffsets: [][2]int{{12, 16}},
+					Ref:             ptr("2001, Some <Book>"),
+				}},
+			},
+			{Glosses: []string{"A low spectrum of sound tones.", "A bass guitar."}, Tags: []string{"informal", "US"}},
+		},
+	},
+	{
+		Word: "bass", Pos: "noun", Lang: "English", LangCode: "en", EtymologyNumber: ptr(2),
+		Senses: []en.SenseData{{Glosses: []string{"A perch-like fish."}, Tags: []string{"countable"}}},
+	},
+	{
+		Word: "bass", Pos: "noun", Lang: "English", LangCode: "en", EtymologyNumber: ptr(2),
+		Senses: []en.SenseData{{Glosses: []string{"A duplicate id."}}},
+	},
+	{
+		Word: "食べる", Pos: "verb", Lang: "Japanese", LangCode: "ja",
+		Forms: []en.FormData{{Form: "taberu", Tags: []string{"romanization"}}},
+		Senses: []en.SenseData{{
+			Glosses:  []string{"to eat"},
+			Tags:     []string{"transitive", "archaic", "figuratively"},
+			Examples: []en.ExampleData{{Text: "ご飯を食べる", Roman: ptr("gohan o taberu"), Translation: ptr("to eat a meal")}},
+		}},
+	},
+	{Word: "3D", Pos: "adj", Lang: "English", LangCode: "en"},
+}
+
+func encode(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := tei.NewEncoder(&buf, tei.Header{Title: "Wiktionary & friends", Lang: "en"})
+	for _, w := range words {
+		if err := enc.Encode(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestEncode(t *testing.T) {
+	doc := encode(t)
+	for _, want := range []string{
+		`<TEI xmlns="http://www.tei-c.org/ns/1.0">`,
+		`<title>Wiktionary &amp; friends</title>`,
+		`<body xml:lang="en">`,
+		`<entry xml:id="en-bass-noun-1" xml:lang="en"><form type="lemma"><orth>bass</orth><pron notation="ipa" xml:lang="en-US">/beɪs/</pron></form>`,
+		`<form type="inflected"><orth>basses</orth><gramGrp><gram type="number">plural</gram></gramGrp></form>`,
+		`<gramGrp><gram type="pos">noun</gram></gramGrp><etym>From Middle English bace, alteration of base.</etym>`,
+		`<sense xml:id="en-bass-noun-1-s1" n="1"><usg type="domain">music</usg><gramGrp><gram type="countability">uncountable</gram></gramGrp><def>A low spectrum of sound tones.</def>`,
+		`<cit type="example"><quote>Turn up the <hi rend="bold">bass</hi> &amp; treble.</quote><bibl>2001, Some &lt;Book&gt;</bibl></cit>`,
+		`<sense xml:id="en-bass-noun-1-s1.1" n="1.1"><usg type="socioCultural">informal</usg><usg type="geographic">US</usg><def>A bass guitar.</def></sense></sense>`,
+		`<entry xml:id="en-bass-noun-2-2" xml:lang="en">`,
+		`<form type="variant"><orth>taberu</orth></form>`,
+		`<usg type="time">archaic</usg><usg type="meaning">figuratively</usg><gramGrp><gram type="subcategorization">transitive</gram></gramGrp>`,
+		`<cit type="transliteration"><quote>gohan o taberu</quote></cit><cit type="translation" xml:lang="en"><quote>to eat a meal</quote></cit>`,
+		`<entry xml:id="en-3D-adj" xml:lang="en">`,
+		`</body></text></TEI>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Contains(doc, "inflection-template") {
+		t.Error("inflection table metadata leaked into the forms")
+	}
+}
+
+func TestEntryIDs(t *testing.T) {
+	var buf bytes.Buffer
+	enc := tei.NewEncoder(&buf, tei.Header{})
+	for _, w := range []*en.WordData{
+		{Word: "a b", Pos: "noun", LangCode: "en"},
+		{Word: "a_b", Pos: "noun", LangCode: "en"},
+		{Word: "a_b", Pos: "noun", LangCode: "en", EtymologyNumber: ptr(2)},
+		// a digit encoded with a first byte that is a letter in Latin-1
+		{Word: "x", Pos: "noun", LangCode: "٣"},
+	} {
+		if err := enc.Encode(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var ids []string
+	for _, m := range regexp.MustCompile(`<entry xml:id="([^"]+)"`).FindAllStringSubmatch(buf.String(), -1) {
+		ids = append(ids, m[1])
+	}
+	want := []string{"en-a_b-noun", "en-a_b-noun-2", "en-a_b-noun-2-2", "_٣-x-noun"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, err := loadSchema("testdata/lex0-subset.rng")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Validate(strings.NewReader(encode(t))); err != nil {
+		t.Errorf("document does not validate: %v", err)
+	}
+
+	var empty bytes.Buffer
+	if err := tei.NewEncoder(&empty, tei.Header{}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Validate(strings.NewReader(empty.String())); err != nil {
+		t.Errorf("empty document does not validate: %v", err)
+	}
+
+	// make sure the validator rejects what it should
+	for _, bad := range []string{
+		`<entry xml:id="x"><orth>x</orth></entry>`,
+		`<entry xml:id="x"><form type="weird"><orth>x</orth></form></entry>`,
+		`<entry><form type="lemma"><orth>x</orth></form></entry>`,
+		`<entry xml:id="x"><form type="lemma"><orth>x</orth></form><sense xml:id="s" n="1"><def>a</def><def>b</def></sense></entry>`,
+		`<entry xml:id="x"><form type="lemma"><orth>x</orth></form>stray text</entry>`,
+	} {
+		doc := strings.Replace(empty.String(), "<body>", "<body>"+bad, 1)
+		if err := v.Validate(strings.NewReader(doc)); err == nil {
+			t.Errorf("invalid entry accepted: %s", bad)
+		}
+	}
+}
+
+func TestMarshalEntry(t *testing.T) {
+	data, err := tei.MarshalEntry(words[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<entry xml:id="en-3D-adj" xml:lang="en"><form type="lemma"><orth>3D</orth></form><gramGrp><gram type="pos">adj</gram></gramGrp></entry>`
+	if string(data) != want {
+		t.Errorf("got %s\nwant %s", data, want)
+	}
+}
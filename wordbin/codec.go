@@ -0,0 +1,322 @@
+package wordbin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// interned are the JSON names of the fields whose strings are stored
+// once in the string table and referred to by index: the small, very
+// repetitive vocabularies.
+var interned = map[string]bool{
+	"lang":      true,
+	"lang_code": true,
+	"pos":       true,
+	"tags":      true,
+	"raw_tags":  true,
+	"topics":    true,
+	"source":    true,
+	"name":      true,
+	"code":      true,
+}
+
+var wordType = reflect.TypeFor[en.WordData]()
+
+// field is a struct field as it is encoded.
+type field struct {
+	index    int
+	interned bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf returns the encoded fields of a struct type: all of them, in
+// declaration order.
+func fieldsOf(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	fs := make([]field, t.NumField())
+	for i := range fs {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fs[i] = field{index: i, interned: interned[name]}
+	}
+	fieldCache.Store(t, fs)
+	return fs
+}
+
+// fingerprint hashes the layout of [en.WordData]: the names, JSON tags
+// and kinds of all the fields of the types it contains. Files written
+// with a different layout cannot be decoded and are rejected.
+var fingerprint = sync.OnceValue(func() uint64 {
+	h := fnv.New64a()
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		fmt.Fprintf(h, "%s:%s;", t.Name(), t.Kind())
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			if t.Kind() == reflect.Array {
+				fmt.Fprintf(h, "%d;", t.Len())
+			}
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case reflect.Struct:
+			if seen[t] {
+				return
+			}
+			seen[t] = true
+			for i := range t.NumField() {
+				f := t.Field(i)
+				fmt.Fprintf(h, "%s %q ", f.Name, f.Tag.Get("json"))
+				walk(f.Type)
+			}
+		}
+	}
+	walk(wordType)
+	return h.Sum64()
+})
+
+// encoder appends values to a buffer.
+//
+// Structs are encoded as the uvarint index+1 of each non-zero field
+// followed by its value, and a 0. Strings are a uvarint length and the
+// bytes, or the uvarint index in the string table for interned fields;
+// integers are varints, booleans a byte and floats 8 bytes. Slices and
+// maps start with their uvarint length, map keys being sorted. Pointers
+// are a 0 byte for nil, or a 1 byte and the value; non-nil pointer
+// fields of structs are only encoded as their value.
+type encoder struct {
+	buf    []byte
+	intern func(string) uint64
+}
+
+func (e *encoder) value(v reflect.Value, interned bool) error {
+	switch v.Kind() {
+	case reflect.String:
+		if interned {
+			e.buf = binary.AppendUvarint(e.buf, e.intern(v.String()))
+		} else {
+			e.buf = binary.AppendUvarint(e.buf, uint64(v.Len()))
+			e.buf = append(e.buf, v.String()...)
+		}
+	case reflect.Bool:
+		b := byte(0)
+		if v.Bool() {
+			b = 1
+		}
+		e.buf = append(e.buf, b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = binary.AppendVarint(e.buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.buf = binary.AppendUvarint(e.buf, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0)
+			return nil
+		}
+		e.buf = append(e.buf, 1)
+		return e.value(v.Elem(), interned)
+	case reflect.Slice:
+		e.buf = binary.AppendUvarint(e.buf, uint64(v.Len()))
+		fallthrough
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := e.value(v.Index(i), interned); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		e.buf = binary.AppendUvarint(e.buf, uint64(v.Len()))
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, k := range keys {
+			if err := e.value(k, false); err != nil {
+				return err
+			}
+			if err := e.value(v.MapIndex(k), interned); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, f := range fieldsOf(v.Type()) {
+			fv := v.Field(f.index)
+			if fv.IsZero() {
+				continue
+			}
+			e.buf = binary.AppendUvarint(e.buf, uint64(f.index+1))
+			if fv.Kind() == reflect.Pointer {
+				fv = fv.Elem()
+			}
+			if err := e.value(fv, f.interned); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, 0)
+	default:
+		return fmt.Errorf("wordbin: cannot encode %s", v.Type())
+	}
+	return nil
+}
+
+// ErrCorrupt is returned when the data cannot be decoded.
+var ErrCorrupt = errors.New("wordbin: corrupt data")
+
+// decoder decodes values from a record.
+type decoder struct {
+	data    []byte
+	pos     int
+	strings []string
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, ErrCorrupt
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrCorrupt
+	}
+	d.pos++
+	return d.data[d.pos-1], nil
+}
+
+// length reads a length, which cannot exceed the bytes left as every
+// element takes at least one byte.
+func (d *decoder) length() (int, error) {
+	n, err := d.uvarint()
+	if err != nil || n > uint64(len(d.data)-d.pos) {
+		return 0, ErrCorrupt
+	}
+	return int(n), nil
+}
+
+func (d *decoder) value(v reflect.Value, interned bool) error {
+	switch v.Kind() {
+	case reflect.String:
+		if interned {
+			i, err := d.uvarint()
+			if err != nil || i >= uint64(len(d.strings)) {
+				return ErrCorrupt
+			}
+			v.SetString(d.strings[i])
+			return nil
+		}
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		v.SetString(string(d.data[d.pos : d.pos+n]))
+		d.pos += n
+	case reflect.Bool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(d.data[d.pos:])
+		if n <= 0 {
+			return ErrCorrupt
+		}
+		d.pos += n
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		if d.pos+8 > len(d.data) {
+			return ErrCorrupt
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:])))
+		d.pos += 8
+	case reflect.Pointer:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		if b == 0 {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return d.value(v.Elem(), interned)
+	case reflect.Slice:
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		fallthrough
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := d.value(v.Index(i), interned); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for range n {
+			k := reflect.New(v.Type().Key()).Elem()
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(k, false); err != nil {
+				return err
+			}
+			if err := d.value(e, interned); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		fs := fieldsOf(v.Type())
+		for {
+			i, err := d.uvarint()
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				return nil
+			}
+			if i > uint64(len(fs)) {
+				return ErrCorrupt
+			}
+			f := fs[i-1]
+			fv := v.Field(f.index)
+			if fv.Kind() == reflect.Pointer {
+				fv.Set(reflect.New(fv.Type().Elem()))
+				fv = fv.Elem()
+			}
+			if err := d.value(fv, f.interned); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("wordbin: cannot decode %s", v.Type())
+	}
+	return nil
+}
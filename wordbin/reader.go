@@ -0,0 +1,224 @@
+package wordbin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/mmap"
+)
+
+var (
+	// ErrFormat is returned for data that is not a wordbin file, or of
+	// an unsupported version.
+	ErrFormat = errors.New("wordbin: not a wordbin file")
+	// ErrLayout is returned for files written for other Go types.
+	ErrLayout = errors.New("wordbin: file written with a different en.WordData layout")
+)
+
+// Reader reads a file. Its methods may be called concurrently.
+type Reader struct {
+	unmap func() error
+
+	count   int
+	keys    []byte
+	index   []byte
+	records []byte
+	strings []string
+}
+
+// Open memory-maps and opens the file `name`.
+func Open(name string) (*Reader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, unmap, err := mmap.Map(f)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	r.unmap = unmap
+	return r, nil
+}
+
+// NewReader reads a file held in memory. Only the string table is
+// decoded; entries are decoded when they are read.
+func NewReader(data []byte) (*Reader, error) {
+	if len(data) < headerSize+footerSize || string(data[:len(MAGIC)]) != MAGIC || string(data[len(data)-len(MAGIC):]) != MAGIC {
+		return nil, ErrFormat
+	}
+	if v := binary.LittleEndian.Uint32(data[len(MAGIC):]); v != VERSION {
+		return nil, fmt.Errorf("%w: version %d", ErrFormat, v)
+	}
+	footer := data[len(data)-footerSize:]
+	var f [5]uint64
+	for i := range f {
+		f[i] = binary.LittleEndian.Uint64(footer[8*i:])
+	}
+	count, stringsOff, keysOff, indexOff, fp := f[0], f[1], f[2], f[3], f[4]
+	if fp != fingerprint() {
+		return nil, ErrLayout
+	}
+	end := uint64(len(data) - footerSize)
+	if !(headerSize <= stringsOff && stringsOff <= keysOff && keysOff <= indexOff && indexOff <= end) ||
+		count > (end-indexOff)/indexEntry || end-indexOff != count*indexEntry {
+		return nil, ErrCorrupt
+	}
+
+	r := &Reader{
+		count:   int(count),
+		records: data[:stringsOff],
+		keys:    data[keysOff:indexOff],
+		index:   data[indexOff:end],
+	}
+	d := &decoder{data: data[stringsOff:keysOff]}
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	r.strings = make([]string, n)
+	for i := range r.strings {
+		l, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		r.strings[i] = string(d.data[d.pos : d.pos+l])
+		d.pos += l
+	}
+	return r, nil
+}
+
+// Close releases the memory mapping of a file opened with [Open].
+func (r *Reader) Close() error {
+	if r.unmap == nil {
+		return nil
+	}
+	err := r.unmap()
+	r.unmap = nil
+	return err
+}
+
+// Len returns the number of entries.
+func (r *Reader) Len() int {
+	return r.count
+}
+
+// key returns the key and record offset of the i-th index entry.
+func (r *Reader) key(i int) ([]byte, uint64, error) {
+	e := r.index[i*indexEntry:]
+	off, keyOff := binary.LittleEndian.Uint64(e), binary.LittleEndian.Uint64(e[8:])
+	if keyOff >= uint64(len(r.keys)) {
+		return nil, 0, ErrCorrupt
+	}
+	n, size := binary.Uvarint(r.keys[keyOff:])
+	if size <= 0 || n > uint64(len(r.keys))-keyOff-uint64(size) {
+		return nil, 0, ErrCorrupt
+	}
+	start := keyOff + uint64(size)
+	return r.keys[start : start+n], off, nil
+}
+
+// record decodes the record at `off`.
+func (r *Reader) record(off uint64) (*en.WordData, int, error) {
+	if off < headerSize || off >= uint64(len(r.records)) {
+		return nil, 0, ErrCorrupt
+	}
+	n, size := binary.Uvarint(r.records[off:])
+	if size <= 0 || n > uint64(len(r.records))-off-uint64(size) {
+		return nil, 0, ErrCorrupt
+	}
+	start := off + uint64(size)
+	d := &decoder{data: r.records[start : start+n], strings: r.strings}
+	w := new(en.WordData)
+	if err := d.value(reflect.ValueOf(w).Elem(), false); err != nil {
+		return nil, 0, err
+	}
+	if d.pos != len(d.data) {
+		return nil, 0, ErrCorrupt
+	}
+	return w, size + int(n), nil
+}
+
+// Get returns the entries of `word` in the language `langCode`, or in
+// all languages if it is empty, sorted by language code and in the
+// order they were added. The index is binary searched, and only the
+// matching entries are decoded.
+func (r *Reader) Get(word, langCode string) ([]*en.WordData, error) {
+	prefix := word + "\x00"
+	exact := langCode != ""
+	if exact {
+		prefix += langCode
+	}
+	var err error
+	i := sort.Search(r.count, func(i int) bool {
+		k, _, kerr := r.key(i)
+		if kerr != nil {
+			err = kerr
+			return true
+		}
+		return string(k) >= prefix
+	})
+	if err != nil {
+		return nil, err
+	}
+	var words []*en.WordData
+	for ; i < r.count; i++ {
+		k, off, err := r.key(i)
+		if err != nil {
+			return nil, err
+		}
+		if exact && string(k) != prefix || !exact && !bytes.HasPrefix(k, []byte(prefix)) {
+			break
+		}
+		w, _, err := r.record(off)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+// All returns the entries in the order they were added, reading the
+// records sequentially. Iteration stops after the first error.
+func (r *Reader) All() iter.Seq2[*en.WordData, error] {
+	return func(yield func(*en.WordData, error) bool) {
+		off := uint64(headerSize)
+		for range r.count {
+			w, n, err := r.record(off)
+			if !yield(w, err) || err != nil {
+				return
+			}
+			off += uint64(n)
+		}
+	}
+}
+
+// Sorted returns the entries sorted by headword, then language code.
+// Iteration stops after the first error.
+func (r *Reader) Sorted() iter.Seq2[*en.WordData, error] {
+	return func(yield func(*en.WordData, error) bool) {
+		for i := range r.count {
+			_, off, err := r.key(i)
+			var w *en.WordData
+			if err == nil {
+				w, _, err = r.record(off)
+			}
+			if !yield(w, err) || err != nil {
+				return
+			}
+		}
+	}
+}
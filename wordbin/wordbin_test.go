@@ -0,0 +1,214 @@
+package wordbin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/wordbin"
+)
+
+const corpus = `{"word":"water","lang":"English","lang_code":"en","pos":"noun","etymology_number":1,"etymology_text":"From Old English wæter.","head_templates":[{"name":"en-noun","args":{"1":"~","2":"s"},"expansion":"water (countable and uncountable, plural waters)"}],"forms":[{"form":"waters","tags":["plural"]},{"form":"en-noun","tags":["inflection-template"],"source":"head"}],"sounds":[{"ipa":"/ˈwɔːtə/","tags":["Received-Pronunciation"]},{"audio":"En-us-water.ogg","ogg_url":"https://upload.wikimedia.org/x.ogg"}],"senses":[{"glosses":["A clear liquid."],"tags":["uncountable"],"categories":["en:Liquids"],"examples":[{"text":"Drink water.","bold_text_offsets":[[6,11]],"type":"example"}]},{"glosses":["A body of water."],"tags":["countable"],"categories":[]}],"translations":[{"lang":"French","lang_code":"fr","code":"fr","word":"eau","tags":["feminine"],"sense":"liquid"}],"synonyms":[{"word":"H2O","tags":["informal"]}]}
+{"word":"water","lang":"English","lang_code":"en","pos":"verb","senses":[{"glosses":["To pour water on."]}]}
+{"word":"eau","lang":"French","lang_code":"fr","pos":"noun","senses":[{"glosses":["water"],"tags":["feminine"]}]}
+{"word":"water","lang":"Dutch","lang_code":"nl","pos":"noun","senses":[{"glosses":["water"],"tags":["neuter"]}]}
+{"word":"wat","lang":"Dutch","lang_code":"nl","pos":"pron","senses":[{"glosses":["what"]}]}
+`
+
+func words(t *testing.T) []*en.WordData {
+	t.Helper()
+	var ws []*en.WordData
+	for l := range strings.Lines(corpus) {
+		w := new(en.WordData)
+		if err := json.Unmarshal([]byte(l), w); err != nil {
+			t.Fatal(err)
+		}
+		ws = append(ws, w)
+	}
+	return ws
+}
+
+func encode(t *testing.T, ws []*en.WordData) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := wordbin.NewWriter(&buf)
+	for _, word := range ws {
+		if err := w.Add(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sameJSON compares entries by their JSON encodings.
+func sameJSON(t *testing.T, got, want *en.WordData) {
+	t.Helper()
+	g, _ := json.Marshal(got)
+	w, _ := json.Marshal(want)
+	if !bytes.Equal(g, w) {
+		t.Errorf("got\n%s\nwant\n%s", g, w)
+	}
+}
+
+func key(w *en.WordData) string {
+	return w.Word + "/" + w.LangCode + "/" + w.Pos
+}
+
+func TestRoundTrip(t *testing.T) {
+	ws := words(t)
+	data := encode(t, ws)
+	if len(data) >= len(corpus) {
+		t.Errorf("%d bytes, JSON is %d", len(data), len(corpus))
+	}
+	r, err := wordbin.NewReader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != len(ws) {
+		t.Errorf("Len = %d", r.Len())
+	}
+	i := 0
+	for w, err := range r.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameJSON(t, w, ws[i])
+		i++
+	}
+	if i != len(ws) {
+		t.Errorf("All yielded %d entries", i)
+	}
+
+	// empty slices are kept
+	got, err := r.Get("water", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := got[0].Senses[1].Categories; s == nil || len(s) != 0 {
+		t.Errorf("empty categories = %#v", s)
+	}
+	if n := got[0].EtymologyNumber; n == nil || *n != 1 {
+		t.Errorf("etymology_number = %v", n)
+	}
+}
+
+func TestGet(t *testing.T) {
+	r, err := wordbin.NewReader(encode(t, words(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word, lang string
+		want       []string
+	}{
+		{"water", "en", []string{"water/en/noun", "water/en/verb"}},
+		{"water", "nl", []string{"water/nl/noun"}},
+		{"water", "", []string{"water/en/noun", "water/en/verb", "water/nl/noun"}},
+		{"wat", "", []string{"wat/nl/pron"}},
+		{"wat", "en", nil},
+		{"eau", "fr", []string{"eau/fr/noun"}},
+		{"fire", "", nil},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		got, err := r.Get(tt.word, tt.lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, w := range got {
+			keys = append(keys, key(w))
+		}
+		if strings.Join(keys, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Get(%q, %q) = %v, want %v", tt.word, tt.lang, keys, tt.want)
+		}
+	}
+
+	var sorted []string
+	for w, err := range r.Sorted() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorted = append(sorted, key(w))
+	}
+	want := "eau/fr/noun wat/nl/pron water/en/noun water/en/verb water/nl/noun"
+	if got := strings.Join(sorted, " "); got != want {
+		t.Errorf("Sorted = %s", got)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "words.bin")
+	if err := os.WriteFile(name, encode(t, words(t)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := wordbin.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Get("eau", "fr")
+	if err != nil || len(got) != 1 || got[0].Senses[0].Tags[0] != "feminine" {
+		t.Errorf("Get = %v, %v", got, err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.bin")
+	os.WriteFile(empty, nil, 0o644)
+	if _, err := wordbin.Open(empty); !errors.Is(err, wordbin.ErrFormat) {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	r, err := wordbin.NewReader(encode(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Get("water", ""); r.Len() != 0 || got != nil || err != nil {
+		t.Errorf("Len = %d, Get = %v, %v", r.Len(), got, err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	data := encode(t, words(t))
+
+	if _, err := wordbin.NewReader([]byte(corpus)); !errors.Is(err, wordbin.ErrFormat) {
+		t.Errorf("JSON: %v", err)
+	}
+	version := bytes.Clone(data)
+	version[len(wordbin.MAGIC)] = 9
+	if _, err := wordbin.NewReader(version); !errors.Is(err, wordbin.ErrFormat) {
+		t.Errorf("version 9: %v", err)
+	}
+	layout := bytes.Clone(data)
+	layout[len(layout)-len(wordbin.MAGIC)-1] ^= 0xff
+	if _, err := wordbin.NewReader(layout); !errors.Is(err, wordbin.ErrLayout) {
+		t.Errorf("fingerprint: %v", err)
+	}
+
+	// damaged records are reported, never panic
+	for i := len(wordbin.MAGIC) + 8; i < len(data); i++ {
+		damaged := bytes.Clone(data)
+		damaged[i] ^= 0x5a
+		r, err := wordbin.NewReader(damaged)
+		if err != nil {
+			continue
+		}
+		for _, err := range r.All() {
+			if err != nil {
+				break
+			}
+		}
+		r.Get("water", "")
+	}
+}
@@ -0,0 +1,153 @@
+// Package wordbin stores [en.WordData] entries in a compact binary
+// file that is read in place, through a memory mapping, and gives
+// random access to the entries by headword.
+//
+// A file is laid out as follows; integers in the header, index and
+// footer are little-endian.
+//
+//	header   MAGIC, version uint32, reserved uint32
+//	records  uvarint length and encoded entry, for each entry
+//	strings  uvarint count, then uvarint length and bytes of each
+//	keys     uvarint length and bytes of each key: word "\x00" lang_code
+//	index    record offset uint64 and key offset uint64 of each entry,
+//	         sorted by key then record offset
+//	footer   entry count, offsets of the strings, keys and index
+//	         sections, and layout fingerprint, all uint64; MAGIC
+//
+// Entries are encoded field by field, skipping empty fields; the small
+// vocabularies of tags, language codes and parts of speech are stored
+// once in the string table. The fingerprint identifies the layout of
+// the Go types: a file written by a version of this module with other
+// fields is rejected with [ErrLayout] and must be written again.
+package wordbin
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"slices"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+const (
+	MAGIC   string = "WIKTBIN\x00"
+	VERSION uint32 = 1
+
+	// sizes in bytes
+	headerSize = 16
+	footerSize = 48
+	indexEntry = 16
+)
+
+// Writer writes a file. Records are written as they are added; the
+// string table, keys and index by [Writer.Close].
+type Writer struct {
+	w   *bufio.Writer
+	off uint64
+	enc encoder
+	err error
+
+	strings []string
+	ids     map[string]uint64
+	entries []indexed
+}
+
+// indexed is an entry of the index.
+type indexed struct {
+	key string
+	off uint64
+}
+
+// NewWriter writes the header to `w`.
+func NewWriter(w io.Writer) *Writer {
+	wr := &Writer{w: bufio.NewWriterSize(w, 1<<16), ids: make(map[string]uint64)}
+	wr.enc.intern = wr.intern
+	var h []byte
+	h = append(h, MAGIC...)
+	h = binary.LittleEndian.AppendUint32(h, VERSION)
+	h = binary.LittleEndian.AppendUint32(h, 0)
+	wr.write(h)
+	return wr
+}
+
+func (w *Writer) intern(s string) uint64 {
+	if id, ok := w.ids[s]; ok {
+		return id
+	}
+	id := uint64(len(w.strings))
+	w.strings = append(w.strings, s)
+	w.ids[s] = id
+	return id
+}
+
+func (w *Writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.w.Write(p)
+	w.off += uint64(len(p))
+}
+
+// Add writes an entry.
+func (w *Writer) Add(word *en.WordData) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.enc.buf = w.enc.buf[:0]
+	if err := w.enc.value(reflect.ValueOf(word).Elem(), false); err != nil {
+		return err
+	}
+	w.entries = append(w.entries, indexed{key: word.Word + "\x00" + word.LangCode, off: w.off})
+	w.write(binary.AppendUvarint(nil, uint64(len(w.enc.buf))))
+	w.write(w.enc.buf)
+	return w.err
+}
+
+// Close writes the string table, the index and the footer. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	var b []byte
+	stringsOff := w.off
+	b = binary.AppendUvarint(b, uint64(len(w.strings)))
+	for _, s := range w.strings {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	w.write(b)
+
+	// the entries were added in record order, which the stable sort
+	// keeps for equal keys
+	slices.SortStableFunc(w.entries, func(a, b indexed) int { return cmp.Compare(a.key, b.key) })
+	keysOff := w.off
+	keyOffs := make([]uint64, len(w.entries))
+	var keys bytes.Buffer
+	for i, e := range w.entries {
+		keyOffs[i] = uint64(keys.Len())
+		keys.Write(binary.AppendUvarint(nil, uint64(len(e.key))))
+		keys.WriteString(e.key)
+	}
+	w.write(keys.Bytes())
+
+	indexOff := w.off
+	b = make([]byte, 0, len(w.entries)*indexEntry)
+	for i, e := range w.entries {
+		b = binary.LittleEndian.AppendUint64(b, e.off)
+		b = binary.LittleEndian.AppendUint64(b, keyOffs[i])
+	}
+	w.write(b)
+
+	b = b[:0]
+	for _, x := range []uint64{uint64(len(w.entries)), stringsOff, keysOff, indexOff, fingerprint()} {
+		b = binary.LittleEndian.AppendUint64(b, x)
+	}
+	b = append(b, MAGIC...)
+	w.write(b)
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
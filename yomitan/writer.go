@@ -0,0 +1,287 @@
+package yomitan
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/internal/article"
+)
+
+// Options configures a [Writer].
+type Options struct {
+	// defaults to "Wiktionary"
+	Title string
+	// defaults to today, formatted as YYYY-MM-DD
+	Revision    string
+	Author      string
+	URL         string
+	Description string
+	Attribution string
+	// defaults to the language of the first word added
+	SourceLanguage string
+	// defaults to "en"
+	TargetLanguage string
+	// number of terms per term bank, 10000 by default
+	BankSize int
+	// do not add terms for inflected forms
+	NoForms bool
+}
+
+// posRules maps parts of speech to the condition names of Yomitan's
+// English deinflector.
+var posRules = map[string]string{
+	"verb": "v",
+	"noun": "n",
+	"adj":  "adj",
+}
+
+// skippedFormTags mark forms that are not inflections of the headword.
+var skippedFormTags = []string{"canonical", "romanization", "transliteration"}
+
+// Writer builds a Yomitan dictionary archive. Terms are written in
+// banks of [Options.BankSize] as they are added; the tag bank and
+// index.json are written by [Writer.Close].
+type Writer struct {
+	zw   *zip.Writer
+	opts Options
+
+	terms []Term
+	banks int
+	seq   int
+	tags  map[string]Tag
+	seen  map[[3]string]bool
+}
+
+// NewWriter starts an archive written to `w`. Closing the [Writer]
+// does not close `w`.
+func NewWriter(w io.Writer, opts Options) *Writer {
+	if opts.Title == "" {
+		opts.Title = "Wiktionary"
+	}
+	if opts.Revision == "" {
+		opts.Revision = time.Now().Format("2006-01-02")
+	}
+	if opts.TargetLanguage == "" {
+		opts.TargetLanguage = "en"
+	}
+	if opts.BankSize <= 0 {
+		opts.BankSize = 10000
+	}
+	return &Writer{
+		zw:   zip.NewWriter(w),
+		opts: opts,
+		tags: make(map[string]Tag),
+		seen: make(map[[3]string]bool),
+	}
+}
+
+// Add converts `word` into a term whose glossary has one structured
+// content item per top-level sense, plus a deinflection term for each
+// inflected form unless [Options.NoForms] is set. The terms of one
+// [en.WordData] share a sequence number.
+func (w *Writer) Add(word *en.WordData) error {
+	if w.opts.SourceLanguage == "" {
+		w.opts.SourceLanguage = word.LangCode
+	}
+	e := article.New(word)
+	w.seq++
+
+	t := Term{
+		Expression: word.Word,
+		Reading:    Reading(word),
+		Rules:      posRules[word.Pos],
+		Sequence:   w.seq,
+	}
+	if word.Pos != "" {
+		t.DefinitionTags = word.Pos
+		if _, ok := w.tags[word.Pos]; !ok {
+			w.tags[word.Pos] = Tag{Name: word.Pos, Category: "partOfSpeech", Order: -3, Notes: e.Pos}
+		}
+	}
+	for _, s := range e.Senses {
+		t.Glossary = append(t.Glossary, StructuredContent{Type: "structured-content", Content: senseContent(s)})
+	}
+	if len(t.Glossary) > 0 {
+		if err := w.add(t); err != nil {
+			return err
+		}
+	}
+
+	if w.opts.NoForms {
+		return nil
+	}
+	for _, f := range e.Forms {
+		if f.Form == word.Word || len(f.Tags) == 0 || slices.ContainsFunc(f.Tags, func(t string) bool {
+			return slices.Contains(skippedFormTags, t)
+		}) {
+			continue
+		}
+		rule := strings.ReplaceAll(strings.Join(f.Tags, " "), "-", " ")
+		key := [3]string{f.Form, word.Word, rule}
+		if w.seen[key] {
+			continue
+		}
+		w.seen[key] = true
+		var reading string
+		for _, fd := range word.Forms {
+			if fd.Form == f.Form && slices.Equal(fd.Tags, f.Tags) {
+				reading = formReading(fd)
+				break
+			}
+		}
+		err := w.add(Term{
+			Expression: f.Form,
+			Reading:    reading,
+			Glossary:   []any{[]any{word.Word, []string{rule}}},
+			Sequence:   w.seq,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) add(t Term) error {
+	w.terms = append(w.terms, t)
+	if len(w.terms) >= w.opts.BankSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush writes the pending terms as the next term bank.
+func (w *Writer) flush() error {
+	if len(w.terms) == 0 {
+		return nil
+	}
+	w.banks++
+	if err := w.writeJSON(fmt.Sprintf("term_bank_%d.json", w.banks), w.terms); err != nil {
+		return err
+	}
+	w.terms = w.terms[:0]
+	return nil
+}
+
+func (w *Writer) writeJSON(name string, v any) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// Close writes the remaining terms, the tag bank and index.json and
+// finishes the archive.
+func (w *Writer) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	tags := make([]Tag, 0, len(w.tags))
+	for _, t := range w.tags {
+		tags = append(tags, t)
+	}
+	slices.SortFunc(tags, func(a, b Tag) int { return cmp.Compare(a.Name, b.Name) })
+	if err := w.writeJSON("tag_bank_1.json", tags); err != nil {
+		return err
+	}
+	err := w.writeJSON("index.json", Index{
+		Title:          w.opts.Title,
+		Revision:       w.opts.Revision,
+		Sequenced:      true,
+		Format:         3,
+		Author:         w.opts.Author,
+		URL:            w.opts.URL,
+		Description:    w.opts.Description,
+		Attribution:    w.opts.Attribution,
+		SourceLanguage: w.opts.SourceLanguage,
+		TargetLanguage: w.opts.TargetLanguage,
+	})
+	if err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// senseContent renders a sense as its label, gloss, examples and
+// numbered subsenses.
+func senseContent(s *article.Sense) []any {
+	var content []any
+	if l := article.Label(s.Tags, s.Qualifier); l != "" {
+		content = append(content, &Element{
+			Tag:     "span",
+			Content: l,
+			Data:    map[string]string{"content": "sense-tags"},
+			Style:   map[string]string{"fontStyle": "italic", "marginRight": "0.25em"},
+		})
+	}
+	content = append(content, s.Gloss)
+	if len(s.Examples) > 0 {
+		examples := make([]any, 0, len(s.Examples))
+		for _, ex := range s.Examples {
+			examples = append(examples, exampleContent(ex))
+		}
+		content = append(content, &Element{
+			Tag:     "ul",
+			Content: examples,
+			Data:    map[string]string{"content": "examples"},
+		})
+	}
+	if len(s.Subsenses) > 0 {
+		subsenses := make([]any, 0, len(s.Subsenses))
+		for _, sub := range s.Subsenses {
+			subsenses = append(subsenses, &Element{Tag: "li", Content: senseContent(sub)})
+		}
+		content = append(content, &Element{Tag: "ol", Content: subsenses})
+	}
+	return content
+}
+
+func exampleContent(ex article.Example) *Element {
+	content := spansContent(ex.Text)
+	if ex.Roman != "" {
+		content = append(content, &Element{
+			Tag:     "div",
+			Content: ex.Roman,
+			Data:    map[string]string{"content": "example-roman"},
+		})
+	}
+	if len(ex.Translation) > 0 {
+		content = append(content, &Element{
+			Tag:     "div",
+			Content: spansContent(ex.Translation),
+			Data:    map[string]string{"content": "example-translation"},
+		})
+	}
+	if ex.Ref != "" {
+		content = append(content, &Element{
+			Tag:     "div",
+			Content: ex.Ref,
+			Data:    map[string]string{"content": "example-ref"},
+			Style:   map[string]string{"fontSize": "smaller"},
+		})
+	}
+	return &Element{Tag: "li", Content: content, Data: map[string]string{"content": "example"}}
+}
+
+// spansContent renders bold runs as bold spans.
+func spansContent(spans []article.Span) []any {
+	content := make([]any, 0, len(spans))
+	for _, s := range spans {
+		if s.Bold {
+			content = append(content, &Element{Tag: "span", Content: s.Text, Style: map[string]string{"fontWeight": "bold"}})
+		} else {
+			content = append(content, s.Text)
+		}
+	}
+	return content
+}
@@ -0,0 +1,174 @@
+// Package yomitan exports [en.WordData] as a Yomitan (formerly
+// Yomichan) dictionary: a zip archive holding index.json, a tag bank
+// and term banks in format 3.
+package yomitan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+)
+
+// Index is the content of index.json.
+type Index struct {
+	Title       string `json:"title"`
+	Revision    string `json:"revision"`
+	Sequenced   bool   `json:"sequenced"`
+	Format      int    `json:"format"`
+	Author      string `json:"author,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Attribution string `json:"attribution,omitempty"`
+	// ISO language codes
+	SourceLanguage string `json:"sourceLanguage,omitempty"`
+	TargetLanguage string `json:"targetLanguage,omitempty"`
+}
+
+// Term is a row of a term bank. It is encoded as the JSON array
+// [expression, reading, definitionTags, rules, score, glossary,
+// sequence, termTags].
+type Term struct {
+	Expression string
+	// empty when the reading is the expression itself
+	Reading string
+	// space-separated tag names
+	DefinitionTags string
+	// space-separated deinflection rule identifiers
+	Rules    string
+	Score    int
+	Glossary []any
+	Sequence int
+	TermTags string
+}
+
+func (t Term) MarshalJSON() ([]byte, error) {
+	glossary := t.Glossary
+	if glossary == nil {
+		glossary = []any{}
+	}
+	return marshal([]any{t.Expression, t.Reading, t.DefinitionTags, t.Rules, t.Score, glossary, t.Sequence, t.TermTags})
+}
+
+func (t *Term) UnmarshalJSON(b []byte) error {
+	row := []any{&t.Expression, &t.Reading, &t.DefinitionTags, &t.Rules, &t.Score, &t.Glossary, &t.Sequence, &t.TermTags}
+	return json.Unmarshal(b, &row)
+}
+
+// Tag is a row of a tag bank, encoded as [name, category, order,
+// notes, score].
+type Tag struct {
+	Name string
+	// e.g. "partOfSpeech"
+	Category string
+	Order    int
+	Notes    string
+	Score    int
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshal([]any{t.Name, t.Category, t.Order, t.Notes, t.Score})
+}
+
+func (t *Tag) UnmarshalJSON(b []byte) error {
+	row := []any{&t.Name, &t.Category, &t.Order, &t.Notes, &t.Score}
+	return json.Unmarshal(b, &row)
+}
+
+// marshal is [json.Marshal] without escaping <, > and &, which are
+// common in glosses.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// StructuredContent is a glossary item rendered by Yomitan as HTML.
+type StructuredContent struct {
+	// always "structured-content"
+	Type    string `json:"type"`
+	Content any    `json:"content"`
+}
+
+// Element is a structured content node. Content is a string, an
+// *Element or a slice of those.
+type Element struct {
+	Tag     string            `json:"tag"`
+	Content any               `json:"content,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+	Style   map[string]string `json:"style,omitempty"`
+	Lang    string            `json:"lang,omitempty"`
+}
+
+// Reading returns the reading of the headword: the kana spelled out by
+// the furigana of its canonical form, or else its romanization. It is
+// empty when neither is known or the reading is the word itself.
+func Reading(w *en.WordData) string {
+	var roman string
+	for _, f := range w.Forms {
+		if f.Form == w.Word || hasTag(f.Tags, "canonical") {
+			if r := rubyReading(f.Form, f.Ruby); r != "" {
+				return r
+			}
+			if f.Roman != nil && roman == "" {
+				roman = *f.Roman
+			}
+		}
+		if hasTag(f.Tags, "romanization") && roman == "" {
+			roman = f.Form
+		}
+	}
+	if roman == w.Word {
+		return ""
+	}
+	return roman
+}
+
+// formReading is the reading of an inflected form.
+func formReading(f en.FormData) string {
+	if r := rubyReading(f.Form, f.Ruby); r != "" {
+		return r
+	}
+	if f.Roman != nil {
+		return *f.Roman
+	}
+	return ""
+}
+
+// rubyReading replaces the ruby bases in `text` by their annotation,
+// e.g. 食べる with [["食", "た"]] reads たべる. It returns "" when a base
+// cannot be found in order.
+func rubyReading(text string, ruby [][]string) string {
+	if len(ruby) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, pair := range ruby {
+		if len(pair) != 2 {
+			return ""
+		}
+		i := strings.Index(text, pair[0])
+		if i < 0 || pair[0] == "" {
+			return ""
+		}
+		sb.WriteString(text[:i])
+		sb.WriteString(pair[1])
+		text = text[i+len(pair[0]):]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
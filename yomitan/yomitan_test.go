@@ -0,0 +1,203 @@
+package yomitan_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreeDictionary/wiktionary-schema-go/en"
+	"github.com/FreeDictionary/wiktionary-schema-go/yomitan"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var words = []*en.WordData{
+	{
+		Word: "run", Pos: "verb", LangCode: "en",
+		Forms: []en.FormData{
+			{Form: "runs", Tags: []string{"present", "singular", "third-person"}},
+			{Form: "ran", Tags: []string{"past"}},
+			{Form: "ran", Tags: []string{"past"}},
+			{Form: "-", Tags: []string{"table-tags"}},
+		},
+		Senses: []en.SenseData{
+			{
+				Glosses: []string{"To move swiftly."},
+				Tags:    []string{"intransitive"},
+				Examples: []en.ExampleData{
+					{Text: "She runs <fast>.", BoldTextOffsets: [][2]int{{4, 8}}, Ref: ptr("1900, Anon.")},
+				},
+			},
+			{Glosses: []string{"To move swiftly.", "To flee."}},
+			{Glosses: []string{"To manage."}},
+		},
+	},
+	{
+		Word: "食べる", Pos: "verb", LangCode: "ja",
+		Forms: []en.FormData{
+			{Form: "食べる", Tags: []string{"canonical"}, Ruby: [][]string{{"食", "た"}}, Roman: ptr("taberu")},
+			{Form: "taberu", Tags: []string{"romanization"}},
+		},
+		Senses: []en.SenseData{{Glosses: []string{"to eat"}}},
+	},
+	{
+		Word: "ciao", Pos: "intj", LangCode: "it",
+		Forms:  []en.FormData{{Form: "ciao", Tags: []string{"romanization"}}},
+		Senses: []en.SenseData{{Glosses: []string{"hi"}}},
+	},
+}
+
+func build(t *testing.T, opts yomitan.Options) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := yomitan.NewWriter(&buf, opts)
+	for _, word := range words {
+		if err := w.Add(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func decode(t *testing.T, zr *zip.Reader, name string, v any) {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+func raw(t *testing.T, zr *zip.Reader, name string) string {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestArchive(t *testing.T) {
+	zr := build(t, yomitan.Options{Title: "Test", Revision: "r1", BankSize: 2})
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"term_bank_1.json", "term_bank_2.json", "term_bank_3.json", "tag_bank_1.json", "index.json"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	var index yomitan.Index
+	decode(t, zr, "index.json", &index)
+	if index != (yomitan.Index{Title: "Test", Revision: "r1", Sequenced: true, Format: 3, SourceLanguage: "en", TargetLanguage: "en"}) {
+		t.Errorf("index = %+v", index)
+	}
+
+	var terms []yomitan.Term
+	for _, name := range names[:3] {
+		var bank []yomitan.Term
+		decode(t, zr, name, &bank)
+		if len(bank) > 2 {
+			t.Errorf("%s has %d terms", name, len(bank))
+		}
+		terms = append(terms, bank...)
+	}
+	if len(terms) != 5 {
+		t.Fatalf("got %d terms, want 5", len(terms))
+	}
+
+	run := terms[0]
+	if run.Expression != "run" || run.Reading != "" || run.DefinitionTags != "verb" || run.Rules != "v" || run.Sequence != 1 {
+		t.Errorf("run = %+v", run)
+	}
+	if len(run.Glossary) != 2 {
+		t.Fatalf("run has %d glossary items, want one per top-level sense", len(run.Glossary))
+	}
+	first := raw(t, zr, "term_bank_1.json")
+	for _, want := range []string{
+		`"type":"structured-content"`,
+		`{"tag":"span","content":"(intransitive)","data":{"content":"sense-tags"}`,
+		`"To move swiftly."`,
+		`["She ",{"tag":"span","content":"runs","style":{"fontWeight":"bold"}}," <fast>."`,
+		`{"tag":"div","content":"1900, Anon.","data":{"content":"example-ref"}`,
+		`{"tag":"ol","content":[{"tag":"li","content":["To flee."]}]}`,
+	} {
+		if !strings.Contains(first, want) {
+			t.Errorf("missing %s in %s", want, first)
+		}
+	}
+
+	// deinflections, with the duplicate "ran" dropped
+	runs, ran := terms[1], terms[2]
+	deinflected, _ := json.Marshal(runs.Glossary)
+	if runs.Expression != "runs" || string(deinflected) != `[["run",["present singular third person"]]]` || runs.Sequence != 1 {
+		t.Errorf("runs = %+v, glossary %s", runs, deinflected)
+	}
+	if ran.Expression != "ran" {
+		t.Errorf("ran = %+v", ran)
+	}
+
+	if taberu := terms[3]; taberu.Expression != "食べる" || taberu.Reading != "たべる" || taberu.Sequence != 2 {
+		t.Errorf("taberu = %+v", taberu)
+	}
+	if ciao := terms[4]; ciao.Reading != "" || ciao.Rules != "" {
+		t.Errorf("ciao = %+v", ciao)
+	}
+
+	var tags []yomitan.Tag
+	decode(t, zr, "tag_bank_1.json", &tags)
+	wantTags := []yomitan.Tag{
+		{Name: "intj", Category: "partOfSpeech", Order: -3, Notes: "interjection"},
+		{Name: "verb", Category: "partOfSpeech", Order: -3, Notes: "verb"},
+	}
+	if !slices.Equal(tags, wantTags) {
+		t.Errorf("tags = %+v", tags)
+	}
+}
+
+func TestNoForms(t *testing.T) {
+	zr := build(t, yomitan.Options{NoForms: true})
+	var bank []yomitan.Term
+	decode(t, zr, "term_bank_1.json", &bank)
+	if len(bank) != 3 {
+		t.Errorf("got %d terms, want 3", len(bank))
+	}
+}
+
+func TestReading(t *testing.T) {
+	for _, tc := range []struct {
+		word *en.WordData
+		want string
+	}{
+		{&en.WordData{Word: "日本語", Forms: []en.FormData{{Form: "日本語", Ruby: [][]string{{"日本", "にほん"}, {"語", "ご"}}}}}, "にほんご"},
+		{&en.WordData{Word: "お茶", Forms: []en.FormData{{Form: "お茶", Tags: []string{"canonical"}, Ruby: [][]string{{"茶", "ちゃ"}}}}}, "おちゃ"},
+		// a base out of order falls back to the romanization
+		{&en.WordData{Word: "x", Forms: []en.FormData{{Form: "x", Ruby: [][]string{{"y", "z"}}, Roman: ptr("ex")}}}, "ex"},
+		{&en.WordData{Word: "cat"}, ""},
+	} {
+		if got := yomitan.Reading(tc.word); got != tc.want {
+			t.Errorf("Reading(%s) = %q, want %q", tc.word.Word, got, tc.want)
+		}
+	}
+}